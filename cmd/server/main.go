@@ -2,11 +2,21 @@ package main
 
 import (
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/demand"
+	"github.com/willianbsanches13/pack-calculator/internal/grpcapi"
 	"github.com/willianbsanches13/pack-calculator/internal/handler"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
 	"github.com/willianbsanches13/pack-calculator/internal/storage"
+	"github.com/willianbsanches13/pack-calculator/internal/webhook"
 )
 
 func main() {
@@ -21,15 +31,158 @@ func main() {
 	}
 
 	store := storage.NewMemoryStorage()
-	h := handler.New(store)
+	history := storage.NewMemoryHistory(storage.DefaultHistoryLimit)
+
+	idempotencyTTL := storage.DefaultIdempotencyTTL
+	if v := os.Getenv("IDEMPOTENCY_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			log.Fatal("IDEMPOTENCY_TTL must be a positive duration, e.g. 24h")
+		}
+		idempotencyTTL = ttl
+	}
+
+	auditRetention := storage.DefaultAuditRetention
+	if v := os.Getenv("AUDIT_RETENTION"); v != "" {
+		retention, err := time.ParseDuration(v)
+		if err != nil || retention <= 0 {
+			log.Fatal("AUDIT_RETENTION must be a positive duration, e.g. 2160h")
+		}
+		auditRetention = retention
+	}
+	auditMaxRecords := envInt("AUDIT_MAX_RECORDS", storage.DefaultAuditMaxRecords)
+	audit := storage.NewMemoryAuditLog(auditRetention, auditMaxRecords)
+
+	maxAmount := envInt("MAX_AMOUNT", calculator.DefaultMaxAmount)
+	if maxAmount <= 0 {
+		log.Fatal("MAX_AMOUNT must be a positive integer")
+	}
+
+	opts := []handler.Option{
+		handler.WithMaxAmount(maxAmount),
+		handler.WithHistory(history),
+		handler.WithIdempotency(storage.NewMemoryIdempotencyStore(), idempotencyTTL),
+		handler.WithAudit(audit),
+	}
+	// the gRPC server shares authentication, rate limits and tenants with REST
+	grpcOpts := []grpcapi.Option{grpcapi.WithMaxAmount(maxAmount)}
+	if adminKey := os.Getenv("ADMIN_API_KEY"); adminKey != "" {
+		keys := storage.NewMemoryAPIKeyStore()
+		keys.CreateAPIKey(storage.APIKey{
+			ID:        "bootstrap-admin",
+			Name:      "bootstrap admin",
+			KeyHash:   auth.HashKey(adminKey),
+			Scopes:    []string{string(auth.ScopeAdmin)},
+			CreatedAt: time.Now().UTC(),
+		})
+		opts = append(opts, handler.WithAPIKeys(keys))
+		grpcOpts = append(grpcOpts, grpcapi.WithAPIKeys(keys))
+		log.Printf("API key authentication enabled")
+	}
+
+	if jwksFile, jwksURL := os.Getenv("JWT_JWKS_FILE"), os.Getenv("JWT_JWKS_URL"); jwksFile != "" || jwksURL != "" {
+		validator, err := auth.NewJWTValidator(auth.JWTConfig{
+			JWKSFile:    jwksFile,
+			JWKSURL:     jwksURL,
+			Issuer:      os.Getenv("JWT_ISSUER"),
+			Audience:    os.Getenv("JWT_AUDIENCE"),
+			RolesClaim:  os.Getenv("JWT_ROLES_CLAIM"),
+			TenantClaim: os.Getenv("JWT_TENANT_CLAIM"),
+		})
+		if err != nil {
+			log.Fatal("JWT configuration failed:", err)
+		}
+		opts = append(opts, handler.WithJWT(validator))
+		grpcOpts = append(grpcOpts, grpcapi.WithJWT(validator))
+		log.Printf("JWT authentication enabled")
+	}
+
+	if rps := os.Getenv("RATE_LIMIT_RPS"); rps != "" {
+		rate, err := strconv.ParseFloat(rps, 64)
+		if err != nil || rate <= 0 {
+			log.Fatal("RATE_LIMIT_RPS must be a positive number")
+		}
+		burst := envInt("RATE_LIMIT_BURST", max(1, int(rate*2)))
+		routes, err := ratelimit.ParseRoutes(os.Getenv("RATE_LIMIT_ROUTES"))
+		if err != nil {
+			log.Fatal("RATE_LIMIT_ROUTES: ", err)
+		}
+		limiter := ratelimit.New(ratelimit.Config{
+			Default:        ratelimit.Limit{Rate: rate, Burst: burst},
+			Routes:         routes,
+			AmountPerToken: envInt("RATE_LIMIT_AMOUNT_PER_TOKEN", 100000),
+		}, nil)
+		opts = append(opts, handler.WithRateLimit(limiter))
+		grpcOpts = append(grpcOpts, grpcapi.WithRateLimit(limiter))
+		log.Printf("Rate limiting enabled: %.2f req/s, burst %d", rate, burst)
+	}
+
+	var dispatcher *webhook.Dispatcher
+	if os.Getenv("WEBHOOKS_ENABLED") == "true" {
+		dispatcher = webhook.NewDispatcher(storage.NewMemoryWebhookStore(), webhook.Config{
+			MaxAttempts: envInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		})
+		dispatcher.Watch(history)
+		opts = append(opts, handler.WithWebhooks(dispatcher))
+		log.Printf("Webhooks enabled")
+	}
+
+	if os.Getenv("DEMAND_ENABLED") == "true" {
+		bucket := envDuration("DEMAND_BUCKET", storage.DefaultDemandBucket)
+		retention := envDuration("DEMAND_RETENTION", storage.DefaultDemandRetention)
+		collector := demand.NewCollector(storage.NewMemoryDemandStore(bucket, retention), demand.Config{})
+		opts = append(opts, handler.WithDemand(collector))
+		log.Printf("Demand recording enabled: %s buckets kept for %s", bucket, retention)
+	}
+
+	if os.Getenv("MULTI_TENANT") == "true" {
+		tenants := storage.NewMemoryTenantRegistry(func(id string) storage.TenantStores {
+			tenantHistory := storage.NewMemoryHistory(storage.DefaultHistoryLimit)
+			if dispatcher != nil {
+				dispatcher.WatchTenant(id, tenantHistory)
+			}
+			return storage.TenantStores{
+				Storage: storage.NewMemoryStorage(),
+				History: tenantHistory,
+				Audit:   storage.NewMemoryAuditLog(auditRetention, auditMaxRecords),
+			}
+		})
+		// the default tenant keeps the stores created above
+		tenants.Set(storage.DefaultTenant, storage.TenantStores{Storage: store, History: history, Audit: audit})
+		for _, id := range strings.Split(os.Getenv("TENANTS"), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				tenants.CreateTenant(id)
+			}
+		}
+		opts = append(opts, handler.WithTenants(tenants))
+		grpcOpts = append(grpcOpts, grpcapi.WithTenants(tenants))
+		log.Printf("Multi-tenancy enabled: %v", tenants.Tenants())
+	}
+
+	h := handler.New(store, opts...)
 
 	r := gin.New()
 	r.Use(gin.Recovery())
+	r.Use(handler.RequestID())
 	r.Use(gin.Logger())
 	r.Use(corsMiddleware())
 
 	h.RegisterRoutes(r)
 
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Fatal("gRPC listen failed:", err)
+		}
+		grpcServer := grpcapi.NewGRPCServer(grpcapi.NewServer(store, history, grpcOpts...))
+		go func() {
+			log.Printf("Pack Calculator gRPC running on localhost:%s", grpcPort)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatal("gRPC server failed:", err)
+			}
+		}()
+	}
+
 	log.Printf("Pack Calculator API running on http://localhost:%s", port)
 	log.Printf("Default pack sizes: %v", store.GetPackSizes())
 
@@ -38,11 +191,36 @@ func main() {
 	}
 }
 
+func envInt(name string, fallback int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("%s must be an integer", name)
+	}
+	return n
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s must be a positive duration, e.g. 1h", name)
+	}
+	return d
+}
+
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Idempotency-Key, Last-Event-ID, X-Request-ID, X-Tenant-ID")
+		c.Header("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, Deprecation, Link, Idempotent-Replayed, X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -0,0 +1,188 @@
+// Command simulate compares pack size catalogues on replayed or sampled
+// demand, like POST /api/simulate.
+//
+//	simulate -amounts orders.txt -compare proposed=300,600,1200,5000
+//	simulate -dist lognormal -mean 3000 -stddev 2500 -count 10000 -seed 42 -compare 300,600,1200,5000
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/willianbsanches13/pack-calculator/internal/simulation"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// catalogues collects repeated -compare flags
+type catalogues []simulation.Catalogue
+
+func (c *catalogues) String() string {
+	return fmt.Sprint(*c)
+}
+
+// Set parses [name=]size,size,...; unnamed catalogues are numbered
+func (c *catalogues) Set(v string) error {
+	name, list, found := strings.Cut(v, "=")
+	if !found {
+		name, list = fmt.Sprintf("catalogue-%d", len(*c)+1), v
+	}
+	sizes, err := parseSizes(list)
+	if err != nil {
+		return err
+	}
+	*c = append(*c, simulation.Catalogue{Name: name, PackSizes: sizes})
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+
+	var compare catalogues
+	current := flag.String("current", joinSizes(storage.DefaultPackSizes()), "pack sizes of the baseline catalogue")
+	amountsFile := flag.String("amounts", "", "file of order amounts to replay, separated by whitespace or commas; - reads stdin")
+	var dist simulation.Distribution
+	flag.StringVar(&dist.Kind, "dist", "", "distribution to sample instead: uniform, normal or lognormal")
+	flag.IntVar(&dist.Count, "count", 1000, "amounts to sample")
+	flag.Uint64Var(&dist.Seed, "seed", 1, "seed of the sampled amounts")
+	flag.IntVar(&dist.Min, "min", 0, "lowest sampled amount")
+	flag.IntVar(&dist.Max, "max", 0, "highest sampled amount")
+	flag.Float64Var(&dist.Mean, "mean", 0, "mean of normal and lognormal amounts")
+	flag.Float64Var(&dist.StdDev, "stddev", 0, "standard deviation of normal and lognormal amounts")
+	flag.Var(&compare, "compare", "catalogue to compare, as [name=]size,size,...; repeatable")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	diffs := flag.Int("diffs", 20, "differing amounts to print; -1 prints all")
+	flag.Parse()
+
+	if len(compare) == 0 {
+		log.Fatal("at least one -compare catalogue is required")
+	}
+	if (*amountsFile == "") == (dist.Kind == "") {
+		log.Fatal("set either -amounts or -dist")
+	}
+
+	var amounts []int
+	var err error
+	if *amountsFile != "" {
+		amounts, err = readAmounts(*amountsFile)
+	} else {
+		amounts, err = dist.Sample()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	baseline, err := parseSizes(*current)
+	if err != nil {
+		log.Fatal(err)
+	}
+	all := append([]simulation.Catalogue{{Name: simulation.Baseline, PackSizes: baseline}}, compare...)
+
+	report, err := simulation.Run(amounts, all)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	printReport(os.Stdout, report, *diffs)
+}
+
+func printReport(out io.Writer, r *simulation.Report, diffs int) {
+	fmt.Fprintf(out, "%d orders, %d items\n\n", r.Orders, r.OrderedItems)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "catalogue\tpack sizes\titems\tpacks\tovership\trate\tΔ overship\tΔ packs\tamounts changed\t")
+	for _, s := range r.Catalogues {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%.2f%%\t%+d\t%+d\t%d\t\n",
+			s.Name, joinSizes(s.PackSizes), s.TotalItems, s.TotalPacks, s.TotalOvership,
+			100*s.OvershipRate, s.OvershipDelta, s.PacksDelta, s.AmountsChanged)
+	}
+	w.Flush()
+
+	if len(r.Differences) == 0 || diffs == 0 {
+		return
+	}
+	shown := r.Differences
+	if diffs > 0 && len(shown) > diffs {
+		shown = shown[:diffs]
+	}
+	fmt.Fprintf(out, "\n%d amounts differ from %s; overship/packs per catalogue:\n\n", len(r.Differences), simulation.Baseline)
+
+	w = tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "amount\torders\t")
+	for _, s := range r.Catalogues {
+		fmt.Fprintf(w, "%s\t", s.Name)
+	}
+	fmt.Fprintln(w)
+	for _, d := range shown {
+		fmt.Fprintf(w, "%d\t%d\t", d.Amount, d.Orders)
+		for _, o := range d.Outcomes {
+			fmt.Fprintf(w, "%d/%d\t", o.Overship, o.TotalPacks)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+func readAmounts(path string) ([]int, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Split(bufio.ScanWords)
+	var amounts []int
+	for scanner.Scan() {
+		for _, field := range strings.Split(scanner.Text(), ",") {
+			if field == "" {
+				continue
+			}
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("amount %q is not an integer", field)
+			}
+			amounts = append(amounts, n)
+		}
+	}
+	return amounts, scanner.Err()
+}
+
+func parseSizes(list string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("pack size %q is not an integer", field)
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes, nil
+}
+
+func joinSizes(sizes []int) string {
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,102 @@
+// Package auth provides API key generation, hashing and scope checks, and the
+// authentication and tenant rules shared by the HTTP and gRPC servers.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+var ErrUnknownScope = errors.New("unknown scope")
+
+type Scope string
+
+const (
+	ScopeCalculate      Scope = "calculate"
+	ScopePackSizesRead  Scope = "packsizes:read"
+	ScopePackSizesWrite Scope = "packsizes:write"
+	ScopeAdmin          Scope = "admin" // grants every other scope
+)
+
+// keyPrefix makes leaked keys easy to spot in logs and secret scanners
+const keyPrefix = "pk_"
+
+func AllScopes() []Scope {
+	return []Scope{ScopeCalculate, ScopePackSizesRead, ScopePackSizesWrite, ScopeAdmin}
+}
+
+func ParseScope(s string) (Scope, error) {
+	for _, scope := range AllScopes() {
+		if string(scope) == s {
+			return scope, nil
+		}
+	}
+	return "", ErrUnknownScope
+}
+
+// HasScope reports whether the granted scopes satisfy the required one.
+func HasScope(granted []string, required Scope) bool {
+	for _, g := range granted {
+		if g == string(required) || g == string(ScopeAdmin) {
+			return true
+		}
+	}
+	return false
+}
+
+// HashKey returns the hex encoded SHA-256 of a raw key. Only the hash is stored.
+func HashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewKey generates a key identifier and the raw secret handed to the client.
+func NewKey() (id string, raw string, err error) {
+	id, err = randomHex(8)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return id, keyPrefix + secret, nil
+}
+
+// Principal is the authenticated caller attached to a request.
+// Tenant is empty when the credentials are not bound to a tenant.
+type Principal struct {
+	Subject string
+	Scopes  []string
+	Tenant  string
+}
+
+func (p Principal) HasScope(required Scope) bool {
+	return HasScope(p.Scopes, required)
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal attaches the authenticated caller to ctx.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFrom returns the caller attached by WithPrincipal.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(Principal)
+	return p, ok
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
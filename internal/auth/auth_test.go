@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required Scope
+		want     bool
+	}{
+		{"exact", []string{"calculate"}, ScopeCalculate, true},
+		{"missing", []string{"packsizes:read"}, ScopePackSizesWrite, false},
+		{"admin grants all", []string{"admin"}, ScopePackSizesWrite, true},
+		{"none", nil, ScopeCalculate, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasScope(tt.granted, tt.required); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	if _, err := ParseScope("packsizes:write"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ParseScope("root"); err != ErrUnknownScope {
+		t.Errorf("got error = %v, want %v", err, ErrUnknownScope)
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	id1, raw1, err := NewKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	id2, raw2, _ := NewKey()
+
+	if id1 == id2 || raw1 == raw2 {
+		t.Error("expected unique keys")
+	}
+	if !strings.HasPrefix(raw1, keyPrefix) {
+		t.Errorf("expected prefix %q, got %q", keyPrefix, raw1)
+	}
+	if HashKey(raw1) == raw1 || HashKey(raw1) != HashKey(raw1) {
+		t.Error("expected stable hash different from raw key")
+	}
+}
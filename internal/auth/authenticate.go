@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+var (
+	ErrNoCredentials   = errors.New("credentials are required")
+	ErrInvalidAPIKey   = errors.New("API key is not valid")
+	ErrInvalidTenantID = errors.New("tenant ID must be 1-64 lowercase letters, digits, '-' or '_'")
+	ErrTenantMismatch  = errors.New("credentials are bound to another tenant")
+	ErrTenantNotBound  = errors.New("credentials are not bound to tenant")
+	ErrUnknownTenant   = errors.New("tenant is not registered")
+)
+
+// Authenticator checks the credentials of a call, whatever the transport.
+// Authentication is disabled when both fields are nil.
+type Authenticator struct {
+	APIKeys storage.APIKeyStore
+	JWT     *JWTValidator
+}
+
+func (a Authenticator) Enabled() bool {
+	return a.APIKeys != nil || a.JWT != nil
+}
+
+// Authenticate returns the caller of an Authorization value and an API key,
+// either of which may be empty. A bearer token wins when JWT is configured.
+// Errors wrap ErrInvalidToken, ErrNoCredentials or ErrInvalidAPIKey.
+func (a Authenticator) Authenticate(authorization, apiKey string) (Principal, error) {
+	if token, ok := BearerToken(authorization); ok && a.JWT != nil {
+		return a.JWT.Validate(token)
+	}
+
+	if apiKey == "" || a.APIKeys == nil {
+		return Principal{}, ErrNoCredentials
+	}
+	key, ok := a.APIKeys.GetAPIKeyByHash(HashKey(apiKey))
+	if !ok {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	return Principal{Subject: "apikey:" + key.ID, Scopes: key.Scopes, Tenant: key.Tenant}, nil
+}
+
+// BearerToken extracts the token of an Authorization value.
+func BearerToken(authorization string) (string, bool) {
+	const prefix = "Bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(prefix):]), true
+}
+
+// ResolveTenant picks the tenant of a call that requested one, possibly
+// empty, for the principal in ctx, if any. Credentials bound to a tenant
+// cannot name another one, and only unbound admins may choose a tenant when
+// authenticated. Unregistered tenants are not found, so a typo never creates
+// a tenant. Errors wrap the ErrInvalidTenantID, ErrTenantMismatch,
+// ErrTenantNotBound and ErrUnknownTenant.
+func ResolveTenant(ctx context.Context, tenants storage.TenantRegistry, requested string) (string, error) {
+	if requested != "" && !storage.ValidTenantID(requested) {
+		return "", ErrInvalidTenantID
+	}
+
+	tenant := requested
+	if principal, ok := PrincipalFrom(ctx); ok {
+		switch {
+		case principal.Tenant != "":
+			if requested != "" && requested != principal.Tenant {
+				return "", ErrTenantMismatch
+			}
+			tenant = principal.Tenant
+		case requested != "" && requested != storage.DefaultTenant && !principal.HasScope(ScopeAdmin):
+			return "", fmt.Errorf("%w %s", ErrTenantNotBound, requested)
+		}
+	}
+	if tenant == "" {
+		tenant = storage.DefaultTenant
+	}
+	if _, ok := tenants.Tenant(tenant); !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+	}
+	return tenant, nil
+}
+
+type tenantContextKey struct{}
+
+// WithTenant attaches the tenant resolved for a call to ctx.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFrom returns the tenant attached by WithTenant, or "".
+func TenantFrom(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
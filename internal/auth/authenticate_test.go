@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func TestAuthenticate(t *testing.T) {
+	keys := storage.NewMemoryAPIKeyStore()
+	keys.CreateAPIKey(storage.APIKey{ID: "calc", KeyHash: HashKey("pk_calc"), Scopes: []string{string(ScopeCalculate)}, Tenant: "acme"})
+	a := Authenticator{APIKeys: keys}
+
+	principal, err := a.Authenticate("", "pk_calc")
+	if err != nil || principal.Subject != "apikey:calc" || principal.Tenant != "acme" || !principal.HasScope(ScopeCalculate) {
+		t.Errorf("expected the key's principal, got %+v %v", principal, err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		apiKey        string
+		want          error
+	}{
+		{"no credentials", "", "", ErrNoCredentials},
+		{"unknown key", "", "pk_nope", ErrInvalidAPIKey},
+		{"bearer without JWT", "Bearer x", "", ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := a.Authenticate(tt.authorization, tt.apiKey); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	if token, ok := BearerToken("bearer  abc "); !ok || token != "abc" {
+		t.Errorf("expected abc, got %q %v", token, ok)
+	}
+	for _, header := range []string{"", "Bearer ", "Basic abc"} {
+		if _, ok := BearerToken(header); ok {
+			t.Errorf("%q: expected no token", header)
+		}
+	}
+}
+
+func TestResolveTenant(t *testing.T) {
+	tenants := storage.NewMemoryTenantRegistry(func(string) storage.TenantStores { return storage.TenantStores{} })
+	tenants.CreateTenant("acme")
+
+	bound := WithPrincipal(context.Background(), Principal{Subject: "bound", Scopes: []string{string(ScopeAdmin)}, Tenant: "acme"})
+	reader := WithPrincipal(context.Background(), Principal{Subject: "reader", Scopes: []string{string(ScopeCalculate)}})
+	admin := WithPrincipal(context.Background(), Principal{Subject: "admin", Scopes: []string{string(ScopeAdmin)}})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requested string
+		want      string
+		wantErr   error
+	}{
+		{"anonymous default", context.Background(), "", storage.DefaultTenant, nil},
+		{"anonymous chooses", context.Background(), "acme", "acme", nil},
+		{"bound credentials", bound, "", "acme", nil},
+		{"bound elsewhere", bound, storage.DefaultTenant, "", ErrTenantMismatch},
+		{"unbound non-admin", reader, "acme", "", ErrTenantNotBound},
+		{"unbound admin", admin, "acme", "acme", nil},
+		{"invalid ID", context.Background(), "Acme!", "", ErrInvalidTenantID},
+		{"unregistered", admin, "globex", "", ErrUnknownTenant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant, err := ResolveTenant(tt.ctx, tenants, tt.requested)
+			if tenant != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %q %v, got %q %v", tt.want, tt.wantErr, tenant, err)
+			}
+		})
+	}
+}
+
+func TestTenantContext(t *testing.T) {
+	if tenant := TenantFrom(context.Background()); tenant != "" {
+		t.Errorf("expected no tenant, got %q", tenant)
+	}
+	if tenant := TenantFrom(WithTenant(context.Background(), "acme")); tenant != "acme" {
+		t.Errorf("expected acme, got %q", tenant)
+	}
+}
@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoJWKSSource = errors.New("jwks file or url is required")
+	ErrUnknownKeyID = errors.New("token signed with unknown key")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// jwksRefreshInterval bounds how often an unknown kid triggers a JWKS refetch
+const jwksRefreshInterval = time.Minute
+
+// JWTConfig describes how bearer tokens from the identity provider are validated.
+type JWTConfig struct {
+	JWKSFile string
+	JWKSURL  string
+	Issuer   string
+	Audience string
+
+	// RolesClaim is a dotted path to the roles array, e.g. "realm_access.roles".
+	RolesClaim string
+	RoleScopes map[string][]Scope
+
+	// TenantClaim names the claim that binds the caller to a tenant.
+	TenantClaim string
+}
+
+// DefaultRoleScopes maps the roles issued by the SSO to API scopes.
+func DefaultRoleScopes() map[string][]Scope {
+	return map[string][]Scope{
+		"viewer":   {ScopeCalculate, ScopePackSizesRead},
+		"operator": {ScopeCalculate, ScopePackSizesRead, ScopePackSizesWrite},
+		"admin":    {ScopeAdmin},
+	}
+}
+
+// JWTValidator verifies bearer tokens against a JSON Web Key Set.
+type JWTValidator struct {
+	cfg    JWTConfig
+	parser *jwt.Parser
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+}
+
+func NewJWTValidator(cfg JWTConfig) (*JWTValidator, error) {
+	if cfg.JWKSFile == "" && cfg.JWKSURL == "" {
+		return nil, ErrNoJWKSSource
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = "tenant"
+	}
+	if cfg.RoleScopes == nil {
+		cfg.RoleScopes = DefaultRoleScopes()
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	v := &JWTValidator{
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if err := v.refresh(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Validate checks the token signature and claims and returns the caller.
+func (v *JWTValidator) Validate(token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	sub, _ := claims.GetSubject()
+	if sub == "" {
+		return Principal{}, fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+
+	var scopes []string
+	for _, role := range rolesFromClaims(claims, v.cfg.RolesClaim) {
+		for _, scope := range v.cfg.RoleScopes[role] {
+			scopes = append(scopes, string(scope))
+		}
+	}
+
+	tenant, _ := claims[v.cfg.TenantClaim].(string)
+	return Principal{Subject: sub, Scopes: scopes, Tenant: tenant}, nil
+}
+
+func (v *JWTValidator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+
+	// the provider may have rotated keys since we last fetched them
+	if v.cfg.JWKSURL != "" && v.canRefresh() {
+		if err := v.refresh(); err != nil {
+			return nil, err
+		}
+		if key, ok := v.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, ErrUnknownKeyID
+}
+
+func (v *JWTValidator) lookup(kid string) (crypto.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+func (v *JWTValidator) canRefresh() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return time.Since(v.lastRefresh) >= jwksRefreshInterval
+}
+
+func (v *JWTValidator) refresh() error {
+	data, err := v.loadJWKS()
+	if err != nil {
+		return err
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.lastRefresh = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *JWTValidator) loadJWKS() ([]byte, error) {
+	if v.cfg.JWKSFile != "" {
+		return os.ReadFile(v.cfg.JWKSFile)
+	}
+
+	resp, err := v.client.Get(v.cfg.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching jwks: unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS decodes the RSA and EC signing keys of a JSON Web Key Set.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parsing jwk %q: %w", jwk.Kid, err)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey returns nil for key types we do not verify with
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// rolesFromClaims accepts either a string array or a space separated string
+func rolesFromClaims(claims jwt.MapClaims, path string) []string {
+	var current interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+
+	switch v := current.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		roles := make([]string, 0, len(v))
+		for _, r := range v {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "pack-calculator"
+	testKID      = "test-key"
+)
+
+func writeTestJWKS(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	set := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKID,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, _ := json.Marshal(set)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write jwks: %v", err)
+	}
+	return path
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "alice",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"operator"},
+	}
+}
+
+func TestJWTValidator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	v, err := NewJWTValidator(JWTConfig{
+		JWKSFile: writeTestJWKS(t, key),
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		p, err := v.Validate(signTestToken(t, key, validClaims()))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Subject != "alice" {
+			t.Errorf("expected subject alice, got %s", p.Subject)
+		}
+		if !p.HasScope(ScopePackSizesWrite) || p.HasScope(ScopeAdmin) {
+			t.Errorf("unexpected scopes %v", p.Scopes)
+		}
+		if p.Tenant != "" {
+			t.Errorf("expected no tenant, got %q", p.Tenant)
+		}
+	})
+
+	t.Run("tenant claim", func(t *testing.T) {
+		claims := validClaims()
+		claims["tenant"] = "acme"
+		p, err := v.Validate(signTestToken(t, key, claims))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Tenant != "acme" {
+			t.Errorf("expected tenant acme, got %q", p.Tenant)
+		}
+	})
+
+	rejected := []struct {
+		name   string
+		key    *rsa.PrivateKey
+		mutate func(jwt.MapClaims)
+	}{
+		{"expired", key, func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{"no expiry", key, func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"wrong issuer", key, func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"wrong audience", key, func(c jwt.MapClaims) { c["aud"] = "other-api" }},
+		{"wrong signature", otherKey, func(c jwt.MapClaims) {}},
+	}
+
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.mutate(claims)
+			_, err := v.Validate(signTestToken(t, tt.key, claims))
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("got error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestRolesFromNestedClaim(t *testing.T) {
+	claims := jwt.MapClaims{
+		"realm_access": map[string]interface{}{"roles": []interface{}{"viewer", "admin"}},
+	}
+
+	roles := rolesFromClaims(claims, "realm_access.roles")
+	if len(roles) != 2 || roles[0] != "viewer" || roles[1] != "admin" {
+		t.Errorf("unexpected roles %v", roles)
+	}
+}
+
+func TestNewJWTValidatorRequiresSource(t *testing.T) {
+	if _, err := NewJWTValidator(JWTConfig{}); err != ErrNoJWKSSource {
+		t.Errorf("got error = %v, want %v", err, ErrNoJWKSSource)
+	}
+}
@@ -3,18 +3,33 @@ package calculator
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"runtime"
 	"sort"
+	"strings"
+	"sync"
 )
 
 var (
 	ErrNoPackSizes     = errors.New("no pack sizes configured")
 	ErrInvalidAmount   = errors.New("amount must be greater than zero")
 	ErrInvalidPackSize = errors.New("pack size must be greater than zero")
+	// ErrAmountTooLarge and ErrPackSizeTooLarge reject amounts and pack sizes
+	// above the maximum set with SetMaxAmount
+	ErrAmountTooLarge   = errors.New("amount exceeds the maximum")
+	ErrPackSizeTooLarge = errors.New("pack size exceeds the maximum")
 )
 
+// DefaultMaxAmount is the largest order servers accept by default; its DP
+// table takes about 800 MB.
+const DefaultMaxAmount = 50_000_000
+
 type Calculator struct {
-	packSizes []int // sorted descending
+	packSizes []int        // sorted descending
+	packs     map[int]Pack // attributes by size, nil for bare sizes
+	solver    string       // name given to SetSolver, "" for the DP
+	maxAmount int          // 0 leaves amounts and pack sizes unbounded
 }
 
 func New(packSizes []int) (*Calculator, error) {
@@ -56,11 +71,93 @@ func (c *Calculator) SetPackSizes(packSizes []int) error {
 
 	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
 	c.packSizes = sizes
+	c.packs = nil
+	return nil
+}
+
+// SetMaxAmount bounds the tables calculations build, whose length is the
+// amount plus the largest pack: amounts and pack sizes above n fail with
+// ErrAmountTooLarge and ErrPackSizeTooLarge. 0, the default, leaves them
+// unbounded.
+func (c *Calculator) SetMaxAmount(n int) {
+	c.maxAmount = n
+}
+
+// TableSize checks amount and the constraints against the maximum and
+// returns the length of the table calculating them builds, so servers can
+// charge for it up front.
+func (c *Calculator) TableSize(amount int, cons Constraints) (int, error) {
+	if amount <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	if err := c.checkLimits(amount); err != nil {
+		return 0, err
+	}
+	if err := cons.validate(c.packSizes, amount, c.maxAmount); err != nil {
+		return 0, err
+	}
+	return c.tableSize(amount, cons), nil
+}
+
+// tableSize is the length of the table for amount: a cheaper total always
+// exists below amount + lo*size, as dropping one pack, or all lo of a size
+// used exactly lo times, stays at or above amount. Constraints must be valid.
+func (c *Calculator) tableSize(amount int, cons Constraints) int {
+	size := amount
+	for _, s := range c.packSizes {
+		size = max(size, amount+max(1, cons.MinPacks[s])*s)
+	}
+	return size
+}
+
+// checkLimits rejects amounts and pack sizes above the maximum
+func (c *Calculator) checkLimits(amount int) error {
+	if c.maxAmount <= 0 {
+		return nil
+	}
+	if amount > c.maxAmount {
+		return fmt.Errorf("%w of %d", ErrAmountTooLarge, c.maxAmount)
+	}
+	// sorted descending, so the largest is enough
+	if c.packSizes[0] > c.maxAmount {
+		return fmt.Errorf("%w of %d", ErrPackSizeTooLarge, c.maxAmount)
+	}
 	return nil
 }
 
-// Calculate finds the optimal pack combination using DP (similar to coin change).
+// Calculate finds the optimal pack combination using DP (similar to coin
+// change), or the solver selected with SetSolver.
 func (c *Calculator) Calculate(amount int) (map[int]int, error) {
+	packs, _, err := c.solve(amount)
+	return packs, err
+}
+
+func (c *Calculator) solve(amount int) (map[int]int, Solver, error) {
+	if amount <= 0 {
+		return nil, nil, ErrInvalidAmount
+	}
+
+	if len(c.packSizes) == 0 {
+		return nil, nil, ErrNoPackSizes
+	}
+	if err := c.checkLimits(amount); err != nil {
+		return nil, nil, err
+	}
+
+	s := c.solverFor(amount)
+	return s.Solve(c.packSizes, amount), s, nil
+}
+
+const impossible = math.MaxInt32
+
+// table is the DP state shared by Calculate and Alternatives
+type table struct {
+	dp        []int // dp[i] = min packs to get i items
+	parent    []int // tracks which pack was used
+	maxTarget int
+}
+
+func (c *Calculator) buildTable(amount int) (*table, error) {
 	if amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
@@ -68,16 +165,37 @@ func (c *Calculator) Calculate(amount int) (map[int]int, error) {
 	if len(c.packSizes) == 0 {
 		return nil, ErrNoPackSizes
 	}
+	if err := c.checkLimits(amount); err != nil {
+		return nil, err
+	}
+
+	return newTable(c.packSizes, amount), nil
+}
+
+// parallelMinAmount is the smallest amount whose table is filled in parallel
+const parallelMinAmount = 1 << 20
+
+// minChunk is the fewest totals of a block a worker takes, so workers do
+// not share cache lines more than they must
+const minChunk = 64
+
+// newTable fills the table for amount from sizes sorted descending, in
+// parallel for large amounts when there is more than one CPU
+func newTable(sizes []int, amount int) *table {
+	if workers := runtime.GOMAXPROCS(0); workers > 1 && amount >= parallelMinAmount {
+		return newTableParallel(sizes, amount, workers)
+	}
+	return newTableSequential(sizes, amount)
+}
 
-	smallestPack := c.packSizes[len(c.packSizes)-1]
-	largestPack := c.packSizes[0]
+func newTableSequential(sizes []int, amount int) *table {
+	largestPack := sizes[0]
 
 	// upper bound for DP - no valid solution exceeds this
 	maxTarget := amount + largestPack
 
-	const impossible = math.MaxInt32
-	dp := make([]int, maxTarget+1)     // dp[i] = min packs to get i items
-	parent := make([]int, maxTarget+1) // tracks which pack was used
+	dp := make([]int, maxTarget+1)
+	parent := make([]int, maxTarget+1)
 
 	for i := range dp {
 		dp[i] = impossible
@@ -91,7 +209,7 @@ func (c *Calculator) Calculate(amount int) (map[int]int, error) {
 			continue
 		}
 
-		for _, packSize := range c.packSizes {
+		for _, packSize := range sizes {
 			next := i + packSize
 			if next > maxTarget {
 				continue
@@ -104,34 +222,80 @@ func (c *Calculator) Calculate(amount int) (map[int]int, error) {
 		}
 	}
 
-	// find smallest total >= amount
-	target := -1
-	for i := amount; i <= maxTarget; i++ {
-		if dp[i] != impossible {
-			target = i
-			break
-		}
+	return &table{dp: dp, parent: parent, maxTarget: maxTarget}
+}
+
+// newTableParallel fills the same table as newTableSequential one size at a
+// time, smallest first. A size only links totals that far apart, so its
+// residues are independent chains: each worker takes a range of residues and
+// walks it through every block of the size's width, reading only totals it
+// wrote itself. Taking a size on equal packs leaves the largest size of a
+// fewest-packs combination as parent, the one the sequential scan, trying
+// larger sizes first from smaller totals, keeps too.
+func newTableParallel(sizes []int, amount, workers int) *table {
+	maxTarget := amount + sizes[0]
+
+	dp := make([]int, maxTarget+1)
+	parent := make([]int, maxTarget+1)
+
+	var wg sync.WaitGroup
+	chunk := ceilDiv(maxTarget+1, workers)
+	for lo := 0; lo <= maxTarget; lo += chunk {
+		hi := min(lo+chunk, maxTarget+1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				dp[i] = impossible
+				parent[i] = -1
+			}
+		}()
 	}
+	wg.Wait()
+	dp[0] = 0
+
+	for k := len(sizes) - 1; k >= 0; k-- {
+		size := sizes[k]
+		if k < len(sizes)-1 && size == sizes[k+1] {
+			continue
+		}
 
-	if target == -1 {
-		// Fallback: shouldnt happen with valid pack sizes
-		packsNeeded := (amount + smallestPack - 1) / smallestPack
-		return map[int]int{smallestPack: packsNeeded}, nil
+		n := max(1, min(workers, size/minChunk))
+		for w := 0; w < n; w++ {
+			lo, hi := w*size/n, (w+1)*size/n
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for block := size; block+lo <= maxTarget; block += size {
+					end := min(block+hi, maxTarget+1)
+					for i := block + lo; i < end; i++ {
+						if d := dp[i-size]; d != impossible && d+1 <= dp[i] {
+							dp[i] = d + 1
+							parent[i] = size
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
 	}
 
-	// backtrack to find which packs were used
+	return &table{dp: dp, parent: parent, maxTarget: maxTarget}
+}
+
+// backtrack to find which packs were used
+func (t *table) backtrack(target int) map[int]int {
 	result := make(map[int]int)
 	current := target
 	for current > 0 {
-		pack := parent[current]
+		pack := t.parent[current]
 		if pack == -1 {
 			break
 		}
 		result[pack]++
 		current -= pack
 	}
-
-	return result, nil
+	return result
 }
 
 type CalculationResult struct {
@@ -139,14 +303,108 @@ type CalculationResult struct {
 	TotalItems  int         `json:"total_items"`
 	TotalPacks  int         `json:"total_packs"`
 	OrderAmount int         `json:"order_amount"`
+	TotalWeight float64     `json:"total_weight_kg,omitempty"`
+	Backorder   *Backorder  `json:"backorder,omitempty"`
+	// Solver names the solver of a calculation made after SetSolver.
+	Solver string `json:"solver,omitempty"`
 }
 
 func (c *Calculator) CalculateWithDetails(amount int) (*CalculationResult, error) {
-	packs, err := c.Calculate(amount)
+	packs, s, err := c.solve(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	result := c.newResult(amount, packs)
+	if c.solver != "" {
+		result.Solver = s.Name()
+	}
+	return result, nil
+}
+
+// Alternatives returns up to limit trade-offs against the optimal result:
+// combinations that ship more items but need fewer packs. Each alternative
+// uses fewer packs than every smaller total, ordered by total items.
+func (c *Calculator) Alternatives(amount, limit int) ([]*CalculationResult, error) {
+	t, err := c.buildTable(amount)
 	if err != nil {
 		return nil, err
 	}
 
+	var results []*CalculationResult
+	fewest := impossible
+	for i := amount; i <= t.maxTarget && len(results) <= limit; i++ {
+		if t.dp[i] >= fewest {
+			continue
+		}
+		fewest = t.dp[i]
+		results = append(results, c.newResult(amount, t.backtrack(i)))
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+	// the first entry is the optimal result itself
+	return results[1:], nil
+}
+
+// CalculateMany calculates several amounts from one table built for the
+// largest. The table does not depend on its length, so each result is the
+// one CalculateWithDetails returns.
+func (c *Calculator) CalculateMany(amounts []int) ([]*CalculationResult, error) {
+	largest := 0
+	for _, amount := range amounts {
+		if amount <= 0 {
+			return nil, ErrInvalidAmount
+		}
+		largest = max(largest, amount)
+	}
+	if largest == 0 {
+		return nil, nil
+	}
+
+	t, err := c.buildTable(largest)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*CalculationResult, len(amounts))
+	for i, amount := range amounts {
+		target := amount
+		for t.dp[target] == impossible {
+			target++
+		}
+		results[i] = c.newResult(amount, t.backtrack(target))
+	}
+	return results, nil
+}
+
+// Overship is how many items are sent beyond the order amount.
+func (r *CalculationResult) Overship() int {
+	return r.TotalItems - r.OrderAmount
+}
+
+// Explanation describes in plain words why the result was chosen.
+func (r *CalculationResult) Explanation() string {
+	sizes := make([]int, 0, len(r.Packs))
+	for size := range r.Packs {
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		parts[i] = fmt.Sprintf("%dx%d", r.Packs[size], size)
+	}
+
+	return fmt.Sprintf(
+		"%d is the smallest total at or above the order of %d that whole packs can reach (%d over). "+
+			"The fewest packs that make exactly %d are %d: %s.",
+		r.TotalItems, r.OrderAmount, r.Overship(), r.TotalItems, r.TotalPacks, strings.Join(parts, " + "),
+	)
+}
+
+func (c *Calculator) newResult(amount int, packs map[int]int) *CalculationResult {
 	var totalItems, totalPacks int
 	for size, qty := range packs {
 		totalItems += size * qty
@@ -158,5 +416,6 @@ func (c *Calculator) CalculateWithDetails(amount int) (*CalculationResult, error
 		TotalItems:  totalItems,
 		TotalPacks:  totalPacks,
 		OrderAmount: amount,
-	}, nil
+		TotalWeight: c.Weight(packs),
+	}
 }
@@ -1,6 +1,12 @@
 package calculator
 
 import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sort"
 	"testing"
 )
 
@@ -245,6 +251,143 @@ func TestGetPackSizes(t *testing.T) {
 	}
 }
 
+func TestMaxAmount(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000})
+	calc.SetMaxAmount(10000)
+
+	if size, err := calc.TableSize(10000, Constraints{}); err != nil || size != 11000 {
+		t.Errorf("expected a table of 11000 at the maximum, got %d %v", size, err)
+	}
+	if _, err := calc.TableSize(10001, Constraints{}); !errors.Is(err, ErrAmountTooLarge) {
+		t.Errorf("expected ErrAmountTooLarge, got %v", err)
+	}
+	if _, err := calc.Calculate(10001); !errors.Is(err, ErrAmountTooLarge) {
+		t.Errorf("expected Calculate to enforce the maximum, got %v", err)
+	}
+
+	calc.SetPackSizes([]int{250, 2000000000})
+	if _, err := calc.TableSize(1, Constraints{}); !errors.Is(err, ErrPackSizeTooLarge) {
+		t.Errorf("expected ErrPackSizeTooLarge, got %v", err)
+	}
+	if _, err := calc.CalculateConstrained(1, Constraints{MaxTotalPacks: 1}); !errors.Is(err, ErrPackSizeTooLarge) {
+		t.Errorf("expected constrained calculations to enforce the maximum, got %v", err)
+	}
+}
+
+func TestAlternatives(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000, 2000, 5000})
+
+	alts, err := calc.Alternatives(12001, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 12250 needs 4 packs, the next total reachable with fewer is 3x5000
+	if len(alts) != 1 {
+		t.Fatalf("expected 1 alternative, got %d", len(alts))
+	}
+	if alts[0].TotalItems != 15000 || alts[0].TotalPacks != 3 {
+		t.Errorf("expected 15000 items in 3 packs, got %d in %d", alts[0].TotalItems, alts[0].TotalPacks)
+	}
+
+	alts, _ = calc.Alternatives(12001, 0)
+	if len(alts) != 0 {
+		t.Errorf("expected no alternatives with limit 0, got %d", len(alts))
+	}
+
+	if _, err := calc.Alternatives(0, 1); err != ErrInvalidAmount {
+		t.Errorf("got error = %v, want %v", err, ErrInvalidAmount)
+	}
+}
+
+func TestCalculateMany(t *testing.T) {
+	calc, _ := New([]int{23, 31, 53})
+
+	amounts := []int{500000, 1, 263, 12001, 263}
+	results, err := calc.CalculateMany(amounts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, amount := range amounts {
+		want, _ := calc.CalculateWithDetails(amount)
+		if results[i].TotalItems != want.TotalItems || !equalPacks(results[i].Packs, want.Packs) {
+			t.Errorf("%d: got %+v, want %+v", amount, results[i], want)
+		}
+	}
+
+	if _, err := calc.CalculateMany([]int{250, 0}); err != ErrInvalidAmount {
+		t.Errorf("got error = %v, want %v", err, ErrInvalidAmount)
+	}
+}
+
+func TestExplanation(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000, 2000, 5000})
+	result, _ := calc.CalculateWithDetails(12001)
+
+	want := "12250 is the smallest total at or above the order of 12001 that whole packs can reach (249 over). " +
+		"The fewest packs that make exactly 12250 are 4: 2x5000 + 1x2000 + 1x250."
+	if got := result.Explanation(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if result.Overship() != 249 {
+		t.Errorf("expected overship 249, got %d", result.Overship())
+	}
+}
+
+func TestParallelTableMatchesSequential(t *testing.T) {
+	check := func(sizes []int, amount, workers int) {
+		t.Helper()
+		sorted := slices.Clone(sizes)
+		sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+		want := newTableSequential(sorted, amount)
+		got := newTableParallel(sorted, amount, workers)
+		if got.maxTarget != want.maxTarget || !slices.Equal(got.dp, want.dp) || !slices.Equal(got.parent, want.parent) {
+			t.Fatalf("%v for %d with %d workers: tables differ", sizes, amount, workers)
+		}
+	}
+
+	catalogues := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{23, 31, 53},
+		{6, 9, 20},
+		{300, 300, 128, 700},
+		{1000},
+	}
+	for _, sizes := range catalogues {
+		for _, workers := range []int{1, 2, 3, 8} {
+			for _, amount := range []int{1, 251, 12001, 500000} {
+				check(sizes, amount, workers)
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 100; i++ {
+		sizes := make([]int, 1+rng.IntN(6))
+		for j := range sizes {
+			sizes[j] = 1 + rng.IntN(2000)
+		}
+		check(sizes, 1+rng.IntN(100000), 1+rng.IntN(8))
+	}
+}
+
+func BenchmarkBuildTable(b *testing.B) {
+	sizes := []int{5000, 2000, 1000, 500, 250}
+	for _, amount := range []int{1_000_000, 20_000_000} {
+		b.Run(fmt.Sprintf("sequential/%d", amount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				newTableSequential(sizes, amount)
+			}
+		})
+		b.Run(fmt.Sprintf("parallel/%d", amount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				newTableParallel(sizes, amount, runtime.GOMAXPROCS(0))
+			}
+		})
+	}
+}
+
 func BenchmarkCalculate(b *testing.B) {
 	calc, _ := New([]int{23, 31, 53})
 
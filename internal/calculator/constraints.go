@@ -0,0 +1,354 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+var (
+	ErrInvalidConstraint = errors.New("invalid constraint")
+	ErrInfeasible        = errors.New("no pack combination satisfies the constraints")
+)
+
+// Constraint names reported by InfeasibleError
+const (
+	ConstraintMaxTotalPacks = "max_total_packs"
+	ConstraintMaxPacks      = "max_packs"
+	ConstraintMinPacks      = "min_packs"
+	ConstraintMaxWeight     = "max_weight"
+	ConstraintCombined      = "combined"
+)
+
+// weightTolerance absorbs float rounding when comparing against MaxWeight
+const weightTolerance = 1e-9
+
+// Constraints limit the combinations a calculation may return. Zero values do
+// not constrain, except that MaxPacks[size] = 0 excludes the size.
+type Constraints struct {
+	// MaxTotalPacks caps the packs of the whole order, e.g. a dock limit.
+	MaxTotalPacks int `json:"max_total_packs,omitempty"`
+	// MaxPacks caps the packs of a size.
+	MaxPacks map[int]int `json:"max_packs,omitempty"`
+	// MinPacks is a minimum order quantity: a size is either unused or used
+	// at least this many times.
+	MinPacks map[int]int `json:"min_packs,omitempty"`
+	// MaxWeight caps the total weight in kilograms of packs with a weight.
+	MaxWeight float64 `json:"max_weight_kg,omitempty"`
+}
+
+func (c Constraints) IsZero() bool {
+	return c.MaxTotalPacks == 0 && len(c.MaxPacks) == 0 && len(c.MinPacks) == 0 && c.MaxWeight == 0
+}
+
+// validate checks the constraints of a calculation of amount. Minimums must
+// keep amount + min_packs*size, which sizes the table, within maxAmount, or
+// within an int when maxAmount is 0.
+func (c Constraints) validate(packSizes []int, amount, maxAmount int) error {
+	if c.MaxTotalPacks < 0 {
+		return fmt.Errorf("%w: max_total_packs must not be negative", ErrInvalidConstraint)
+	}
+	if c.MaxWeight < 0 {
+		return fmt.Errorf("%w: max_weight_kg must not be negative", ErrInvalidConstraint)
+	}
+
+	known := make(map[int]bool, len(packSizes))
+	for _, size := range packSizes {
+		known[size] = true
+	}
+	check := func(name string, m map[int]int) error {
+		for size, n := range m {
+			if !known[size] {
+				return fmt.Errorf("%w: %s names pack size %d, which is not available", ErrInvalidConstraint, name, size)
+			}
+			if n < 0 {
+				return fmt.Errorf("%w: %s for pack size %d must not be negative", ErrInvalidConstraint, name, size)
+			}
+		}
+		return nil
+	}
+	if err := check(ConstraintMaxPacks, c.MaxPacks); err != nil {
+		return err
+	}
+	if err := check(ConstraintMinPacks, c.MinPacks); err != nil {
+		return err
+	}
+
+	limit := maxAmount
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	for size, min := range c.MinPacks {
+		if max, ok := c.MaxPacks[size]; ok && max > 0 && min > max {
+			return fmt.Errorf("%w: min_packs %d exceeds max_packs %d for pack size %d", ErrInvalidConstraint, min, max, size)
+		}
+		if amount > limit || min > (limit-amount)/size {
+			return fmt.Errorf("%w: min_packs %d of pack size %d with the amount exceeds the maximum of %d items", ErrInvalidConstraint, min, size, limit)
+		}
+	}
+	return nil
+}
+
+// InfeasibleError explains which constraint rules out every combination.
+// Size is set for per-size constraints and MaxWeight for the weight limit.
+type InfeasibleError struct {
+	Constraint string  `json:"constraint"`
+	Size       int     `json:"size,omitempty"`
+	Limit      int     `json:"limit"`
+	MaxWeight  float64 `json:"max_weight_kg,omitempty"`
+	Amount     int     `json:"amount"`
+}
+
+func (e *InfeasibleError) Error() string {
+	switch e.Constraint {
+	case ConstraintMaxTotalPacks:
+		return fmt.Sprintf("no combination of at most %d packs covers %d items", e.Limit, e.Amount)
+	case ConstraintMaxPacks:
+		return fmt.Sprintf("at most %d packs of %d leave no combination covering %d items", e.Limit, e.Size, e.Amount)
+	case ConstraintMinPacks:
+		return fmt.Sprintf("the minimum of %d packs of %d leaves no combination covering %d items", e.Limit, e.Size, e.Amount)
+	case ConstraintMaxWeight:
+		return fmt.Sprintf("no combination of at most %g kg covers %d items", e.MaxWeight, e.Amount)
+	case ConstraintShipmentMaxItems:
+		return fmt.Sprintf("a pack of %d exceeds the shipment limit of %d items", e.Size, e.Limit)
+	case ConstraintShipmentMaxWeight:
+		return fmt.Sprintf("a pack of %d weighs more than the shipment limit of %g kg", e.Size, e.MaxWeight)
+	case ConstraintExact:
+		return fmt.Sprintf("no combination makes exactly %d items", e.Amount)
+	case ConstraintTolerance:
+		return fmt.Sprintf("no combination covers %d items with at most %d over", e.Amount, e.Limit)
+	}
+	return fmt.Sprintf("the constraints together leave no combination covering %d items", e.Amount)
+}
+
+func (e *InfeasibleError) Is(target error) bool {
+	return target == ErrInfeasible
+}
+
+// CalculateConstrained finds the smallest total at or above amount, then the
+// fewest packs for it, among the combinations the constraints allow. It
+// returns an *InfeasibleError when there is none.
+func (c *Calculator) CalculateConstrained(amount int, cons Constraints) (*CalculationResult, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if cons.IsZero() {
+		return c.CalculateWithDetails(amount)
+	}
+	if c.solver != "" {
+		return nil, ErrSolverNotApplicable
+	}
+	if err := c.checkLimits(amount); err != nil {
+		return nil, err
+	}
+	if err := cons.validate(c.packSizes, amount, c.maxAmount); err != nil {
+		return nil, err
+	}
+
+	w := Fulfilment{}.window(amount)
+	packs, ok := c.solveConstrained(amount, cons, w)
+	if !ok {
+		return nil, c.diagnose(amount, cons, w)
+	}
+	return c.newResult(amount, packs), nil
+}
+
+// diagnose relaxes one constraint at a time and blames the first whose
+// removal makes the order feasible. Minimums go first, as a cap that
+// conflicts with one is usually the limit the caller meant to keep.
+func (c *Calculator) diagnose(amount int, cons Constraints, w window) error {
+	for _, size := range sortedKeys(cons.MinPacks) {
+		relaxed := cons
+		relaxed.MinPacks = without(cons.MinPacks, size)
+		if _, ok := c.solveConstrained(amount, relaxed, w); ok {
+			return &InfeasibleError{Constraint: ConstraintMinPacks, Size: size, Limit: cons.MinPacks[size], Amount: amount}
+		}
+	}
+
+	if cons.MaxTotalPacks > 0 {
+		relaxed := cons
+		relaxed.MaxTotalPacks = 0
+		if _, ok := c.solveConstrained(amount, relaxed, w); ok {
+			return &InfeasibleError{Constraint: ConstraintMaxTotalPacks, Limit: cons.MaxTotalPacks, Amount: amount}
+		}
+	}
+
+	if cons.MaxWeight > 0 {
+		relaxed := cons
+		relaxed.MaxWeight = 0
+		if _, ok := c.solveConstrained(amount, relaxed, w); ok {
+			return &InfeasibleError{Constraint: ConstraintMaxWeight, MaxWeight: cons.MaxWeight, Amount: amount}
+		}
+	}
+
+	for _, size := range sortedKeys(cons.MaxPacks) {
+		relaxed := cons
+		relaxed.MaxPacks = without(cons.MaxPacks, size)
+		if _, ok := c.solveConstrained(amount, relaxed, w); ok {
+			return &InfeasibleError{Constraint: ConstraintMaxPacks, Size: size, Limit: cons.MaxPacks[size], Amount: amount}
+		}
+	}
+
+	return &InfeasibleError{Constraint: ConstraintCombined, Amount: amount}
+}
+
+// solveConstrained returns the best feasible total the window allows. With a
+// weight limit the fewest packs for a total may be too heavy while a lighter
+// combination fits, so a second, lightest-first pass is compared. The two
+// passes can still miss a combination when the pack and weight limits both
+// bind and neither the fewest nor the lightest packs satisfy both.
+func (c *Calculator) solveConstrained(amount int, cons Constraints, w window) (map[int]int, bool) {
+	packs, ok := c.solveBounded(amount, cons, w, false)
+	if cons.MaxWeight == 0 {
+		return packs, ok
+	}
+
+	lighter, lok := c.solveBounded(amount, cons, w, true)
+	switch {
+	case !lok:
+		return packs, ok
+	case !ok:
+		return lighter, true
+	}
+	a, b := c.newResult(amount, packs), c.newResult(amount, lighter)
+	if w.better(b.TotalItems, b.TotalPacks, a.TotalItems, a.TotalPacks) {
+		return lighter, true
+	}
+	return packs, true
+}
+
+// cost of the best combination reaching a total
+type cost struct {
+	packs  int
+	weight float64
+}
+
+// solveBounded runs a bounded knapsack over the pack sizes, one size at a
+// time, keeping the cheapest cost per total: fewest packs then lightest, or
+// lightest then fewest packs when lightFirst is set. For each size the
+// quantity is 0 or in [lo, hi]; a sliding window minimum over totals with the
+// same remainder keeps each size linear in the table length. It returns the
+// best total the window allows.
+func (c *Calculator) solveBounded(amount int, cons Constraints, w window, lightFirst bool) (map[int]int, bool) {
+	sizes := c.packSizes
+	less := func(a, b cost) bool {
+		if a.packs == impossible || b.packs == impossible {
+			return b.packs == impossible && a.packs != impossible
+		}
+		if lightFirst && a.weight != b.weight {
+			return a.weight < b.weight
+		}
+		if a.packs != b.packs {
+			return a.packs < b.packs
+		}
+		return a.weight < b.weight
+	}
+
+	maxTarget := c.tableSize(amount, cons)
+
+	dp := make([]cost, maxTarget+1)
+	for i := range dp {
+		dp[i] = cost{packs: impossible}
+	}
+	dp[0] = cost{}
+
+	choice := make([][]int32, len(sizes))
+	window := make([]int, 0, maxTarget+1)
+
+	for i, size := range sizes {
+		lo := max(1, cons.MinPacks[size])
+		hi := maxTarget / size
+		if n, ok := cons.MaxPacks[size]; ok {
+			hi = min(hi, n)
+		}
+		weight := c.packs[size].Weight
+
+		next := make([]cost, len(dp))
+		copy(next, dp)
+		choice[i] = make([]int32, len(dp))
+
+		if lo <= hi {
+			for r := 0; r < size && r <= maxTarget; r++ {
+				// window[head:] holds candidate j (as in total r+j*size) ordered by
+				// cost less j packs, which orders them for every k alike
+				window, head := window[:0], 0
+				value := func(j int) cost {
+					d := dp[r+j*size]
+					return cost{d.packs - j, d.weight - float64(j)*weight}
+				}
+
+				for k := 0; r+k*size <= maxTarget; k++ {
+					if j := k - lo; j >= 0 && dp[r+j*size].packs != impossible {
+						for len(window) > head && !less(value(window[len(window)-1]), value(j)) {
+							window = window[:len(window)-1]
+						}
+						window = append(window, j)
+					}
+					for head < len(window) && window[head] < k-hi {
+						head++
+					}
+					if head == len(window) {
+						continue
+					}
+
+					j := window[head]
+					t := r + k*size
+					v := value(j)
+					if candidate := (cost{v.packs + k, v.weight + float64(k)*weight}); less(candidate, next[t]) {
+						next[t] = candidate
+						choice[i][t] = int32(k - j)
+					}
+				}
+			}
+		}
+		dp = next
+	}
+
+	target := -1
+	for t := w.lo; t <= maxTarget && t <= w.hi; t++ {
+		d := dp[t]
+		if d.packs == impossible ||
+			(cons.MaxTotalPacks > 0 && d.packs > cons.MaxTotalPacks) ||
+			(cons.MaxWeight > 0 && d.weight > cons.MaxWeight+weightTolerance) {
+			continue
+		}
+		if target == -1 || w.better(t, d.packs, target, dp[target].packs) {
+			target = t
+		}
+		if w.first() {
+			break
+		}
+	}
+	if target == -1 {
+		return nil, false
+	}
+
+	packs := make(map[int]int)
+	for i := len(sizes) - 1; i >= 0; i-- {
+		if q := int(choice[i][target]); q > 0 {
+			packs[sizes[i]] = q
+			target -= q * sizes[i]
+		}
+	}
+	return packs, true
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+	return keys
+}
+
+func without(m map[int]int, key int) map[int]int {
+	out := make(map[int]int, len(m))
+	for k, v := range m {
+		if k != key {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,212 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCalculateConstrained(t *testing.T) {
+	sizes := []int{250, 500, 1000, 2000, 5000}
+
+	tests := []struct {
+		name      string
+		amount    int
+		cons      Constraints
+		wantPacks map[int]int
+	}{
+		{"no constraints", 12001, Constraints{}, map[int]int{5000: 2, 2000: 1, 250: 1}},
+		{"max of a size", 12001, Constraints{MaxPacks: map[int]int{5000: 1}}, map[int]int{5000: 1, 2000: 3, 1000: 1, 250: 1}},
+		{"excluded size", 251, Constraints{MaxPacks: map[int]int{500: 0}}, map[int]int{250: 2}},
+		{"minimum order quantity", 600, Constraints{MinPacks: map[int]int{250: 3}}, map[int]int{250: 3}},
+		{"minimum skipped when unused", 5000, Constraints{MinPacks: map[int]int{250: 4}}, map[int]int{5000: 1}},
+		{"max total packs", 1750, Constraints{MaxTotalPacks: 2}, map[int]int{2000: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc, _ := New(sizes)
+			result, err := calc.CalculateConstrained(tt.amount, tt.cons)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalPacks(result.Packs, tt.wantPacks) {
+				t.Errorf("got %v, want %v", result.Packs, tt.wantPacks)
+			}
+		})
+	}
+}
+
+func TestCalculateConstrainedMatchesBruteForce(t *testing.T) {
+	sizes := []int{3, 5, 7}
+	calc, _ := New(sizes)
+
+	constraints := []Constraints{
+		{MaxTotalPacks: 3},
+		{MaxPacks: map[int]int{7: 1, 5: 2}},
+		{MinPacks: map[int]int{3: 4}, MaxPacks: map[int]int{5: 1}},
+		{MinPacks: map[int]int{7: 2, 5: 3}, MaxTotalPacks: 6},
+	}
+
+	for _, cons := range constraints {
+		for amount := 1; amount <= 40; amount++ {
+			wantItems, wantPacks, ok := bruteForce(sizes, nil, amount, cons, Fulfilment{}.window(amount))
+			result, err := calc.CalculateConstrained(amount, cons)
+			if !ok {
+				if !errors.Is(err, ErrInfeasible) {
+					t.Errorf("%+v amount %d: expected infeasible, got %v %v", cons, amount, result, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%+v amount %d: unexpected error: %v", cons, amount, err)
+			}
+			if result.TotalItems != wantItems || result.TotalPacks != wantPacks {
+				t.Errorf("%+v amount %d: got %d items in %d packs, want %d in %d",
+					cons, amount, result.TotalItems, result.TotalPacks, wantItems, wantPacks)
+			}
+		}
+	}
+}
+
+// bruteForce enumerates every quantity of the three sizes up to 20
+func bruteForce(sizes []int, weights map[int]float64, amount int, cons Constraints, w window) (int, int, bool) {
+	allowed := func(size, q int) bool {
+		if max, ok := cons.MaxPacks[size]; ok && q > max {
+			return false
+		}
+		return q == 0 || q >= cons.MinPacks[size]
+	}
+
+	bestItems, bestPacks := -1, 0
+	for a := 0; a <= 20; a++ {
+		for b := 0; b <= 20; b++ {
+			for c := 0; c <= 20; c++ {
+				if !allowed(sizes[0], a) || !allowed(sizes[1], b) || !allowed(sizes[2], c) {
+					continue
+				}
+				items, packs := a*sizes[0]+b*sizes[1]+c*sizes[2], a+b+c
+				weight := float64(a)*weights[sizes[0]] + float64(b)*weights[sizes[1]] + float64(c)*weights[sizes[2]]
+				if items < w.lo || items > w.hi || (cons.MaxTotalPacks > 0 && packs > cons.MaxTotalPacks) ||
+					(cons.MaxWeight > 0 && weight > cons.MaxWeight+weightTolerance) {
+					continue
+				}
+				if bestItems == -1 || w.better(items, packs, bestItems, bestPacks) {
+					bestItems, bestPacks = items, packs
+				}
+			}
+		}
+	}
+	return bestItems, bestPacks, bestItems != -1
+}
+
+func TestMaxWeightMatchesBruteForce(t *testing.T) {
+	// the 5 pack is heavy, so fewer packs is not always lighter
+	weights := map[int]float64{3: 1, 5: 4, 7: 2}
+	calc, _ := NewWithPacks([]Pack{{Size: 3, Weight: 1}, {Size: 5, Weight: 4}, {Size: 7, Weight: 2}})
+
+	for _, cons := range []Constraints{
+		{MaxWeight: 6},
+		{MaxWeight: 9.5, MaxTotalPacks: 4},
+		{MaxWeight: 8, MinPacks: map[int]int{3: 2}},
+	} {
+		for amount := 1; amount <= 30; amount++ {
+			wantItems, wantPacks, ok := bruteForce([]int{7, 5, 3}, weights, amount, cons, Fulfilment{}.window(amount))
+			result, err := calc.CalculateConstrained(amount, cons)
+			if !ok {
+				if !errors.Is(err, ErrInfeasible) {
+					t.Errorf("%+v amount %d: expected infeasible, got %v %v", cons, amount, result, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%+v amount %d: unexpected error: %v", cons, amount, err)
+			}
+			if result.TotalItems != wantItems || result.TotalPacks != wantPacks || result.TotalWeight > cons.MaxWeight {
+				t.Errorf("%+v amount %d: got %d items in %d packs (%g kg), want %d in %d",
+					cons, amount, result.TotalItems, result.TotalPacks, result.TotalWeight, wantItems, wantPacks)
+			}
+		}
+	}
+}
+
+func TestCalculateConstrainedInfeasible(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000})
+
+	tests := []struct {
+		name string
+		cons Constraints
+		want InfeasibleError
+	}{
+		{"max total packs", Constraints{MaxTotalPacks: 2}, InfeasibleError{Constraint: ConstraintMaxTotalPacks, Limit: 2, Amount: 3000}},
+		{
+			"max of a size",
+			Constraints{MaxPacks: map[int]int{1000: 1, 500: 1, 250: 2}},
+			InfeasibleError{Constraint: ConstraintMaxPacks, Size: 1000, Limit: 1, Amount: 3000},
+		},
+		{
+			"minimum order quantity",
+			Constraints{MinPacks: map[int]int{1000: 4}, MaxTotalPacks: 3},
+			InfeasibleError{Constraint: ConstraintMinPacks, Size: 1000, Limit: 4, Amount: 3000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := calc.CalculateConstrained(3000, tt.cons)
+
+			var infeasible *InfeasibleError
+			if !errors.As(err, &infeasible) || *infeasible != tt.want {
+				t.Fatalf("got %v, want %+v", err, tt.want)
+			}
+			if !errors.Is(err, ErrInfeasible) {
+				t.Error("expected errors.Is(err, ErrInfeasible)")
+			}
+		})
+	}
+}
+
+func TestConstraintsValidation(t *testing.T) {
+	calc, _ := New([]int{250, 500})
+
+	for _, cons := range []Constraints{
+		{MaxTotalPacks: -1},
+		{MaxPacks: map[int]int{300: 1}},
+		{MinPacks: map[int]int{250: -1}},
+		{MinPacks: map[int]int{250: 3}, MaxPacks: map[int]int{250: 2}},
+		// the table would need more totals than an int holds
+		{MinPacks: map[int]int{500: 1 << 60}},
+	} {
+		if _, err := calc.CalculateConstrained(100, cons); !errors.Is(err, ErrInvalidConstraint) {
+			t.Errorf("%+v: got %v, want %v", cons, err, ErrInvalidConstraint)
+		}
+	}
+}
+
+func TestMinPacksTableSize(t *testing.T) {
+	calc, _ := New([]int{250, 500})
+	calc.SetMaxAmount(10000)
+
+	if size, err := calc.TableSize(100, Constraints{MinPacks: map[int]int{250: 4}}); err != nil || size != 1100 {
+		t.Errorf("expected the minimum of 4x250 to size the table at 1100, got %d %v", size, err)
+	}
+
+	cons := Constraints{MinPacks: map[int]int{500: 20}}
+	if _, err := calc.TableSize(1, cons); !errors.Is(err, ErrInvalidConstraint) {
+		t.Errorf("expected ErrInvalidConstraint above the maximum, got %v", err)
+	}
+	if _, err := calc.CalculateConstrained(1, cons); !errors.Is(err, ErrInvalidConstraint) {
+		t.Errorf("expected CalculateConstrained to enforce the maximum, got %v", err)
+	}
+}
+
+func equalPacks(got, want map[int]int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for size, qty := range want {
+		if got[size] != qty {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package calculator
+
+import "errors"
+
+// Stable codes of calculator errors, shared by the REST, GraphQL and gRPC
+// APIs. Clients branch on these, so they are never renamed.
+const (
+	CodeNoPackSizes       = "no_pack_sizes"
+	CodeInvalidPackSize   = "invalid_pack_size"
+	CodeInvalidAmount     = "invalid_amount"
+	CodeInvalidPack       = "invalid_pack"
+	CodeInvalidConstraint = "invalid_constraint"
+	CodeInvalidPackaging  = "invalid_packaging"
+	CodeInfeasible        = "infeasible"
+	CodeValidationFailed  = "validation_failed"
+	CodeCalculationError  = "calculation_error"
+)
+
+// ErrorCode maps calculator errors to codes:
+//
+//	ErrNoPackSizes                           -> no_pack_sizes
+//	ErrInvalidPackSize, ErrPackSizeTooLarge  -> invalid_pack_size
+//	ErrInvalidAmount, ErrAmountTooLarge      -> invalid_amount
+//	ErrInvalidPack                           -> invalid_pack
+//	ErrInvalidConstraint                     -> invalid_constraint
+//	ErrInvalidPackaging                      -> invalid_packaging
+//	ErrInfeasible                            -> infeasible
+//	ErrUnknownSolver                         -> validation_failed
+//	ErrSolverNotApplicable                   -> validation_failed
+//	anything else                            -> calculation_error
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrNoPackSizes):
+		return CodeNoPackSizes
+	case errors.Is(err, ErrInvalidPackSize), errors.Is(err, ErrPackSizeTooLarge):
+		return CodeInvalidPackSize
+	case errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrAmountTooLarge):
+		return CodeInvalidAmount
+	case errors.Is(err, ErrInvalidPack):
+		return CodeInvalidPack
+	case errors.Is(err, ErrInvalidConstraint):
+		return CodeInvalidConstraint
+	case errors.Is(err, ErrInvalidPackaging):
+		return CodeInvalidPackaging
+	case errors.Is(err, ErrInfeasible):
+		return CodeInfeasible
+	case errors.Is(err, ErrUnknownSolver), errors.Is(err, ErrSolverNotApplicable):
+		return CodeValidationFailed
+	}
+	return CodeCalculationError
+}
@@ -0,0 +1,30 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrNoPackSizes, CodeNoPackSizes},
+		{ErrInvalidPackSize, CodeInvalidPackSize},
+		{fmt.Errorf("%w of 10", ErrPackSizeTooLarge), CodeInvalidPackSize},
+		{ErrInvalidAmount, CodeInvalidAmount},
+		{fmt.Errorf("%w of 10", ErrAmountTooLarge), CodeInvalidAmount},
+		{&InfeasibleError{Constraint: ConstraintMaxTotalPacks}, CodeInfeasible},
+		{ErrUnknownSolver, CodeValidationFailed},
+		{ErrSolverNotApplicable, CodeValidationFailed},
+		{errors.New("boom"), CodeCalculationError},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
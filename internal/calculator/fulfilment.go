@@ -0,0 +1,241 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Fulfilment modes
+const (
+	// ModeMinimalOvership ships the smallest total at or above the amount,
+	// then the fewest packs. It is the default.
+	ModeMinimalOvership = "minimal_overship"
+	// ModeExact ships exactly the amount or fails.
+	ModeExact = "exact"
+	// ModeTolerance ships the fewest packs within a cap above the amount,
+	// then the smallest total.
+	ModeTolerance = "tolerance"
+	// ModeBackorder ships the largest total at or below the amount that the
+	// inventory allows, then the fewest packs, and backorders the rest.
+	ModeBackorder = "backorder"
+)
+
+// Constraint names reported when a fulfilment mode rules out every total
+const (
+	ConstraintExact     = "exact"
+	ConstraintTolerance = "tolerance"
+)
+
+// Fulfilment selects the totals a result may ship and how they are ranked.
+// The zero value is ModeMinimalOvership.
+type Fulfilment struct {
+	Mode      string    `json:"mode,omitempty"`
+	Tolerance Tolerance `json:"tolerance,omitempty"`
+	// Inventory is the stock of ModeBackorder in packs per size; sizes it
+	// does not list are unlimited.
+	Inventory map[int]int `json:"inventory,omitempty"`
+}
+
+// Backorder is the part of an order ModeBackorder could not ship, with the
+// packs suggested for it from the full catalogue.
+type Backorder struct {
+	Amount     int         `json:"amount"`
+	Packs      map[int]int `json:"packs"`
+	TotalItems int         `json:"total_items"`
+	TotalPacks int         `json:"total_packs"`
+}
+
+// Tolerance caps the over-shipment of ModeTolerance, either in items or as a
+// percentage of the amount.
+type Tolerance struct {
+	Items   int     `json:"items,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+}
+
+func (f Fulfilment) Validate() error {
+	if f.Tolerance.Items < 0 || f.Tolerance.Percent < 0 {
+		return fmt.Errorf("%w: tolerance must not be negative", ErrInvalidConstraint)
+	}
+	for size, n := range f.Inventory {
+		if n < 0 {
+			return fmt.Errorf("%w: inventory for pack size %d must not be negative", ErrInvalidConstraint, size)
+		}
+	}
+
+	switch f.Mode {
+	case "", ModeMinimalOvership, ModeExact, ModeTolerance, ModeBackorder:
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidConstraint, f.Mode)
+	}
+	if f.Tolerance != (Tolerance{}) && f.Mode != ModeTolerance {
+		return fmt.Errorf("%w: tolerance needs mode %s", ErrInvalidConstraint, ModeTolerance)
+	}
+	if f.Tolerance.Items > 0 && f.Tolerance.Percent > 0 {
+		return fmt.Errorf("%w: tolerance takes either items or percent", ErrInvalidConstraint)
+	}
+	if len(f.Inventory) > 0 && f.Mode != ModeBackorder {
+		return fmt.Errorf("%w: inventory needs mode %s", ErrInvalidConstraint, ModeBackorder)
+	}
+	return nil
+}
+
+// Rankings of the totals in a window
+const (
+	rankSmallest    = iota // smallest total, then fewest packs
+	rankFewestPacks        // fewest packs, then smallest total
+	rankLargest            // largest total, then fewest packs
+)
+
+// window is the range of totals a fulfilment allows and how it ranks them
+type window struct {
+	lo, hi int
+	rank   int
+}
+
+func (f Fulfilment) window(amount int) window {
+	switch f.Mode {
+	case ModeExact:
+		return window{lo: amount, hi: amount}
+	case ModeTolerance:
+		over := f.Tolerance.Items
+		if f.Tolerance.Percent > 0 {
+			over = int(math.Floor(float64(amount) * f.Tolerance.Percent / 100))
+		}
+		return window{lo: amount, hi: amount + over, rank: rankFewestPacks}
+	case ModeBackorder:
+		return window{lo: 0, hi: amount, rank: rankLargest}
+	}
+	return window{lo: amount, hi: math.MaxInt32}
+}
+
+// better reports whether a result of items in packs ranks above one of
+// bestItems in bestPacks
+func (w window) better(items, packs, bestItems, bestPacks int) bool {
+	switch {
+	case w.rank == rankFewestPacks && packs != bestPacks:
+		return packs < bestPacks
+	case w.rank == rankLargest && items != bestItems:
+		return items > bestItems
+	case items != bestItems:
+		return items < bestItems
+	}
+	return packs < bestPacks
+}
+
+// first reports whether the first total found, scanning up, is the best
+func (w window) first() bool {
+	return w.rank == rankSmallest
+}
+
+func (w window) infeasible(amount int) *InfeasibleError {
+	if w.rank == rankFewestPacks {
+		return &InfeasibleError{Constraint: ConstraintTolerance, Limit: w.hi - amount, Amount: amount}
+	}
+	return &InfeasibleError{Constraint: ConstraintExact, Amount: amount}
+}
+
+// CalculateFulfilment is CalculateConstrained with a fulfilment mode. When
+// the mode alone rules out every total the *InfeasibleError names the mode.
+// A fewest-packs result never needs a total beyond amount plus the largest
+// pack, as dropping a pack from it would still cover the amount.
+//
+// ModeBackorder never fails on inventory: it may ship nothing and backorder
+// the whole amount. The result's Backorder suggests packs for the remainder
+// from the full catalogue, ignoring inventory and constraints.
+func (c *Calculator) CalculateFulfilment(amount int, cons Constraints, f Fulfilment) (*CalculationResult, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	if f.Mode == "" || f.Mode == ModeMinimalOvership {
+		return c.CalculateConstrained(amount, cons)
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if c.solver != "" {
+		return nil, ErrSolverNotApplicable
+	}
+	if err := c.checkLimits(amount); err != nil {
+		return nil, err
+	}
+	if err := cons.validate(c.packSizes, amount, c.maxAmount); err != nil {
+		return nil, err
+	}
+	for size := range f.Inventory {
+		if !slices.Contains(c.packSizes, size) {
+			return nil, fmt.Errorf("%w: inventory names pack size %d, which is not available", ErrInvalidConstraint, size)
+		}
+	}
+	cons = cons.withInventory(f.Inventory)
+
+	w := f.window(amount)
+	var result *CalculationResult
+	if cons.IsZero() {
+		t, err := c.buildTable(amount)
+		if err != nil {
+			return nil, err
+		}
+		target := t.pick(w)
+		if target == -1 {
+			return nil, w.infeasible(amount)
+		}
+		result = c.newResult(amount, t.backtrack(target))
+	} else {
+		packs, ok := c.solveConstrained(amount, cons, w)
+		if !ok {
+			return nil, c.diagnose(amount, cons, w)
+		}
+		result = c.newResult(amount, packs)
+	}
+
+	if f.Mode == ModeBackorder && result.TotalItems < amount {
+		remainder := amount - result.TotalItems
+		suggested, err := c.CalculateWithDetails(remainder)
+		if err != nil {
+			return nil, err
+		}
+		result.Backorder = &Backorder{
+			Amount:     remainder,
+			Packs:      suggested.Packs,
+			TotalItems: suggested.TotalItems,
+			TotalPacks: suggested.TotalPacks,
+		}
+	}
+	return result, nil
+}
+
+// withInventory caps MaxPacks by the stock of each size
+func (c Constraints) withInventory(inventory map[int]int) Constraints {
+	if len(inventory) == 0 {
+		return c
+	}
+	caps := make(map[int]int, len(c.MaxPacks)+len(inventory))
+	for size, n := range c.MaxPacks {
+		caps[size] = n
+	}
+	for size, n := range inventory {
+		if max, ok := caps[size]; !ok || n < max {
+			caps[size] = n
+		}
+	}
+	c.MaxPacks = caps
+	return c
+}
+
+// pick returns the best total the window allows, or -1
+func (t *table) pick(w window) int {
+	best := -1
+	for i := w.lo; i <= t.maxTarget && i <= w.hi; i++ {
+		if t.dp[i] == impossible {
+			continue
+		}
+		if best == -1 || w.better(i, t.dp[i], best, t.dp[best]) {
+			best = i
+		}
+		if w.first() {
+			break
+		}
+	}
+	return best
+}
@@ -0,0 +1,169 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCalculateFulfilment(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000, 2000, 5000})
+
+	tests := []struct {
+		name      string
+		amount    int
+		f         Fulfilment
+		wantPacks map[int]int
+	}{
+		{"default", 4001, Fulfilment{}, map[int]int{2000: 2, 250: 1}},
+		{"minimal overship", 4001, Fulfilment{Mode: ModeMinimalOvership}, map[int]int{2000: 2, 250: 1}},
+		{"exact", 12000, Fulfilment{Mode: ModeExact}, map[int]int{5000: 2, 2000: 1}},
+		{"tolerance in items", 4001, Fulfilment{Mode: ModeTolerance, Tolerance: Tolerance{Items: 1000}}, map[int]int{5000: 1}},
+		{"tolerance in percent", 4001, Fulfilment{Mode: ModeTolerance, Tolerance: Tolerance{Percent: 25}}, map[int]int{5000: 1}},
+		{"tight tolerance keeps the smallest total", 4001, Fulfilment{Mode: ModeTolerance, Tolerance: Tolerance{Percent: 20}}, map[int]int{2000: 2, 250: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.CalculateFulfilment(tt.amount, Constraints{}, tt.f)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalPacks(result.Packs, tt.wantPacks) {
+				t.Errorf("got %v, want %v", result.Packs, tt.wantPacks)
+			}
+		})
+	}
+}
+
+func TestCalculateFulfilmentInfeasible(t *testing.T) {
+	calc, _ := New([]int{250, 500})
+
+	tests := []struct {
+		name string
+		f    Fulfilment
+		cons Constraints
+		want InfeasibleError
+	}{
+		{"exact", Fulfilment{Mode: ModeExact}, Constraints{}, InfeasibleError{Constraint: ConstraintExact, Amount: 251}},
+		{"tolerance", Fulfilment{Mode: ModeTolerance, Tolerance: Tolerance{Items: 10}}, Constraints{}, InfeasibleError{Constraint: ConstraintTolerance, Limit: 10, Amount: 251}},
+		{
+			"constraint within the tolerance",
+			Fulfilment{Mode: ModeTolerance, Tolerance: Tolerance{Items: 249}},
+			Constraints{MaxPacks: map[int]int{500: 0}, MaxTotalPacks: 1},
+			InfeasibleError{Constraint: ConstraintMaxTotalPacks, Limit: 1, Amount: 251},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := calc.CalculateFulfilment(251, tt.cons, tt.f)
+
+			var infeasible *InfeasibleError
+			if !errors.As(err, &infeasible) || *infeasible != tt.want {
+				t.Fatalf("got %v, want %+v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateFulfilmentMatchesBruteForce(t *testing.T) {
+	sizes := []int{7, 5, 3}
+	calc, _ := New(sizes)
+
+	modes := []Fulfilment{
+		{Mode: ModeExact},
+		{Mode: ModeTolerance, Tolerance: Tolerance{Items: 4}},
+		{Mode: ModeTolerance, Tolerance: Tolerance{Percent: 30}},
+		{Mode: ModeBackorder},
+		{Mode: ModeBackorder, Inventory: map[int]int{7: 1, 5: 2}},
+	}
+	constraints := []Constraints{
+		{},
+		{MaxTotalPacks: 4},
+		{MinPacks: map[int]int{3: 3}, MaxPacks: map[int]int{7: 2}},
+	}
+
+	for _, f := range modes {
+		for _, cons := range constraints {
+			for amount := 1; amount <= 40; amount++ {
+				wantItems, wantPacks, ok := bruteForce(sizes, nil, amount, cons.withInventory(f.Inventory), f.window(amount))
+				result, err := calc.CalculateFulfilment(amount, cons, f)
+				if !ok {
+					if !errors.Is(err, ErrInfeasible) {
+						t.Errorf("%+v %+v amount %d: expected infeasible, got %v %v", f, cons, amount, result, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%+v %+v amount %d: unexpected error: %v", f, cons, amount, err)
+				}
+				if result.TotalItems != wantItems || result.TotalPacks != wantPacks {
+					t.Errorf("%+v %+v amount %d: got %d items in %d packs, want %d in %d",
+						f, cons, amount, result.TotalItems, result.TotalPacks, wantItems, wantPacks)
+				}
+			}
+		}
+	}
+}
+
+func TestFulfilmentValidation(t *testing.T) {
+	for _, f := range []Fulfilment{
+		{Mode: "cheapest"},
+		{Tolerance: Tolerance{Items: 5}},
+		{Mode: ModeExact, Tolerance: Tolerance{Percent: 5}},
+		{Mode: ModeTolerance, Tolerance: Tolerance{Items: 5, Percent: 5}},
+		{Mode: ModeTolerance, Tolerance: Tolerance{Items: -1}},
+		{Mode: ModeExact, Inventory: map[int]int{250: 1}},
+		{Mode: ModeBackorder, Inventory: map[int]int{250: -1}},
+	} {
+		if err := f.Validate(); !errors.Is(err, ErrInvalidConstraint) {
+			t.Errorf("%+v: expected ErrInvalidConstraint, got %v", f, err)
+		}
+	}
+}
+
+func TestCalculateBackorder(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000, 2000, 5000})
+
+	tests := []struct {
+		name          string
+		amount        int
+		inventory     map[int]int
+		wantPacks     map[int]int
+		wantBackorder *Backorder
+	}{
+		{
+			"short stock",
+			12001,
+			map[int]int{5000: 1, 2000: 1, 1000: 0, 500: 0, 250: 4},
+			map[int]int{5000: 1, 2000: 1, 250: 4},
+			&Backorder{Amount: 4001, Packs: map[int]int{2000: 2, 250: 1}, TotalItems: 4250, TotalPacks: 3},
+		},
+		{"enough stock", 750, nil, map[int]int{500: 1, 250: 1}, nil},
+		{"below the smallest pack", 100, nil, map[int]int{}, &Backorder{Amount: 100, Packs: map[int]int{250: 1}, TotalItems: 250, TotalPacks: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.CalculateFulfilment(tt.amount, Constraints{}, Fulfilment{Mode: ModeBackorder, Inventory: tt.inventory})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equalPacks(result.Packs, tt.wantPacks) {
+				t.Errorf("got %v, want %v", result.Packs, tt.wantPacks)
+			}
+			if (result.Backorder == nil) != (tt.wantBackorder == nil) {
+				t.Fatalf("got backorder %+v, want %+v", result.Backorder, tt.wantBackorder)
+			}
+			if b := result.Backorder; b != nil && (b.Amount != tt.wantBackorder.Amount || !equalPacks(b.Packs, tt.wantBackorder.Packs) ||
+				b.TotalItems != tt.wantBackorder.TotalItems || b.TotalPacks != tt.wantBackorder.TotalPacks) {
+				t.Errorf("got backorder %+v, want %+v", b, tt.wantBackorder)
+			}
+		})
+	}
+
+	_, err := calc.CalculateFulfilment(100, Constraints{}, Fulfilment{Mode: ModeBackorder, Inventory: map[int]int{300: 1}})
+	if !errors.Is(err, ErrInvalidConstraint) {
+		t.Errorf("expected an unknown inventory size to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrInvalidPack = errors.New("pack weight and dimensions must not be negative")
+
+// Pack is a pack definition. Only Size, the item count, is required.
+type Pack struct {
+	Size       int         `json:"size"`
+	Weight     float64     `json:"weight_kg,omitempty"`
+	Dimensions *Dimensions `json:"dimensions,omitempty"`
+	Label      string      `json:"label,omitempty"`
+}
+
+// Dimensions of a filled pack in centimetres.
+type Dimensions struct {
+	Length float64 `json:"length_cm"`
+	Width  float64 `json:"width_cm"`
+	Height float64 `json:"height_cm"`
+}
+
+func (p Pack) Validate() error {
+	if p.Size <= 0 {
+		return ErrInvalidPackSize
+	}
+	if p.Weight < 0 {
+		return ErrInvalidPack
+	}
+	if d := p.Dimensions; d != nil && (d.Length < 0 || d.Width < 0 || d.Height < 0) {
+		return ErrInvalidPack
+	}
+	return nil
+}
+
+// PacksFromSizes returns bare packs for a list of sizes
+func PacksFromSizes(sizes []int) []Pack {
+	packs := make([]Pack, len(sizes))
+	for i, size := range sizes {
+		packs[i] = Pack{Size: size}
+	}
+	return packs
+}
+
+// Sizes returns the size of every pack, in order
+func Sizes(packs []Pack) []int {
+	sizes := make([]int, len(packs))
+	for i, p := range packs {
+		sizes[i] = p.Size
+	}
+	return sizes
+}
+
+// NewWithPacks is New for pack definitions, so results carry their weight.
+func NewWithPacks(packs []Pack) (*Calculator, error) {
+	for _, p := range packs {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := New(Sizes(packs))
+	if err != nil {
+		return nil, err
+	}
+	c.packs = make(map[int]Pack, len(packs))
+	for _, p := range packs {
+		c.packs[p.Size] = p
+	}
+	return c, nil
+}
+
+// Pack returns the definition of a size, bare when it has no attributes.
+func (c *Calculator) Pack(size int) Pack {
+	if p, ok := c.packs[size]; ok {
+		return p
+	}
+	return Pack{Size: size}
+}
+
+// Weight is the total weight of a pack combination in kilograms
+func (c *Calculator) Weight(packs map[int]int) float64 {
+	var total float64
+	for size, qty := range packs {
+		total += c.packs[size].Weight * float64(qty)
+	}
+	// keep sums like 0.1+0.2 readable in responses
+	return math.Round(total*1e6) / 1e6
+}
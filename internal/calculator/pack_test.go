@@ -0,0 +1,51 @@
+package calculator
+
+import "testing"
+
+func TestNewWithPacks(t *testing.T) {
+	calc, err := NewWithPacks([]Pack{
+		{Size: 250, Weight: 0.3, Label: "S"},
+		{Size: 500, Weight: 0.55, Dimensions: &Dimensions{Length: 30, Width: 20, Height: 10}},
+		{Size: 1000},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := calc.CalculateWithDetails(751)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.TotalWeight != 0 || result.Packs[1000] != 1 {
+		t.Errorf("expected one unweighted 1000 pack, got %+v", result)
+	}
+
+	result, _ = calc.CalculateWithDetails(501)
+	if result.TotalWeight != 0.85 {
+		t.Errorf("expected 0.85 kg, got %g", result.TotalWeight)
+	}
+	if calc.Pack(250).Label != "S" || calc.Pack(42).Size != 42 {
+		t.Error("unexpected pack definitions")
+	}
+}
+
+func TestNewWithPacksValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		packs []Pack
+		want  error
+	}{
+		{"empty", nil, ErrNoPackSizes},
+		{"zero size", []Pack{{Size: 0}}, ErrInvalidPackSize},
+		{"negative weight", []Pack{{Size: 250, Weight: -1}}, ErrInvalidPack},
+		{"negative dimension", []Pack{{Size: 250, Dimensions: &Dimensions{Length: -1}}}, ErrInvalidPack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWithPacks(tt.packs); err != tt.want {
+				t.Errorf("got error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrInvalidPackaging = errors.New("invalid packaging")
+
+// Handling unit kinds of a packaging tree
+const (
+	UnitPallet = "pallet"
+	UnitCarton = "carton"
+	UnitPack   = "pack"
+)
+
+// CartonType is a carton holding up to Capacity packs of one size. A size
+// may have several carton types, e.g. a case of 24 and a half case of 12.
+type CartonType struct {
+	Name     string `json:"name"`
+	PackSize int    `json:"pack_size"`
+	Capacity int    `json:"capacity"`
+}
+
+// Packaging groups packs into cartons and cartons onto pallets. Packs of a
+// size without a carton type ship loose; a zero PalletCapacity ships cartons
+// without pallets.
+type Packaging struct {
+	Cartons        []CartonType `json:"cartons"`
+	PalletCapacity int          `json:"pallet_capacity,omitempty"`
+}
+
+func (p Packaging) Validate() error {
+	for _, ct := range p.Cartons {
+		if ct.Name == "" {
+			return fmt.Errorf("%w: every carton type needs a name", ErrInvalidPackaging)
+		}
+		if ct.PackSize <= 0 || ct.Capacity <= 0 {
+			return fmt.Errorf("%w: carton type %q needs a positive pack_size and capacity", ErrInvalidPackaging, ct.Name)
+		}
+	}
+	if p.PalletCapacity < 0 {
+		return fmt.Errorf("%w: pallet_capacity must not be negative", ErrInvalidPackaging)
+	}
+	return nil
+}
+
+// HandlingUnit is a node of a packaging tree. Pack nodes are leaves holding
+// Quantity packs of Size; Items counts the items below any node.
+type HandlingUnit struct {
+	Kind     string         `json:"kind"`
+	Name     string         `json:"name,omitempty"`
+	Size     int            `json:"size,omitempty"`
+	Quantity int            `json:"quantity,omitempty"`
+	Items    int            `json:"items"`
+	Contents []HandlingUnit `json:"contents,omitempty"`
+}
+
+// PackagingPlan is the packaging tree of an order. Units are the top level:
+// pallets, cartons not on a pallet and loose packs, which count one handling
+// unit per pack.
+type PackagingPlan struct {
+	Units         []HandlingUnit `json:"units"`
+	HandlingUnits int            `json:"handling_units"`
+	Pallets       int            `json:"pallets"`
+	Cartons       int            `json:"cartons"`
+	LoosePacks    int            `json:"loose_packs"`
+}
+
+// PlanPackaging rolls packs, e.g. the result of Calculate, up into cartons
+// and pallets with the fewest handling units. Each size uses the fewest
+// cartons that hold all its packs, then the least spare capacity; cartons
+// fill largest first. Pallets are spread evenly.
+func PlanPackaging(packs map[int]int, p Packaging) (*PackagingPlan, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	types := make(map[int][]CartonType)
+	for _, ct := range p.Cartons {
+		types[ct.PackSize] = append(types[ct.PackSize], ct)
+	}
+
+	sizes := make([]int, 0, len(packs))
+	for size, qty := range packs {
+		if qty > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	plan := &PackagingPlan{}
+	var cartons, loose []HandlingUnit
+	for _, size := range sizes {
+		qty := packs[size]
+		if len(types[size]) == 0 {
+			loose = append(loose, packUnit(size, qty))
+			plan.LoosePacks += qty
+			continue
+		}
+
+		remaining := qty
+		for _, ct := range chooseCartons(types[size], qty) {
+			n := min(ct.Capacity, remaining)
+			remaining -= n
+			cartons = append(cartons, HandlingUnit{
+				Kind:     UnitCarton,
+				Name:     ct.Name,
+				Items:    n * size,
+				Contents: []HandlingUnit{packUnit(size, n)},
+			})
+		}
+	}
+	plan.Cartons = len(cartons)
+
+	if p.PalletCapacity > 0 && len(cartons) > 0 {
+		plan.Pallets = ceilDiv(len(cartons), p.PalletCapacity)
+		for i, start := 0, 0; i < plan.Pallets; i++ {
+			// the first len%pallets pallets take one carton more
+			n := len(cartons) / plan.Pallets
+			if i < len(cartons)%plan.Pallets {
+				n++
+			}
+			pallet := HandlingUnit{Kind: UnitPallet, Contents: cartons[start : start+n]}
+			for _, c := range pallet.Contents {
+				pallet.Items += c.Items
+			}
+			plan.Units = append(plan.Units, pallet)
+			start += n
+		}
+	} else {
+		plan.Units = append(plan.Units, cartons...)
+	}
+	plan.Units = append(plan.Units, loose...)
+
+	plan.HandlingUnits = len(plan.Units) - len(loose) + plan.LoosePacks
+	return plan, nil
+}
+
+func packUnit(size, qty int) HandlingUnit {
+	return HandlingUnit{Kind: UnitPack, Size: size, Quantity: qty, Items: size * qty}
+}
+
+// chooseCartons returns the fewest cartons whose capacity holds qty packs,
+// then the least spare capacity, largest first. It is the pack calculation
+// with the priorities swapped: dp[t] is the fewest cartons of capacity t.
+func chooseCartons(types []CartonType, qty int) []CartonType {
+	sort.Slice(types, func(i, j int) bool { return types[i].Capacity > types[j].Capacity })
+
+	maxTarget := qty + types[0].Capacity
+	dp := make([]int, maxTarget+1)
+	parent := make([]int, maxTarget+1)
+	for i := range dp {
+		dp[i] = impossible
+	}
+	dp[0] = 0
+	for t := 0; t <= maxTarget; t++ {
+		if dp[t] == impossible {
+			continue
+		}
+		for i, ct := range types {
+			if next := t + ct.Capacity; next <= maxTarget && dp[t]+1 < dp[next] {
+				dp[next] = dp[t] + 1
+				parent[next] = i
+			}
+		}
+	}
+
+	best := -1
+	for t := qty; t <= maxTarget; t++ {
+		if dp[t] != impossible && (best == -1 || dp[t] < dp[best]) {
+			best = t
+		}
+	}
+
+	var chosen []CartonType
+	for t := best; t > 0; t -= types[parent[t]].Capacity {
+		chosen = append(chosen, types[parent[t]])
+	}
+	sort.SliceStable(chosen, func(i, j int) bool { return chosen[i].Capacity > chosen[j].Capacity })
+	return chosen
+}
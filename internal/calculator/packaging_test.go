@@ -0,0 +1,99 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlanPackaging(t *testing.T) {
+	packaging := Packaging{
+		Cartons: []CartonType{
+			{Name: "case", PackSize: 250, Capacity: 24},
+			{Name: "half case", PackSize: 250, Capacity: 12},
+			{Name: "crate", PackSize: 2000, Capacity: 4},
+		},
+		PalletCapacity: 2,
+	}
+
+	plan, err := PlanPackaging(map[int]int{250: 30, 2000: 5, 5000: 2}, packaging)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 30 packs of 250 fit a case and a half case, 5 of 2000 two crates
+	if plan.Cartons != 4 || plan.Pallets != 2 || plan.LoosePacks != 2 || plan.HandlingUnits != 4 {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+	if len(plan.Units) != 3 || plan.Units[0].Kind != UnitPallet || plan.Units[2].Kind != UnitPack || plan.Units[2].Size != 5000 {
+		t.Fatalf("unexpected units %+v", plan.Units)
+	}
+
+	crates := plan.Units[0].Contents
+	if len(crates) != 2 || crates[0].Name != "crate" || crates[0].Contents[0].Quantity != 4 || crates[1].Contents[0].Quantity != 1 {
+		t.Errorf("expected a full and a partial crate first, got %+v", crates)
+	}
+	cases := plan.Units[1].Contents
+	if len(cases) != 2 || cases[0].Name != "case" || cases[1].Name != "half case" || cases[1].Contents[0].Quantity != 6 {
+		t.Errorf("expected a case and a half case, got %+v", cases)
+	}
+
+	var items int
+	for _, u := range plan.Units {
+		items += u.Items
+	}
+	if items != 30*250+5*2000+2*5000 {
+		t.Errorf("tree holds %d items", items)
+	}
+}
+
+func TestPlanPackagingWithoutPallets(t *testing.T) {
+	plan, err := PlanPackaging(map[int]int{500: 7}, Packaging{Cartons: []CartonType{{Name: "box", PackSize: 500, Capacity: 3}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Cartons != 3 || plan.Pallets != 0 || plan.HandlingUnits != 3 || plan.Units[2].Items != 500 {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+}
+
+func TestChooseCartonsPrefersFewestThenTightest(t *testing.T) {
+	types := []CartonType{{Name: "a", Capacity: 5}, {Name: "b", Capacity: 7}, {Name: "c", Capacity: 2}}
+
+	tests := []struct {
+		qty       int
+		wantCount int
+		wantCap   int
+	}{
+		{1, 1, 2},
+		{6, 1, 7},
+		{10, 2, 10},
+		{13, 2, 14},
+		{15, 3, 15},
+		{16, 3, 16},
+	}
+
+	for _, tt := range tests {
+		chosen := chooseCartons(types, tt.qty)
+		var capacity int
+		for _, ct := range chosen {
+			capacity += ct.Capacity
+		}
+		if len(chosen) != tt.wantCount || capacity != tt.wantCap {
+			t.Errorf("qty %d: got %d cartons of capacity %d, want %d of %d", tt.qty, len(chosen), capacity, tt.wantCount, tt.wantCap)
+		}
+	}
+}
+
+func TestPackagingValidation(t *testing.T) {
+	invalid := []Packaging{
+		{Cartons: []CartonType{{PackSize: 250, Capacity: 10}}},
+		{Cartons: []CartonType{{Name: "box", PackSize: 250}}},
+		{PalletCapacity: -1},
+	}
+
+	for _, p := range invalid {
+		if _, err := PlanPackaging(map[int]int{250: 1}, p); !errors.Is(err, ErrInvalidPackaging) {
+			t.Errorf("%+v: expected ErrInvalidPackaging, got %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package calculator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Constraint names reported when a single pack exceeds a shipment limit
+const (
+	ConstraintShipmentMaxItems  = "shipment_max_items"
+	ConstraintShipmentMaxWeight = "shipment_max_weight"
+)
+
+// ShipmentLimit caps a single shipment, e.g. a parcel or truck. Zero fields
+// do not limit, but at least one must be set.
+type ShipmentLimit struct {
+	MaxItems  int     `json:"max_items,omitempty"`
+	MaxPacks  int     `json:"max_packs,omitempty"`
+	MaxWeight float64 `json:"max_weight_kg,omitempty"`
+}
+
+func (l ShipmentLimit) Validate() error {
+	if l.MaxItems < 0 || l.MaxPacks < 0 || l.MaxWeight < 0 {
+		return fmt.Errorf("%w: shipment limits must not be negative", ErrInvalidConstraint)
+	}
+	if l.MaxItems == 0 && l.MaxPacks == 0 && l.MaxWeight == 0 {
+		return fmt.Errorf("%w: a shipment limit needs max_items, max_packs or max_weight_kg", ErrInvalidConstraint)
+	}
+	return nil
+}
+
+// Shipment is one consignment of a split order.
+type Shipment struct {
+	Packs       map[int]int `json:"packs"`
+	TotalItems  int         `json:"total_items"`
+	TotalPacks  int         `json:"total_packs"`
+	TotalWeight float64     `json:"total_weight_kg,omitempty"`
+}
+
+// PlanShipments calculates the optimal packs for amount and splits them into
+// shipments within limit.
+func (c *Calculator) PlanShipments(amount int, limit ShipmentLimit) (*CalculationResult, []Shipment, error) {
+	if err := limit.Validate(); err != nil {
+		return nil, nil, err
+	}
+	result, err := c.CalculateWithDetails(amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	shipments, err := c.SplitShipments(result, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, shipments, nil
+}
+
+// SplitShipments spreads the packs of a result over the fewest shipments
+// within limit. Starting from the lower bound the totals impose, each count
+// is first tried by placing packs largest first into the least loaded
+// shipment they fit (worst-fit decreasing), which keeps loads even, and then
+// by an exact search, until one fits or reaches the count of first-fit
+// decreasing. The search is exact within shipmentSearchBudget steps;
+// beyond it the split is heuristic and may use more shipments than
+// necessary. Packs are never opened, so a pack larger than the
+// limit is reported as an *InfeasibleError. Results of more than
+// MaxShipmentPacks packs, or needing more shipments, are rejected with
+// ErrInvalidConstraint.
+func (c *Calculator) SplitShipments(result *CalculationResult, limit ShipmentLimit) ([]Shipment, error) {
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+
+	if result.TotalPacks == 0 {
+		// a backorder may ship nothing
+		return []Shipment{}, nil
+	}
+	if result.TotalPacks > MaxShipmentPacks {
+		return nil, fmt.Errorf("%w: %d packs exceed the %d a split takes", ErrInvalidConstraint, result.TotalPacks, MaxShipmentPacks)
+	}
+
+	for size := range result.Packs {
+		if limit.MaxItems > 0 && size > limit.MaxItems {
+			return nil, &InfeasibleError{Constraint: ConstraintShipmentMaxItems, Size: size, Limit: limit.MaxItems, Amount: result.OrderAmount}
+		}
+		if w := c.packs[size].Weight; limit.MaxWeight > 0 && w > limit.MaxWeight+weightTolerance {
+			return nil, &InfeasibleError{Constraint: ConstraintShipmentMaxWeight, Size: size, MaxWeight: limit.MaxWeight, Amount: result.OrderAmount}
+		}
+	}
+
+	s := splitter{limit: limit, weights: make(map[int]float64, len(result.Packs))}
+	for size := range result.Packs {
+		s.sizes = append(s.sizes, size)
+		s.weights[size] = c.packs[size].Weight
+	}
+	// the pack using the largest share of a shipment goes first
+	sort.Slice(s.sizes, func(i, j int) bool {
+		a, b := s.load(s.sizes[i], s.weights[s.sizes[i]], 1), s.load(s.sizes[j], s.weights[s.sizes[j]], 1)
+		if a != b {
+			return a > b
+		}
+		return s.sizes[i] > s.sizes[j]
+	})
+
+	lower := 1
+	if limit.MaxItems > 0 {
+		lower = max(lower, ceilDiv(result.TotalItems, limit.MaxItems))
+	}
+	if limit.MaxPacks > 0 {
+		lower = max(lower, ceilDiv(result.TotalPacks, limit.MaxPacks))
+	}
+	if limit.MaxWeight > 0 {
+		lower = max(lower, int(math.Ceil(result.TotalWeight/limit.MaxWeight-weightTolerance)))
+	}
+	// no two packs over half of a limit share a shipment
+	var halfItems, halfWeight int
+	for size, qty := range result.Packs {
+		if limit.MaxItems > 0 && 2*size > limit.MaxItems {
+			halfItems += qty
+		}
+		if limit.MaxWeight > 0 && 2*s.weights[size] > limit.MaxWeight+weightTolerance {
+			halfWeight += qty
+		}
+	}
+	lower = max(lower, halfItems, halfWeight)
+	if lower > MaxShipmentPacks {
+		return nil, fmt.Errorf("%w: at least %d shipments exceed the %d a split takes", ErrInvalidConstraint, lower, MaxShipmentPacks)
+	}
+
+	if shipments, ok := s.place(result.Packs, lower); ok {
+		return shipments, nil
+	}
+	upper := s.firstFit(result.Packs)
+	budget := shipmentSearchBudget
+	n := lower
+	for ; n < len(upper) && budget > 0; n++ {
+		if shipments, ok := s.place(result.Packs, n); ok {
+			return shipments, nil
+		}
+		if shipments, ok := s.search(result.Packs, n, &budget); ok {
+			return shipments, nil
+		}
+	}
+
+	// out of budget: the fewest shipments worst-fit decreasing manages,
+	// found by bisection, or first-fit decreasing
+	best, hi := upper, len(upper)
+	for n < hi {
+		mid := (n + hi) / 2
+		if shipments, ok := s.place(result.Packs, mid); ok {
+			best, hi = shipments, mid
+		} else {
+			n = mid + 1
+		}
+	}
+	return best, nil
+}
+
+// MaxShipmentPacks caps the packs and the shipments of a split
+const MaxShipmentPacks = 10_000
+
+// shipmentSearchBudget bounds the work of the exact search over all shipment
+// counts of a split, in shipments examined
+const shipmentSearchBudget = 1_000_000
+
+// splitter places packs into a fixed number of shipments
+type splitter struct {
+	limit   ShipmentLimit
+	sizes   []int // placement order
+	weights map[int]float64
+}
+
+// load is the largest share of any limit that items, weight and packs use
+func (s *splitter) load(items int, weight float64, packs int) float64 {
+	var share float64
+	if s.limit.MaxItems > 0 {
+		share = max(share, float64(items)/float64(s.limit.MaxItems))
+	}
+	if s.limit.MaxPacks > 0 {
+		share = max(share, float64(packs)/float64(s.limit.MaxPacks))
+	}
+	if s.limit.MaxWeight > 0 {
+		share = max(share, weight/s.limit.MaxWeight)
+	}
+	return share
+}
+
+func (s *splitter) fits(sh *Shipment, size int) bool {
+	l := s.limit
+	return (l.MaxItems == 0 || sh.TotalItems+size <= l.MaxItems) &&
+		(l.MaxPacks == 0 || sh.TotalPacks+1 <= l.MaxPacks) &&
+		(l.MaxWeight == 0 || sh.TotalWeight+s.weights[size] <= l.MaxWeight+weightTolerance)
+}
+
+// room is how many packs of size fit the shipment, at most n
+func (s *splitter) room(sh *Shipment, size, n int) int {
+	l := s.limit
+	if l.MaxItems > 0 {
+		n = min(n, (l.MaxItems-sh.TotalItems)/size)
+	}
+	if l.MaxPacks > 0 {
+		n = min(n, l.MaxPacks-sh.TotalPacks)
+	}
+	if w := s.weights[size]; l.MaxWeight > 0 && w > 0 {
+		n = min(n, int((l.MaxWeight+weightTolerance-sh.TotalWeight)/w))
+	}
+	return max(n, 0)
+}
+
+func (s *splitter) add(sh *Shipment, size int) {
+	s.addN(sh, size, 1)
+}
+
+// addN adds n packs of size to the shipment
+func (s *splitter) addN(sh *Shipment, size, n int) {
+	sh.Packs[size] += n
+	sh.TotalItems += n * size
+	sh.TotalPacks += n
+	sh.TotalWeight += float64(n) * s.weights[size]
+}
+
+func (s *splitter) remove(sh *Shipment, size int) {
+	if sh.Packs[size]--; sh.Packs[size] == 0 {
+		delete(sh.Packs, size)
+	}
+	sh.TotalItems -= size
+	sh.TotalPacks--
+	sh.TotalWeight -= s.weights[size]
+}
+
+func newShipments(n int) []Shipment {
+	shipments := make([]Shipment, n)
+	for i := range shipments {
+		shipments[i].Packs = make(map[int]int)
+	}
+	return shipments
+}
+
+func roundWeights(shipments []Shipment) []Shipment {
+	for i := range shipments {
+		shipments[i].TotalWeight = math.Round(shipments[i].TotalWeight*1e6) / 1e6
+	}
+	return shipments
+}
+
+// place is worst-fit decreasing into n shipments. The packs of one size are
+// placed in bulk: the shipments they fit take an even share each, least
+// loaded first and rounded up, until all are placed or none fits.
+func (s *splitter) place(packs map[int]int, n int) ([]Shipment, bool) {
+	shipments := newShipments(n)
+	order := make([]int, 0, n)
+	loads := make([]float64, n)
+
+	for _, size := range s.sizes {
+		q := packs[size]
+		for q > 0 {
+			order = order[:0]
+			for i := range shipments {
+				sh := &shipments[i]
+				if s.fits(sh, size) {
+					order = append(order, i)
+					loads[i] = s.load(sh.TotalItems, sh.TotalWeight, sh.TotalPacks)
+				}
+			}
+			if len(order) == 0 {
+				return nil, false
+			}
+			sort.SliceStable(order, func(a, b int) bool { return loads[order[a]] < loads[order[b]] })
+
+			for k, i := range order {
+				if q == 0 {
+					break
+				}
+				sh := &shipments[i]
+				m := s.room(sh, size, ceilDiv(q, len(order)-k))
+				s.addN(sh, size, m)
+				q -= m
+			}
+		}
+	}
+	return roundWeights(shipments), true
+}
+
+// firstFit places packs largest first into the first shipment they fit,
+// opening one when none does. It always succeeds and bounds the count.
+func (s *splitter) firstFit(packs map[int]int) []Shipment {
+	var shipments []Shipment
+	for _, size := range s.sizes {
+		// shipments only fill up, so one that did not fit a pack of this
+		// size never will
+		i := 0
+		for q := packs[size]; q > 0; {
+			for i < len(shipments) && !s.fits(&shipments[i], size) {
+				i++
+			}
+			if i == len(shipments) {
+				shipments = append(shipments, newShipments(1)...)
+			}
+			m := s.room(&shipments[i], size, q)
+			s.addN(&shipments[i], size, m)
+			q -= m
+		}
+	}
+	return roundWeights(shipments)
+}
+
+// search looks for a placement into n shipments by backtracking, spending n
+// units of budget per placement it considers. Only the totals of shipments
+// decide what fits, so shipments with the same totals are tried once per
+// pack and a set of totals that failed is not searched again. Packs of one
+// size go to shipments in non-decreasing order, and a branch is cut when the
+// packs left exceed the room left.
+func (s *splitter) search(packs map[int]int, n int, budget *int) ([]Shipment, bool) {
+	var order []int // the packs in placement order
+	var total shipmentLoad
+	for _, size := range s.sizes {
+		for q := 0; q < packs[size]; q++ {
+			order = append(order, size)
+			total.items += size
+			total.packs++
+			total.weight += s.weights[size]
+		}
+	}
+
+	shipments := newShipments(n)
+	failed := make(map[string]bool)
+	l := s.limit
+	var try func(k, from int, left shipmentLoad) bool
+	try = func(k, from int, left shipmentLoad) bool {
+		if k == len(order) {
+			return true
+		}
+		if *budget -= n; *budget < 0 {
+			return false
+		}
+
+		// what is left must fit the room of shipments that take one more pack
+		var room shipmentLoad
+		for i := range shipments {
+			sh := &shipments[i]
+			if s.fitsAny(sh, order[k:]) {
+				room.items += l.MaxItems - sh.TotalItems
+				room.packs += l.MaxPacks - sh.TotalPacks
+				room.weight += l.MaxWeight - sh.TotalWeight
+			}
+		}
+		if (l.MaxItems > 0 && left.items > room.items) ||
+			(l.MaxPacks > 0 && left.packs > room.packs) ||
+			(l.MaxWeight > 0 && left.weight > room.weight+weightTolerance) {
+			return false
+		}
+		key := loadsKey(k, shipments)
+		if failed[key] {
+			return false
+		}
+
+		size := order[k]
+		if k > 0 && order[k-1] != size {
+			from = 0
+		}
+		tried := make(map[shipmentLoad]bool)
+		for i := from; i < len(shipments); i++ {
+			sh := &shipments[i]
+			key := shipmentLoad{sh.TotalItems, sh.TotalPacks, sh.TotalWeight}
+			if tried[key] || !s.fits(sh, size) {
+				continue
+			}
+			tried[key] = true
+
+			s.add(sh, size)
+			if try(k+1, i, shipmentLoad{left.items - size, left.packs - 1, left.weight - s.weights[size]}) {
+				return true
+			}
+			s.remove(sh, size)
+		}
+		if *budget >= 0 {
+			failed[key] = true
+		}
+		return false
+	}
+
+	if !try(0, 0, total) {
+		return nil, false
+	}
+	return roundWeights(shipments), true
+}
+
+// fitsAny reports whether a pack of any size in order, sorted as s.sizes,
+// fits the shipment
+func (s *splitter) fitsAny(sh *Shipment, order []int) bool {
+	for i, size := range order {
+		if (i == 0 || order[i-1] != size) && s.fits(sh, size) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadsKey identifies the sorted totals of shipments after k placements
+func loadsKey(k int, shipments []Shipment) string {
+	loads := make([]shipmentLoad, len(shipments))
+	for i, sh := range shipments {
+		loads[i] = shipmentLoad{sh.TotalItems, sh.TotalPacks, math.Round(sh.TotalWeight * 1e6)}
+	}
+	sort.Slice(loads, func(i, j int) bool {
+		a, b := loads[i], loads[j]
+		if a.items != b.items {
+			return a.items < b.items
+		}
+		if a.packs != b.packs {
+			return a.packs < b.packs
+		}
+		return a.weight < b.weight
+	})
+
+	key := binary.AppendUvarint(make([]byte, 0, 8+len(loads)*12), uint64(k))
+	for _, load := range loads {
+		key = binary.AppendUvarint(key, uint64(load.items))
+		key = binary.AppendUvarint(key, uint64(load.packs))
+		key = binary.AppendUvarint(key, uint64(int64(load.weight)))
+	}
+	return string(key)
+}
+
+// shipmentLoad is the totals of a shipment or of packs to place
+type shipmentLoad struct {
+	items  int
+	packs  int
+	weight float64
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
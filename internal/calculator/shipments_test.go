@@ -0,0 +1,186 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlanShipments(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000, 2000, 5000})
+
+	tests := []struct {
+		name  string
+		limit ShipmentLimit
+		want  []map[int]int
+	}{
+		{"max items", ShipmentLimit{MaxItems: 5000}, []map[int]int{{5000: 1}, {5000: 1}, {2000: 1, 250: 1}}},
+		{"max packs", ShipmentLimit{MaxPacks: 2}, []map[int]int{{5000: 1, 2000: 1}, {5000: 1, 250: 1}}},
+		{"loose limit", ShipmentLimit{MaxItems: 20000}, []map[int]int{{5000: 2, 2000: 1, 250: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, shipments, err := calc.PlanShipments(12001, tt.limit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.TotalItems != 12250 {
+				t.Errorf("expected the optimal result, got %+v", result)
+			}
+			if len(shipments) != len(tt.want) {
+				t.Fatalf("got %d shipments %+v, want %d", len(shipments), shipments, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !equalPacks(shipments[i].Packs, want) {
+					t.Errorf("shipment %d: got %v, want %v", i, shipments[i].Packs, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanShipmentsBalancesWeight(t *testing.T) {
+	calc, _ := NewWithPacks([]Pack{{Size: 250, Weight: 1.5}})
+
+	_, shipments, err := calc.PlanShipments(1000, ShipmentLimit{MaxWeight: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(shipments) != 2 || shipments[0].TotalWeight != 3 || shipments[1].TotalWeight != 3 {
+		t.Errorf("expected two shipments of 3 kg, got %+v", shipments)
+	}
+}
+
+func TestSplitShipmentsStaysWithinLimits(t *testing.T) {
+	calc, _ := NewWithPacks([]Pack{{Size: 23, Weight: 0.4}, {Size: 31, Weight: 0.5}, {Size: 53, Weight: 0.9}})
+	limits := []ShipmentLimit{
+		{MaxItems: 100},
+		{MaxPacks: 3},
+		{MaxWeight: 2.5},
+		{MaxItems: 120, MaxPacks: 4, MaxWeight: 2},
+	}
+
+	for _, limit := range limits {
+		for amount := 1; amount <= 2000; amount += 37 {
+			result, shipments, err := calc.PlanShipments(amount, limit)
+			if err != nil {
+				t.Fatalf("%+v amount %d: unexpected error: %v", limit, amount, err)
+			}
+
+			total := make(map[int]int)
+			for _, s := range shipments {
+				if (limit.MaxItems > 0 && s.TotalItems > limit.MaxItems) ||
+					(limit.MaxPacks > 0 && s.TotalPacks > limit.MaxPacks) ||
+					(limit.MaxWeight > 0 && s.TotalWeight > limit.MaxWeight+weightTolerance) {
+					t.Errorf("%+v amount %d: shipment over the limit: %+v", limit, amount, s)
+				}
+				for size, qty := range s.Packs {
+					total[size] += qty
+				}
+			}
+			if !equalPacks(total, result.Packs) {
+				t.Errorf("%+v amount %d: shipments hold %v, result has %v", limit, amount, total, result.Packs)
+			}
+		}
+	}
+}
+
+func TestSplitShipmentsIsMinimal(t *testing.T) {
+	calc, _ := New([]int{3, 4, 6})
+
+	// worst-fit decreasing needs 3 shipments for both
+	for _, tt := range []struct{ amount, maxItems int }{{23, 12}, {35, 18}} {
+		_, shipments, err := calc.PlanShipments(tt.amount, ShipmentLimit{MaxItems: tt.maxItems})
+		if err != nil || len(shipments) != 2 {
+			t.Errorf("%d in shipments of %d: expected 2 shipments, got %+v %v", tt.amount, tt.maxItems, shipments, err)
+		}
+	}
+
+	for amount := 1; amount <= 60; amount++ {
+		for maxItems := 6; maxItems <= 20; maxItems++ {
+			result, shipments, _ := calc.PlanShipments(amount, ShipmentLimit{MaxItems: maxItems})
+			if want := fewestShipments([3]int{result.Packs[6], result.Packs[4], result.Packs[3]}, maxItems, map[[3]int]int{}); len(shipments) != want {
+				t.Fatalf("%d in shipments of %d: got %d shipments, want %d", amount, maxItems, len(shipments), want)
+			}
+		}
+	}
+}
+
+// fewestShipments counts the shipments of packs of 6, 4 and 3 by trying every
+// content of the first shipment
+func fewestShipments(counts [3]int, maxItems int, memo map[[3]int]int) int {
+	if counts == [3]int{} {
+		return 0
+	}
+	if n, ok := memo[counts]; ok {
+		return n
+	}
+	best := -1
+	for a := 0; a <= counts[0]; a++ {
+		for b := 0; b <= counts[1]; b++ {
+			for c := 0; c <= counts[2]; c++ {
+				if a+b+c == 0 || 6*a+4*b+3*c > maxItems {
+					continue
+				}
+				if n := 1 + fewestShipments([3]int{counts[0] - a, counts[1] - b, counts[2] - c}, maxItems, memo); best == -1 || n < best {
+					best = n
+				}
+			}
+		}
+	}
+	memo[counts] = best
+	return best
+}
+
+func TestSplitShipmentsErrors(t *testing.T) {
+	calc, _ := NewWithPacks([]Pack{{Size: 250, Weight: 3}, {Size: 5000, Weight: 40}})
+
+	_, _, err := calc.PlanShipments(6000, ShipmentLimit{MaxItems: 1000})
+	var infeasible *InfeasibleError
+	if !errors.As(err, &infeasible) || infeasible.Constraint != ConstraintShipmentMaxItems || infeasible.Size != 5000 {
+		t.Errorf("expected the 5000 pack to exceed the limit, got %v", err)
+	}
+
+	_, _, err = calc.PlanShipments(250, ShipmentLimit{MaxWeight: 2})
+	if !errors.As(err, &infeasible) || infeasible.Constraint != ConstraintShipmentMaxWeight {
+		t.Errorf("expected the weight limit to be reported, got %v", err)
+	}
+
+	for _, limit := range []ShipmentLimit{{}, {MaxItems: -1}} {
+		if _, _, err := calc.PlanShipments(250, limit); !errors.Is(err, ErrInvalidConstraint) {
+			t.Errorf("%+v: expected ErrInvalidConstraint, got %v", limit, err)
+		}
+	}
+}
+
+func TestSplitShipmentsCapsThePacks(t *testing.T) {
+	calc, _ := New([]int{1})
+
+	if _, _, err := calc.PlanShipments(MaxShipmentPacks+1, ShipmentLimit{MaxItems: 1}); !errors.Is(err, ErrInvalidConstraint) {
+		t.Errorf("expected too many packs to be rejected, got %v", err)
+	}
+	if _, _, err := calc.PlanShipments(200000, ShipmentLimit{MaxItems: 1}); !errors.Is(err, ErrInvalidConstraint) {
+		t.Errorf("expected too many packs to be rejected, got %v", err)
+	}
+
+	// identical packs are placed in bulk, evenly
+	_, shipments, err := calc.PlanShipments(MaxShipmentPacks, ShipmentLimit{MaxItems: 3})
+	if err != nil || len(shipments) != 3334 {
+		t.Fatalf("expected 3334 shipments, got %d %v", len(shipments), err)
+	}
+	for i, sh := range shipments {
+		if sh.TotalItems < 2 || sh.TotalItems > 3 {
+			t.Fatalf("shipment %d: got %d items", i, sh.TotalItems)
+		}
+	}
+}
+
+func TestSplitShipmentsOfNothing(t *testing.T) {
+	calc, _ := New([]int{250})
+
+	result, _ := calc.CalculateFulfilment(100, Constraints{}, Fulfilment{Mode: ModeBackorder, Inventory: map[int]int{250: 0}})
+	shipments, err := calc.SplitShipments(result, ShipmentLimit{MaxPacks: 1})
+	if err != nil || len(shipments) != 0 {
+		t.Errorf("expected no shipments, got %+v %v", shipments, err)
+	}
+}
@@ -0,0 +1,284 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Solver names
+const (
+	// SolverAuto picks one of the others with ChooseSolver.
+	SolverAuto = "auto"
+	// SolverDP fills a table of the fewest packs for every total up to the
+	// amount plus the largest pack. It is the default.
+	SolverDP = "dp"
+	// SolverMemoryBounded removes packs of the largest size that every
+	// optimal combination contains and runs the DP on the rest, so its table
+	// does not grow with the amount.
+	SolverMemoryBounded = "memory_bounded"
+	// SolverBranchAndBound searches the quantities of the smaller sizes, for
+	// catalogues of a few sizes.
+	SolverBranchAndBound = "branch_and_bound"
+	// SolverGreedy takes the largest packs that fit and tops up with the
+	// smallest. It is fast but not optimal, a baseline for the others.
+	SolverGreedy = "greedy"
+)
+
+var (
+	ErrUnknownSolver = errors.New("unknown solver")
+	// ErrSolverNotApplicable rejects a solver selected for a constrained or
+	// non-default fulfilment calculation, which no solver runs.
+	ErrSolverNotApplicable = errors.New("solvers only apply to unconstrained minimal_overship calculations")
+)
+
+// Solver finds the packs of an unconstrained order. Every solver except
+// greedy returns the smallest total at or above the amount, then the fewest
+// packs; where several combinations tie, solvers may pick different ones.
+type Solver interface {
+	Name() string
+	// Solve is called with sizes sorted descending and amount > 0.
+	Solve(sizes []int, amount int) map[int]int
+}
+
+// Limits of the auto heuristic
+const (
+	branchAndBoundMaxSizes  = 5
+	branchAndBoundMaxLeaves = 100000
+	memoryBoundedMinAmount  = 1 << 20
+)
+
+// NewSolver returns the solver with the given name. SolverAuto has no solver
+// of its own and is resolved by ChooseSolver per order.
+func NewSolver(name string) (Solver, error) {
+	switch name {
+	case SolverDP:
+		return dpSolver{}, nil
+	case SolverMemoryBounded:
+		return memoryBoundedSolver{}, nil
+	case SolverBranchAndBound:
+		return branchAndBoundSolver{}, nil
+	case SolverGreedy:
+		return greedySolver{}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownSolver, name)
+}
+
+// ChooseSolver picks an exact solver for an order: branch and bound when the
+// catalogue has few sizes and a small search, memory bounded for large
+// amounts, otherwise the DP. sizes must be sorted descending.
+func ChooseSolver(sizes []int, amount int) Solver {
+	small := smallerSizes(sizes)
+	switch {
+	case len(small)+1 <= branchAndBoundMaxSizes && searchLeaves(sizes[0], small) <= branchAndBoundMaxLeaves:
+		return branchAndBoundSolver{}
+	case amount >= memoryBoundedMinAmount:
+		return memoryBoundedSolver{}
+	}
+	return dpSolver{}
+}
+
+// SetSolver selects the solver of Calculate by name; "" restores the DP.
+// Solvers only apply to unconstrained, minimal overship calculations; the
+// others fail with ErrSolverNotApplicable while one is selected.
+func (c *Calculator) SetSolver(name string) error {
+	if name != "" && name != SolverAuto {
+		if _, err := NewSolver(name); err != nil {
+			return err
+		}
+	}
+	c.solver = name
+	return nil
+}
+
+// solverFor returns the solver Calculate uses for amount. Branch and bound
+// gives way to ChooseSolver when its search is too large to be bounded.
+func (c *Calculator) solverFor(amount int) Solver {
+	switch c.solver {
+	case "":
+		return dpSolver{}
+	case SolverAuto:
+		return ChooseSolver(c.packSizes, amount)
+	case SolverBranchAndBound:
+		if searchLeaves(c.packSizes[0], smallerSizes(c.packSizes)) > branchAndBoundMaxLeaves {
+			return ChooseSolver(c.packSizes, amount)
+		}
+	}
+	s, _ := NewSolver(c.solver)
+	return s
+}
+
+type dpSolver struct{}
+
+func (dpSolver) Name() string { return SolverDP }
+
+func (dpSolver) Solve(sizes []int, amount int) map[int]int {
+	t := newTable(sizes, amount)
+
+	// find smallest total >= amount
+	target := -1
+	for i := amount; i <= t.maxTarget; i++ {
+		if t.dp[i] != impossible {
+			target = i
+			break
+		}
+	}
+
+	if target == -1 {
+		// Fallback: shouldnt happen with valid pack sizes
+		smallestPack := sizes[len(sizes)-1]
+		packsNeeded := (amount + smallestPack - 1) / smallestPack
+		return map[int]int{smallestPack: packsNeeded}
+	}
+
+	return t.backtrack(target)
+}
+
+type memoryBoundedSolver struct{}
+
+func (memoryBoundedSolver) Name() string { return SolverMemoryBounded }
+
+// Solve relies on an exchange argument: largest/g packs of a smaller size s,
+// with g = gcd(s, largest), hold as many items as s/g packs of the largest,
+// so an optimal combination never has more than largest/g - 1 of them. Those
+// hold at most bound items, and the rest of any total above bound is packs of
+// the largest. Removing q such packs leaves an order below bound + largest,
+// whose optimal combination plus the q packs is optimal for the amount.
+func (memoryBoundedSolver) Solve(sizes []int, amount int) map[int]int {
+	largest := sizes[0]
+	bound := memoryBound(sizes)
+
+	q := 0
+	if amount-largest > bound {
+		q = (amount - bound) / largest
+	}
+	packs := dpSolver{}.Solve(sizes, amount-q*largest)
+	if q > 0 {
+		packs[largest] += q
+	}
+	return packs
+}
+
+// memoryBound is the most items an optimal combination holds outside packs
+// of the largest size, math.MaxInt when that overflows, which removes none.
+func memoryBound(sizes []int) int {
+	largest := sizes[0]
+	bound := 0
+	for _, s := range smallerSizes(sizes) {
+		n := largest/gcd(s, largest) - 1
+		if n > (math.MaxInt-bound)/s {
+			return math.MaxInt
+		}
+		bound += n * s
+	}
+	return bound
+}
+
+type branchAndBoundSolver struct{}
+
+func (branchAndBoundSolver) Name() string { return SolverBranchAndBound }
+
+// Solve enumerates the quantities of the smaller sizes, each below
+// largest/gcd(size, largest) as in the memory bounded solver and never more
+// than the order still needs, and covers the rest with the largest packs.
+// Branches whose total or packs cannot beat the best found are cut.
+func (branchAndBoundSolver) Solve(sizes []int, amount int) map[int]int {
+	largest := sizes[0]
+	small := smallerSizes(sizes)
+	counts := make([]int, len(small))
+
+	bestItems, bestPacks := math.MaxInt, math.MaxInt
+	best := make([]int, len(small)+1) // quantities of small, then of largest
+
+	var search func(i, items, packs int)
+	search = func(i, items, packs int) {
+		remaining := max(0, amount-items)
+		// every leaf below reaches at least amount with the remaining items
+		// in packs no smaller than the largest
+		lowItems, lowPacks := max(items, amount), packs+ceilDiv(remaining, largest)
+		if lowItems > bestItems || (lowItems == bestItems && lowPacks >= bestPacks) {
+			return
+		}
+
+		if i == len(small) {
+			n := ceilDiv(remaining, largest)
+			items, packs = items+n*largest, packs+n
+			if items < bestItems || (items == bestItems && packs < bestPacks) {
+				bestItems, bestPacks = items, packs
+				copy(best, counts)
+				best[len(small)] = n
+			}
+			return
+		}
+
+		s := small[i]
+		most := min(largest/gcd(s, largest)-1, ceilDiv(remaining, s))
+		for k := most; k >= 0; k-- {
+			counts[i] = k
+			search(i+1, items+k*s, packs+k)
+		}
+		counts[i] = 0
+	}
+	search(0, 0, 0)
+
+	packs := make(map[int]int)
+	for i, s := range small {
+		if best[i] > 0 {
+			packs[s] = best[i]
+		}
+	}
+	if n := best[len(small)]; n > 0 {
+		packs[largest] = n
+	}
+	return packs
+}
+
+type greedySolver struct{}
+
+func (greedySolver) Name() string { return SolverGreedy }
+
+func (greedySolver) Solve(sizes []int, amount int) map[int]int {
+	packs := make(map[int]int)
+	remaining := amount
+	for _, s := range sizes {
+		if n := remaining / s; n > 0 {
+			packs[s] += n
+			remaining -= n * s
+		}
+	}
+	// what is left is smaller than every pack
+	if remaining > 0 {
+		packs[sizes[len(sizes)-1]]++
+	}
+	return packs
+}
+
+// smallerSizes returns the distinct sizes below the largest, descending
+func smallerSizes(sizes []int) []int {
+	var small []int
+	for _, s := range sizes {
+		if s < sizes[0] && (len(small) == 0 || s < small[len(small)-1]) {
+			small = append(small, s)
+		}
+	}
+	return small
+}
+
+// searchLeaves bounds the combinations branch and bound may visit
+func searchLeaves(largest int, small []int) int {
+	leaves := 1
+	for _, s := range small {
+		leaves *= largest / gcd(s, largest)
+		if leaves > branchAndBoundMaxLeaves {
+			return leaves
+		}
+	}
+	return leaves
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
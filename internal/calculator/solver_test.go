@@ -0,0 +1,150 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+var exactSolvers = []string{SolverDP, SolverMemoryBounded, SolverBranchAndBound}
+
+func totals(packs map[int]int) (items, count int) {
+	for size, n := range packs {
+		items += size * n
+		count += n
+	}
+	return items, count
+}
+
+// crossValidate checks that every exact solver matches the DP's total and
+// pack count for amount, and that greedy covers it without beating the DP
+func crossValidate(t *testing.T, sizes []int, amount int) {
+	t.Helper()
+	sorted := slices.Clone(sizes)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+
+	wantItems, wantCount := totals(dpSolver{}.Solve(sorted, amount))
+	for _, name := range exactSolvers[1:] {
+		s, _ := NewSolver(name)
+		if items, count := totals(s.Solve(sorted, amount)); items != wantItems || count != wantCount {
+			t.Fatalf("%s with %v for %d: got %d items in %d packs, dp %d in %d", name, sizes, amount, items, count, wantItems, wantCount)
+		}
+	}
+
+	items, count := totals(greedySolver{}.Solve(sorted, amount))
+	if items < amount || items < wantItems || (items == wantItems && count < wantCount) {
+		t.Fatalf("greedy with %v for %d: got %d items in %d packs, dp %d in %d", sizes, amount, items, count, wantItems, wantCount)
+	}
+}
+
+func TestSolversAgree(t *testing.T) {
+	catalogues := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{23, 31, 53},
+		{3, 5},
+		{7},
+		{6, 9, 20},
+		{100, 100, 40},
+	}
+	for _, sizes := range catalogues {
+		for amount := 1; amount <= 2000; amount++ {
+			crossValidate(t, sizes, amount)
+		}
+		crossValidate(t, sizes, 500000)
+	}
+}
+
+func TestSolversAgreeRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 300; i++ {
+		sizes := make([]int, 1+rng.IntN(4))
+		for j := range sizes {
+			sizes[j] = 1 + rng.IntN(120)
+		}
+		crossValidate(t, sizes, 1+rng.IntN(20000))
+	}
+}
+
+func TestGreedyIsNotOptimal(t *testing.T) {
+	packs := greedySolver{}.Solve([]int{5000, 2000, 1000, 500, 250}, 251)
+	if items, _ := totals(packs); items != 500 || packs[250] != 2 {
+		t.Errorf("expected 2x250 from greedy, got %v", packs)
+	}
+}
+
+func TestMemoryBoundedEdgeCase(t *testing.T) {
+	packs := memoryBoundedSolver{}.Solve([]int{53, 31, 23}, 500000)
+	if packs[23] != 2 || packs[31] != 7 || packs[53] != 9429 {
+		t.Errorf("expected {23:2 31:7 53:9429}, got %v", packs)
+	}
+}
+
+func TestMemoryBound(t *testing.T) {
+	if got := memoryBound([]int{53, 31, 23}); got != 52*31+52*23 {
+		t.Errorf("expected 2808, got %d", got)
+	}
+	// (2^40 + 1 - 1) * 2^30 does not fit an int
+	if got := memoryBound([]int{1<<40 + 1, 1 << 30}); got != math.MaxInt {
+		t.Errorf("expected an overflowing bound to saturate, got %d", got)
+	}
+}
+
+func TestChooseSolver(t *testing.T) {
+	tests := []struct {
+		sizes  []int
+		amount int
+		want   string
+	}{
+		{[]int{5000, 2000, 1000, 500, 250}, 12001, SolverBranchAndBound},
+		{[]int{53, 31, 23}, 500000, SolverBranchAndBound},
+		{[]int{5000, 4999, 4998, 4997, 250}, 12001, SolverDP},
+		{[]int{5000, 4999, 4998, 4997, 250}, 50000000, SolverMemoryBounded},
+	}
+	for _, tt := range tests {
+		if got := ChooseSolver(tt.sizes, tt.amount).Name(); got != tt.want {
+			t.Errorf("%v for %d: expected %s, got %s", tt.sizes, tt.amount, tt.want, got)
+		}
+	}
+}
+
+func TestSetSolver(t *testing.T) {
+	calc, _ := New([]int{250, 500, 1000, 2000, 5000})
+
+	result, _ := calc.CalculateWithDetails(251)
+	if result.Solver != "" {
+		t.Errorf("expected no solver reported by default, got %q", result.Solver)
+	}
+
+	if err := calc.SetSolver("simplex"); !errors.Is(err, ErrUnknownSolver) {
+		t.Errorf("expected ErrUnknownSolver, got %v", err)
+	}
+
+	calc.SetSolver(SolverAuto)
+	result, _ = calc.CalculateWithDetails(251)
+	if result.Solver != SolverBranchAndBound || result.TotalItems != 500 || result.TotalPacks != 1 {
+		t.Errorf("expected 1x500 from branch_and_bound, got %+v", result)
+	}
+
+	calc.SetPackSizes([]int{5000, 4999, 4998, 4997, 250})
+	calc.SetSolver(SolverBranchAndBound)
+	if result, _ = calc.CalculateWithDetails(251); result.Solver != SolverDP {
+		t.Errorf("expected a large branch_and_bound search to fall back to dp, got %s", result.Solver)
+	}
+
+	calc.SetPackSizes([]int{250, 500, 1000, 2000, 5000})
+	calc.SetSolver(SolverGreedy)
+	result, _ = calc.CalculateWithDetails(251)
+	if result.Solver != SolverGreedy || result.TotalPacks != 2 {
+		t.Errorf("expected 2 packs from greedy, got %+v", result)
+	}
+
+	if _, err := calc.CalculateConstrained(251, Constraints{MaxTotalPacks: 3}); !errors.Is(err, ErrSolverNotApplicable) {
+		t.Errorf("expected ErrSolverNotApplicable with constraints, got %v", err)
+	}
+	if _, err := calc.CalculateFulfilment(251, Constraints{}, Fulfilment{Mode: ModeExact}); !errors.Is(err, ErrSolverNotApplicable) {
+		t.Errorf("expected ErrSolverNotApplicable with another mode, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+// Package demand records ordered amounts into a DemandStore off the request
+// path, so recording never slows a calculation down.
+package demand
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+type Config struct {
+	Buffer        int           // samples queued before Record drops; default 4096
+	FlushInterval time.Duration // how often queued samples are stored; default 1s
+}
+
+func (c Config) withDefaults() Config {
+	if c.Buffer <= 0 {
+		c.Buffer = 4096
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+	return c
+}
+
+// Collector queues amounts and stores them in batches from one background
+// goroutine. Record never blocks: when the queue is full the sample is
+// dropped and counted instead.
+type Collector struct {
+	store   storage.DemandStore
+	cfg     Config
+	samples chan storage.DemandSample
+	flushes chan chan struct{}
+	dropped atomic.Int64
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	now       func() time.Time
+}
+
+func NewCollector(store storage.DemandStore, cfg Config) *Collector {
+	cfg = cfg.withDefaults()
+	c := &Collector{
+		store:   store,
+		cfg:     cfg,
+		samples: make(chan storage.DemandSample, cfg.Buffer),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		now:     time.Now,
+	}
+	c.wg.Add(1)
+	go c.run()
+	return c
+}
+
+func (c *Collector) Store() storage.DemandStore {
+	return c.store
+}
+
+// Record queues an ordered amount. It is safe for concurrent use.
+func (c *Collector) Record(amount int) {
+	select {
+	case <-c.done:
+		c.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case c.samples <- storage.DemandSample{At: c.now().UTC(), Amount: amount}:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// Dropped is the number of samples lost to a full queue.
+func (c *Collector) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// Flush waits until every sample recorded before the call is stored.
+func (c *Collector) Flush() {
+	reply := make(chan struct{})
+	select {
+	case c.flushes <- reply:
+		<-reply
+	case <-c.done:
+	}
+}
+
+// Close stores the queued samples and stops the collector. Samples recorded
+// afterwards are dropped.
+func (c *Collector) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.wg.Wait()
+	})
+}
+
+func (c *Collector) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]storage.DemandSample, 0, c.cfg.Buffer)
+	store := func() {
+		if len(batch) > 0 {
+			c.store.AddDemand(batch)
+			batch = batch[:0]
+		}
+	}
+	// drain takes what is queued now without waiting for more
+	drain := func() {
+		for n := len(c.samples); n > 0; n-- {
+			batch = append(batch, <-c.samples)
+		}
+	}
+
+	for {
+		select {
+		case sample := <-c.samples:
+			batch = append(batch, sample)
+			if len(batch) == cap(batch) {
+				store()
+			}
+		case <-ticker.C:
+			store()
+		case reply := <-c.flushes:
+			drain()
+			store()
+			close(reply)
+		case <-c.done:
+			drain()
+			store()
+			return
+		}
+	}
+}
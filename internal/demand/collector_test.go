@@ -0,0 +1,120 @@
+package demand
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func orders(store storage.DemandStore) map[int]int {
+	total := make(map[int]int)
+	for _, b := range store.ListDemand(time.Time{}, time.Time{}) {
+		for amount, n := range b.Amounts {
+			total[amount] += n
+		}
+	}
+	return total
+}
+
+func TestCollectorConcurrentRecord(t *testing.T) {
+	store := storage.NewMemoryDemandStore(time.Hour, 0)
+	c := NewCollector(store, Config{Buffer: 10000, FlushInterval: time.Hour})
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				c.Record(250 + i%2)
+			}
+		}()
+	}
+	wg.Wait()
+	c.Flush()
+
+	got := orders(store)
+	if got[250] != 2000 || got[251] != 2000 || c.Dropped() != 0 {
+		t.Errorf("expected 2000 orders of each amount, got %v with %d dropped", got, c.Dropped())
+	}
+}
+
+func TestCollectorFlushesOnInterval(t *testing.T) {
+	store := storage.NewMemoryDemandStore(time.Hour, 0)
+	c := NewCollector(store, Config{FlushInterval: 10 * time.Millisecond})
+	defer c.Close()
+
+	c.Record(500)
+	deadline := time.Now().Add(time.Second)
+	for orders(store)[500] != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the sample to be stored without a flush")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// blockingStore holds AddDemand until released, so the queue fills up
+type blockingStore struct {
+	*storage.MemoryDemandStore
+	release chan struct{}
+}
+
+func (s *blockingStore) AddDemand(samples []storage.DemandSample) {
+	<-s.release
+	s.MemoryDemandStore.AddDemand(samples)
+}
+
+func TestCollectorDropsWhenFull(t *testing.T) {
+	store := &blockingStore{storage.NewMemoryDemandStore(time.Hour, 0), make(chan struct{})}
+	c := NewCollector(store, Config{Buffer: 2, FlushInterval: time.Hour})
+
+	// the first two fill the batch and block the collector in AddDemand,
+	// the next two fill the queue
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			c.Record(i + 1)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Record blocked")
+	}
+
+	close(store.release)
+	c.Close()
+	if c.Dropped() == 0 {
+		t.Error("expected samples to be dropped")
+	}
+	stored := 0
+	for _, n := range orders(store) {
+		stored += n
+	}
+	if stored+int(c.Dropped()) != 10 {
+		t.Errorf("expected every sample stored or dropped, got %d stored and %d dropped", stored, c.Dropped())
+	}
+}
+
+func TestCollectorClose(t *testing.T) {
+	store := storage.NewMemoryDemandStore(time.Hour, 0)
+	c := NewCollector(store, Config{FlushInterval: time.Hour})
+
+	c.Record(1000)
+	c.Close()
+	c.Close()
+	if orders(store)[1000] != 1 {
+		t.Error("expected Close to store queued samples")
+	}
+
+	c.Record(2000)
+	c.Flush()
+	if orders(store)[2000] != 0 || c.Dropped() != 1 {
+		t.Errorf("expected samples after Close to be dropped, %d dropped", c.Dropped())
+	}
+}
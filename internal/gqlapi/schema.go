@@ -0,0 +1,478 @@
+// Package gqlapi exposes pack sizes, calculations and change history as a
+// GraphQL schema backed by the storage and calculator packages.
+package gqlapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// maxAlternatives bounds the alternatives a single query can request
+const maxAlternatives = 10
+
+var (
+	ErrPackSizeExists   = errors.New("pack size already exists")
+	ErrPackSizeNotFound = errors.New("pack size not found")
+)
+
+// Resolver holds the dependencies of the schema. Authorize and Actor are
+// optional; when nil every operation is allowed and changes are anonymous.
+// Stores, when set, picks the request's tenant stores instead of Storage and
+// History.
+//
+// Calculate, when set, runs the calculate field instead of the plain
+// calculator, so the host can resolve profiles, charge, audit and publish
+// calculations as it does elsewhere. Charge, when set, is called with the
+// calculator and amount before alternatives are computed. Both are called once per resolved
+// field, so aliases are charged like separate requests.
+type Resolver struct {
+	Storage   storage.Storage
+	History   storage.HistoryStore
+	Stores    func(ctx context.Context) storage.TenantStores
+	Authorize func(ctx context.Context, scope auth.Scope) error
+	Actor     func(ctx context.Context) string
+	Calculate func(ctx context.Context, in CalculateInput) (*calculator.CalculationResult, *calculator.Calculator, error)
+	Charge    func(ctx context.Context, calc *calculator.Calculator, amount int) error
+}
+
+// CalculateInput holds the arguments of the calculate field.
+type CalculateInput struct {
+	Amount      int
+	PackSizes   []int
+	Profile     string
+	Solver      string
+	Constraints calculator.Constraints
+}
+
+// calculation is the source value of the Calculation type. The calculator is
+// kept so alternatives are only computed when the field is selected.
+type calculation struct {
+	result    *calculator.CalculationResult
+	calc      *calculator.Calculator
+	packSizes []int
+}
+
+type packCount struct {
+	Size     int `json:"size"`
+	Quantity int `json:"quantity"`
+}
+
+// Error carries a stable code in the GraphQL error extensions.
+type Error struct {
+	Code string
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// NewSchema builds the GraphQL schema.
+func NewSchema(r *Resolver) (graphql.Schema, error) {
+	packCountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PackCount",
+		Fields: graphql.Fields{
+			"size":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"quantity": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	packLimitType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "PackLimit",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"size":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"quantity": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	constraintsType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "Constraints",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"maxTotalPacks": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"maxPacks":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(packLimitType))},
+			"minPacks":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(packLimitType))},
+			"maxWeightKg":   &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		},
+	})
+
+	// Fields are a thunk because alternatives refers back to Calculation
+	var calculationType *graphql.Object
+	calculationType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Calculation",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := calculationFields(packCountType)
+			fields["alternatives"] = &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(calculationType))),
+				Description: "Combinations that ship more items but use fewer packs",
+				Args: graphql.FieldConfigArgument{
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 3},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					src := p.Source.(*calculation)
+					limit := p.Args["limit"].(int)
+					if limit < 0 || limit > maxAlternatives {
+						limit = maxAlternatives
+					}
+					if r.Charge != nil {
+						if err := r.Charge(p.Context, src.calc, src.result.OrderAmount); err != nil {
+							return nil, err
+						}
+					}
+
+					alts, err := src.calc.Alternatives(src.result.OrderAmount, limit)
+					if err != nil {
+						return nil, calculatorError(err)
+					}
+
+					out := make([]*calculation, len(alts))
+					for i, alt := range alts {
+						out[i] = &calculation{result: alt, calc: src.calc, packSizes: src.packSizes}
+					}
+					return out, nil
+				},
+			}
+			return fields
+		}),
+	})
+
+	changeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Change",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: changeField(func(c storage.Change) interface{} { return int(c.ID) })},
+			"action":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: changeField(func(c storage.Change) interface{} { return c.Action })},
+			"size":      &graphql.Field{Type: graphql.Int, Resolve: changeField(func(c storage.Change) interface{} { return nilIfZero(c.Size) })},
+			"packSizes": &graphql.Field{Type: intList(), Resolve: changeField(func(c storage.Change) interface{} { return c.PackSizes })},
+			"profile":   &graphql.Field{Type: graphql.String, Resolve: changeField(func(c storage.Change) interface{} { return nilIfEmpty(c.Profile) })},
+			"actor":     &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: changeField(func(c storage.Change) interface{} { return c.Actor })},
+			"timestamp": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: changeField(func(c storage.Change) interface{} { return c.Timestamp.UTC() })},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"packSizes": &graphql.Field{
+				Type: intList(),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := r.authorize(p.Context, auth.ScopePackSizesRead); err != nil {
+						return nil, err
+					}
+					return r.storage(p.Context).GetPackSizes(), nil
+				},
+			},
+			"calculate": &graphql.Field{
+				Type: graphql.NewNonNull(calculationType),
+				Args: graphql.FieldConfigArgument{
+					"amount":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"packSizes":   &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+					"profile":     &graphql.ArgumentConfig{Type: graphql.String},
+					"solver":      &graphql.ArgumentConfig{Type: graphql.String},
+					"constraints": &graphql.ArgumentConfig{Type: constraintsType},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := r.authorize(p.Context, auth.ScopeCalculate); err != nil {
+						return nil, err
+					}
+
+					in := CalculateInput{
+						Amount:      p.Args["amount"].(int),
+						PackSizes:   intsArg(p.Args["packSizes"]),
+						Constraints: constraintsArg(p.Args["constraints"]),
+					}
+					in.Profile, _ = p.Args["profile"].(string)
+					in.Solver, _ = p.Args["solver"].(string)
+
+					result, calc, err := r.calculate(p.Context, in)
+					if err != nil {
+						return nil, err
+					}
+					return &calculation{result: result, calc: calc, packSizes: calc.GetPackSizes()}, nil
+				},
+			},
+			"history": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(changeType))),
+				Description: "Pack size changes, newest first",
+				Args: graphql.FieldConfigArgument{
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := r.authorize(p.Context, auth.ScopePackSizesRead); err != nil {
+						return nil, err
+					}
+					history := r.history(p.Context)
+					if history == nil {
+						return []storage.Change{}, nil
+					}
+
+					changes := history.History()
+					for i, j := 0, len(changes)-1; i < j; i, j = i+1, j-1 {
+						changes[i], changes[j] = changes[j], changes[i]
+					}
+					if limit, ok := p.Args["limit"].(int); ok && limit >= 0 && limit < len(changes) {
+						changes = changes[:limit]
+					}
+					return changes, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"setPackSizes": &graphql.Field{
+				Type: intList(),
+				Args: graphql.FieldConfigArgument{
+					"packSizes": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := r.authorize(p.Context, auth.ScopePackSizesWrite); err != nil {
+						return nil, err
+					}
+
+					sizes := intsArg(p.Args["packSizes"])
+					if len(sizes) == 0 {
+						return nil, calculatorError(calculator.ErrNoPackSizes)
+					}
+					for _, size := range sizes {
+						if size <= 0 {
+							return nil, calculatorError(calculator.ErrInvalidPackSize)
+						}
+					}
+
+					if err := r.storage(p.Context).SetPackSizes(sizes); err != nil {
+						return nil, err
+					}
+					return r.changed(p.Context, storage.ChangeSet, 0), nil
+				},
+			},
+			"addPackSize": &graphql.Field{
+				Type: intList(),
+				Args: graphql.FieldConfigArgument{
+					"size": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := r.authorize(p.Context, auth.ScopePackSizesWrite); err != nil {
+						return nil, err
+					}
+
+					size := p.Args["size"].(int)
+					if size <= 0 {
+						return nil, calculatorError(calculator.ErrInvalidPackSize)
+					}
+					if !r.storage(p.Context).AddPackSize(size) {
+						return nil, &Error{Code: "already_exists", Err: ErrPackSizeExists}
+					}
+					return r.changed(p.Context, storage.ChangeAdd, size), nil
+				},
+			},
+			"removePackSize": &graphql.Field{
+				Type: intList(),
+				Args: graphql.FieldConfigArgument{
+					"size": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := r.authorize(p.Context, auth.ScopePackSizesWrite); err != nil {
+						return nil, err
+					}
+
+					size := p.Args["size"].(int)
+					if !r.storage(p.Context).RemovePackSize(size) {
+						return nil, &Error{Code: "not_found", Err: ErrPackSizeNotFound}
+					}
+					return r.changed(p.Context, storage.ChangeRemove, size), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func calculationFields(packCountType *graphql.Object) graphql.Fields {
+	field := func(t graphql.Output, get func(c *calculation) interface{}) *graphql.Field {
+		return &graphql.Field{
+			Type: t,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return get(p.Source.(*calculation)), nil
+			},
+		}
+	}
+	nonNullInt := graphql.NewNonNull(graphql.Int)
+
+	return graphql.Fields{
+		"orderAmount":   field(nonNullInt, func(c *calculation) interface{} { return c.result.OrderAmount }),
+		"totalItems":    field(nonNullInt, func(c *calculation) interface{} { return c.result.TotalItems }),
+		"totalPacks":    field(nonNullInt, func(c *calculation) interface{} { return c.result.TotalPacks }),
+		"overship":      field(nonNullInt, func(c *calculation) interface{} { return c.result.Overship() }),
+		"packSizesUsed": field(intList(), func(c *calculation) interface{} { return c.packSizes }),
+		"totalWeightKg": field(graphql.Float, func(c *calculation) interface{} { return nilIfZeroFloat(c.result.TotalWeight) }),
+		"solver":        field(graphql.String, func(c *calculation) interface{} { return nilIfEmpty(c.result.Solver) }),
+		"explanation":   field(graphql.NewNonNull(graphql.String), func(c *calculation) interface{} { return c.result.Explanation() }),
+		"packs": field(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(packCountType))), func(c *calculation) interface{} {
+			return sortedPacks(c.result.Packs)
+		}),
+	}
+}
+
+func (r *Resolver) authorize(ctx context.Context, scope auth.Scope) error {
+	if r.Authorize == nil {
+		return nil
+	}
+	if err := r.Authorize(ctx, scope); err != nil {
+		return &Error{Code: "forbidden", Err: err}
+	}
+	return nil
+}
+
+func (r *Resolver) storage(ctx context.Context) storage.Storage {
+	if r.Stores != nil {
+		return r.Stores(ctx).Storage
+	}
+	return r.Storage
+}
+
+func (r *Resolver) history(ctx context.Context) storage.HistoryStore {
+	if r.Stores != nil {
+		return r.Stores(ctx).History
+	}
+	return r.History
+}
+
+// changed records the mutation in the history and returns the new list
+func (r *Resolver) changed(ctx context.Context, action string, size int) []int {
+	sizes := r.storage(ctx).GetPackSizes()
+	history := r.history(ctx)
+	if history == nil {
+		return sizes
+	}
+
+	actor := "anonymous"
+	if r.Actor != nil {
+		actor = r.Actor(ctx)
+	}
+	history.RecordChange(storage.Change{
+		Action:    action,
+		Size:      size,
+		PackSizes: sizes,
+		Actor:     actor,
+	})
+	return sizes
+}
+
+// calculate runs the calculate field through the Calculate hook, or the plain
+// calculator with the stored pack sizes when there is none
+func (r *Resolver) calculate(ctx context.Context, in CalculateInput) (*calculator.CalculationResult, *calculator.Calculator, error) {
+	if r.Calculate != nil {
+		return r.Calculate(ctx, in)
+	}
+	if in.Profile != "" {
+		return nil, nil, &Error{Code: calculator.CodeValidationFailed, Err: errors.New("profiles are not supported")}
+	}
+
+	packSizes := in.PackSizes
+	if len(packSizes) == 0 {
+		packSizes = r.storage(ctx).GetPackSizes()
+	}
+	calc, err := calculator.New(packSizes)
+	if err != nil {
+		return nil, nil, calculatorError(err)
+	}
+	if err := calc.SetSolver(in.Solver); err != nil {
+		return nil, nil, calculatorError(err)
+	}
+	result, err := calc.CalculateFulfilment(in.Amount, in.Constraints, calculator.Fulfilment{})
+	if err != nil {
+		return nil, nil, calculatorError(err)
+	}
+	return result, calc, nil
+}
+
+func calculatorError(err error) error {
+	return &Error{Code: calculator.ErrorCode(err), Err: err}
+}
+
+func sortedPacks(packs map[int]int) []packCount {
+	out := make([]packCount, 0, len(packs))
+	for size, qty := range packs {
+		out = append(out, packCount{Size: size, Quantity: qty})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Size > out[j].Size })
+	return out
+}
+
+func changeField(get func(storage.Change) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(storage.Change)), nil
+	}
+}
+
+func intList() graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int)))
+}
+
+func intsArg(v interface{}) []int {
+	list, _ := v.([]interface{})
+	out := make([]int, 0, len(list))
+	for _, item := range list {
+		if n, ok := item.(int); ok {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func constraintsArg(v interface{}) calculator.Constraints {
+	var cons calculator.Constraints
+	m, _ := v.(map[string]interface{})
+	cons.MaxTotalPacks, _ = m["maxTotalPacks"].(int)
+	cons.MaxWeight, _ = m["maxWeightKg"].(float64)
+	cons.MaxPacks = packLimitsArg(m["maxPacks"])
+	cons.MinPacks = packLimitsArg(m["minPacks"])
+	return cons
+}
+
+// packLimitsArg turns a list of PackLimit into quantities per size
+func packLimitsArg(v interface{}) map[int]int {
+	list, _ := v.([]interface{})
+	if len(list) == 0 {
+		return nil
+	}
+	out := make(map[int]int, len(list))
+	for _, item := range list {
+		m, _ := item.(map[string]interface{})
+		size, _ := m["size"].(int)
+		out[size], _ = m["quantity"].(int)
+	}
+	return out
+}
+
+func nilIfZero(n int) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+func nilIfZeroFloat(f float64) interface{} {
+	if f == 0 {
+		return nil
+	}
+	return f
+}
+
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
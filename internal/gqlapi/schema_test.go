@@ -0,0 +1,163 @@
+package gqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func run(t *testing.T, r *Resolver, query string) *graphql.Result {
+	t.Helper()
+
+	schema, err := NewSchema(r)
+	if err != nil {
+		t.Fatalf("failed to build schema: %v", err)
+	}
+	return graphql.Do(graphql.Params{Schema: schema, RequestString: query, Context: context.Background()})
+}
+
+func decode(t *testing.T, result *graphql.Result, v interface{}) {
+	t.Helper()
+
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	data, _ := json.Marshal(result.Data)
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("failed to decode data: %v", err)
+	}
+}
+
+func TestQueryInOneRoundTrip(t *testing.T) {
+	history := storage.NewMemoryHistory(10)
+	history.RecordChange(storage.Change{Action: storage.ChangeAdd, Size: 250, Actor: "alice"})
+	r := &Resolver{Storage: storage.NewMemoryStorage(), History: history}
+
+	result := run(t, r, `{
+		packSizes
+		calculate(amount: 12001) {
+			totalItems
+			totalPacks
+			overship
+			packs { size quantity }
+			explanation
+			alternatives(limit: 2) { totalItems totalPacks }
+		}
+		history { action size actor }
+	}`)
+
+	var data struct {
+		PackSizes []int
+		Calculate struct {
+			TotalItems   int
+			TotalPacks   int
+			Overship     int
+			Packs        []packCount
+			Explanation  string
+			Alternatives []struct{ TotalItems, TotalPacks int }
+		}
+		History []struct {
+			Action string
+			Size   int
+			Actor  string
+		}
+	}
+	decode(t, result, &data)
+
+	if len(data.PackSizes) != 5 {
+		t.Errorf("expected 5 pack sizes, got %v", data.PackSizes)
+	}
+	if data.Calculate.TotalItems != 12250 || data.Calculate.TotalPacks != 4 || data.Calculate.Overship != 249 {
+		t.Errorf("unexpected calculation %+v", data.Calculate)
+	}
+	if len(data.Calculate.Packs) != 3 || data.Calculate.Packs[0] != (packCount{Size: 5000, Quantity: 2}) {
+		t.Errorf("unexpected packs %+v", data.Calculate.Packs)
+	}
+	if data.Calculate.Explanation == "" {
+		t.Error("expected an explanation")
+	}
+	if len(data.Calculate.Alternatives) != 1 || data.Calculate.Alternatives[0].TotalPacks != 3 {
+		t.Errorf("unexpected alternatives %+v", data.Calculate.Alternatives)
+	}
+	if len(data.History) != 1 || data.History[0].Actor != "alice" {
+		t.Errorf("unexpected history %+v", data.History)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	history := storage.NewMemoryHistory(10)
+	r := &Resolver{
+		Storage: store,
+		History: history,
+		Actor:   func(ctx context.Context) string { return "bob" },
+	}
+
+	var data struct{ AddPackSize []int }
+	decode(t, run(t, r, `mutation { addPackSize(size: 750) }`), &data)
+	if len(data.AddPackSize) != 6 {
+		t.Errorf("expected 6 pack sizes, got %v", data.AddPackSize)
+	}
+
+	result := run(t, r, `mutation { addPackSize(size: 750) }`)
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != "already_exists" {
+		t.Errorf("expected already_exists error, got %v", result.Errors)
+	}
+
+	result = run(t, r, `mutation { setPackSizes(packSizes: [10, 0]) }`)
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != "invalid_pack_size" {
+		t.Errorf("expected invalid_pack_size error, got %v", result.Errors)
+	}
+
+	changes := history.History()
+	if len(changes) != 1 || changes[0].Actor != "bob" || changes[0].Size != 750 {
+		t.Errorf("unexpected history %+v", changes)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	r := &Resolver{
+		Storage: storage.NewMemoryStorage(),
+		Authorize: func(ctx context.Context, scope auth.Scope) error {
+			if scope == auth.ScopePackSizesWrite {
+				return errors.New("denied")
+			}
+			return nil
+		},
+	}
+
+	if result := run(t, r, `{ packSizes }`); len(result.Errors) != 0 {
+		t.Errorf("expected read to be allowed, got %v", result.Errors)
+	}
+
+	result := run(t, r, `mutation { removePackSize(size: 250) }`)
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != "forbidden" {
+		t.Errorf("expected forbidden error, got %v", result.Errors)
+	}
+	if len(r.Storage.GetPackSizes()) != 5 {
+		t.Error("forbidden mutation must not change storage")
+	}
+}
+
+func TestStoresOverridesStorage(t *testing.T) {
+	tenant := storage.TenantStores{Storage: storage.NewMemoryStorageWithSizes([]int{3}), History: storage.NewMemoryHistory(10)}
+	shared := storage.NewMemoryStorage()
+	r := &Resolver{
+		Storage: shared,
+		Stores:  func(context.Context) storage.TenantStores { return tenant },
+	}
+
+	var data struct{ AddPackSize []int }
+	decode(t, run(t, r, `mutation { addPackSize(size: 7) }`), &data)
+	if len(data.AddPackSize) != 2 || len(shared.GetPackSizes()) != 5 {
+		t.Errorf("expected the tenant storage to change, got %v", data.AddPackSize)
+	}
+	if len(tenant.History.History()) != 1 {
+		t.Error("expected the change in the tenant history")
+	}
+}
@@ -0,0 +1,210 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	pb "github.com/willianbsanches13/pack-calculator/internal/grpcapi/packcalculatorv1"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// Metadata keys, the gRPC counterparts of the REST headers
+const (
+	apiKeyMetadata        = "x-api-key"
+	authorizationMetadata = "authorization"
+	tenantMetadata        = "x-tenant-id"
+)
+
+// methodScopes are the scopes of the REST routes each method mirrors
+var methodScopes = map[string]auth.Scope{
+	pb.PackCalculatorService_Calculate_FullMethodName:      auth.ScopeCalculate,
+	pb.PackCalculatorService_BatchCalculate_FullMethodName: auth.ScopeCalculate,
+	pb.PackCalculatorService_GetPackSizes_FullMethodName:   auth.ScopePackSizesRead,
+	pb.PackCalculatorService_SetPackSizes_FullMethodName:   auth.ScopePackSizesWrite,
+	pb.PackCalculatorService_AddPackSize_FullMethodName:    auth.ScopePackSizesWrite,
+	pb.PackCalculatorService_RemovePackSize_FullMethodName: auth.ScopePackSizesWrite,
+}
+
+type Option func(*Server)
+
+// WithAPIKeys enables API key authentication with the x-api-key metadata.
+func WithAPIKeys(keys storage.APIKeyStore) Option {
+	return func(s *Server) {
+		s.apiKeys = keys
+	}
+}
+
+// WithJWT enables bearer token authentication with the authorization metadata.
+func WithJWT(v *auth.JWTValidator) Option {
+	return func(s *Server) {
+		s.jwt = v
+	}
+}
+
+// WithRateLimit charges calls against the limiter's buckets, one token per
+// call plus the amount based cost of calculations. Buckets are per method,
+// keyed as the route "GRPC /<full method>" so ratelimit.ParseRoutes can
+// override them.
+func WithRateLimit(l *ratelimit.Limiter) Option {
+	return func(s *Server) {
+		s.limiter = l
+	}
+}
+
+// WithMaxAmount sets the largest amount a calculation may order, by default
+// calculator.DefaultMaxAmount.
+func WithMaxAmount(n int) Option {
+	return func(s *Server) {
+		s.maxAmount = n
+	}
+}
+
+// WithTenants serves each call from its tenant's stores, chosen as over REST
+// from the credentials or the x-tenant-id metadata.
+func WithTenants(registry storage.TenantRegistry) Option {
+	return func(s *Server) {
+		s.tenants = registry
+	}
+}
+
+func (s *Server) authenticator() auth.Authenticator {
+	return auth.Authenticator{APIKeys: s.apiKeys, JWT: s.jwt}
+}
+
+func (s *Server) authEnabled() bool {
+	return s.authenticator().Enabled()
+}
+
+// intercept authenticates calls to the service, resolves their tenant and
+// charges one rate limit token, like the REST middleware. Other services,
+// such as reflection, pass through.
+func (s *Server) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	scope, ok := methodScopes[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if s.authEnabled() {
+		principal, err := s.authenticator().Authenticate(first(md, authorizationMetadata), first(md, apiKeyMetadata))
+		if err != nil {
+			return nil, authStatus(err)
+		}
+		if !principal.HasScope(scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "credentials lack the %s scope", scope)
+		}
+		ctx = auth.WithPrincipal(ctx, principal)
+	}
+
+	if s.tenants != nil {
+		tenant, err := auth.ResolveTenant(ctx, s.tenants, first(md, tenantMetadata))
+		if err != nil {
+			return nil, authStatus(err)
+		}
+		ctx = auth.WithTenant(ctx, tenant)
+	}
+	if err := s.takeTokens(ctx, 1); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStatus converts an auth.Authenticate or auth.ResolveTenant error
+func authStatus(err error) error {
+	code := codes.Unauthenticated
+	switch {
+	case errors.Is(err, auth.ErrInvalidTenantID):
+		code = codes.InvalidArgument
+	case errors.Is(err, auth.ErrTenantMismatch), errors.Is(err, auth.ErrTenantNotBound):
+		code = codes.PermissionDenied
+	case errors.Is(err, auth.ErrUnknownTenant):
+		code = codes.NotFound
+	}
+	return status.Error(code, err.Error())
+}
+
+// stores returns the stores of the call's tenant
+func (s *Server) stores(ctx context.Context) (storage.Storage, storage.HistoryStore) {
+	tenant := auth.TenantFrom(ctx)
+	if s.tenants == nil || tenant == "" {
+		return s.storage, s.history
+	}
+	// resolveTenant only lets registered tenants through
+	stores, _ := s.tenants.Tenant(tenant)
+	return stores.Storage, stores.History
+}
+
+// chargeTables takes the amount based cost of building tables of the given
+// sizes, as calculator.TableSize returns them
+func (s *Server) chargeTables(ctx context.Context, sizes ...int) error {
+	if s.limiter == nil {
+		return nil
+	}
+
+	cost := 0
+	for _, size := range sizes {
+		cost += s.limiter.CalculationCost(size)
+	}
+	if cost == 0 {
+		return nil
+	}
+	return s.takeTokens(ctx, cost)
+}
+
+// takeTokens charges the caller's bucket for the method, reporting the wait
+// in the retry-after header metadata when the call is rejected.
+func (s *Server) takeTokens(ctx context.Context, cost int) error {
+	if s.limiter == nil {
+		return nil
+	}
+
+	method, _ := grpc.Method(ctx)
+	result := s.limiter.Allow(rateLimitClient(ctx), "GRPC "+method, cost)
+	if result.Allowed {
+		return nil
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds())))))
+	if cost > result.Limit {
+		return status.Errorf(codes.ResourceExhausted, "this call costs %d tokens, more than the burst of %d; order fewer items", cost, result.Limit)
+	}
+	return status.Error(codes.ResourceExhausted, "too many requests, retry later")
+}
+
+// rateLimitClient keys buckets like REST: by identity when authenticated,
+// else by address, and per tenant
+func rateLimitClient(ctx context.Context) string {
+	client := "ip:unknown"
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		client = principal.Subject
+	} else if p, ok := peer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		client = "ip:" + host
+	}
+
+	if tenant := auth.TenantFrom(ctx); tenant != "" {
+		return "tenant:" + tenant + "\x00" + client
+	}
+	return client
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
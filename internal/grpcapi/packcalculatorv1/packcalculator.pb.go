@@ -0,0 +1,800 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: packcalculator/v1/packcalculator.proto
+
+package packcalculatorv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CalculateRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Amount int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Overrides the configured pack sizes when not empty.
+	PackSizes     []int64 `protobuf:"varint,2,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateRequest) Reset() {
+	*x = CalculateRequest{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateRequest) ProtoMessage() {}
+
+func (x *CalculateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateRequest.ProtoReflect.Descriptor instead.
+func (*CalculateRequest) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CalculateRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CalculateRequest) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type CalculateResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	OrderAmount int64                  `protobuf:"varint,1,opt,name=order_amount,json=orderAmount,proto3" json:"order_amount,omitempty"`
+	TotalItems  int64                  `protobuf:"varint,2,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	TotalPacks  int64                  `protobuf:"varint,3,opt,name=total_packs,json=totalPacks,proto3" json:"total_packs,omitempty"`
+	// Pack size to quantity.
+	Packs         map[int64]int64 `protobuf:"bytes,4,rep,name=packs,proto3" json:"packs,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	PackSizesUsed []int64         `protobuf:"varint,5,rep,packed,name=pack_sizes_used,json=packSizesUsed,proto3" json:"pack_sizes_used,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateResponse) Reset() {
+	*x = CalculateResponse{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateResponse) ProtoMessage() {}
+
+func (x *CalculateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateResponse.ProtoReflect.Descriptor instead.
+func (*CalculateResponse) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CalculateResponse) GetOrderAmount() int64 {
+	if x != nil {
+		return x.OrderAmount
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetTotalItems() int64 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetTotalPacks() int64 {
+	if x != nil {
+		return x.TotalPacks
+	}
+	return 0
+}
+
+func (x *CalculateResponse) GetPacks() map[int64]int64 {
+	if x != nil {
+		return x.Packs
+	}
+	return nil
+}
+
+func (x *CalculateResponse) GetPackSizesUsed() []int64 {
+	if x != nil {
+		return x.PackSizesUsed
+	}
+	return nil
+}
+
+type BatchCalculateRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Amounts []int64                `protobuf:"varint,1,rep,packed,name=amounts,proto3" json:"amounts,omitempty"`
+	// Overrides the configured pack sizes for every amount when not empty.
+	PackSizes     []int64 `protobuf:"varint,2,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCalculateRequest) Reset() {
+	*x = BatchCalculateRequest{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCalculateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCalculateRequest) ProtoMessage() {}
+
+func (x *BatchCalculateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCalculateRequest.ProtoReflect.Descriptor instead.
+func (*BatchCalculateRequest) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchCalculateRequest) GetAmounts() []int64 {
+	if x != nil {
+		return x.Amounts
+	}
+	return nil
+}
+
+func (x *BatchCalculateRequest) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type BatchCalculateResult struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Amount int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Set when the calculation succeeded.
+	Result *CalculateResponse `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	// Stable error code from the REST catalogue, e.g. "invalid_amount".
+	ErrorCode     string `protobuf:"bytes,3,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorMessage  string `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCalculateResult) Reset() {
+	*x = BatchCalculateResult{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCalculateResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCalculateResult) ProtoMessage() {}
+
+func (x *BatchCalculateResult) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCalculateResult.ProtoReflect.Descriptor instead.
+func (*BatchCalculateResult) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchCalculateResult) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *BatchCalculateResult) GetResult() *CalculateResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchCalculateResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *BatchCalculateResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type BatchCalculateResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Results       []*BatchCalculateResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCalculateResponse) Reset() {
+	*x = BatchCalculateResponse{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCalculateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCalculateResponse) ProtoMessage() {}
+
+func (x *BatchCalculateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCalculateResponse.ProtoReflect.Descriptor instead.
+func (*BatchCalculateResponse) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchCalculateResponse) GetResults() []*BatchCalculateResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetPackSizesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPackSizesRequest) Reset() {
+	*x = GetPackSizesRequest{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPackSizesRequest) ProtoMessage() {}
+
+func (x *GetPackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPackSizesRequest.ProtoReflect.Descriptor instead.
+func (*GetPackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{5}
+}
+
+type GetPackSizesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes     []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPackSizesResponse) Reset() {
+	*x = GetPackSizesResponse{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPackSizesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPackSizesResponse) ProtoMessage() {}
+
+func (x *GetPackSizesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPackSizesResponse.ProtoReflect.Descriptor instead.
+func (*GetPackSizesResponse) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPackSizesResponse) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type SetPackSizesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes     []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPackSizesRequest) Reset() {
+	*x = SetPackSizesRequest{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPackSizesRequest) ProtoMessage() {}
+
+func (x *SetPackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPackSizesRequest.ProtoReflect.Descriptor instead.
+func (*SetPackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetPackSizesRequest) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type SetPackSizesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes     []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPackSizesResponse) Reset() {
+	*x = SetPackSizesResponse{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPackSizesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPackSizesResponse) ProtoMessage() {}
+
+func (x *SetPackSizesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPackSizesResponse.ProtoReflect.Descriptor instead.
+func (*SetPackSizesResponse) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetPackSizesResponse) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type AddPackSizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddPackSizeRequest) Reset() {
+	*x = AddPackSizeRequest{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddPackSizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddPackSizeRequest) ProtoMessage() {}
+
+func (x *AddPackSizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddPackSizeRequest.ProtoReflect.Descriptor instead.
+func (*AddPackSizeRequest) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AddPackSizeRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type AddPackSizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes     []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddPackSizeResponse) Reset() {
+	*x = AddPackSizeResponse{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddPackSizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddPackSizeResponse) ProtoMessage() {}
+
+func (x *AddPackSizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddPackSizeResponse.ProtoReflect.Descriptor instead.
+func (*AddPackSizeResponse) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AddPackSizeResponse) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+type RemovePackSizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemovePackSizeRequest) Reset() {
+	*x = RemovePackSizeRequest{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemovePackSizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemovePackSizeRequest) ProtoMessage() {}
+
+func (x *RemovePackSizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemovePackSizeRequest.ProtoReflect.Descriptor instead.
+func (*RemovePackSizeRequest) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RemovePackSizeRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type RemovePackSizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes     []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemovePackSizeResponse) Reset() {
+	*x = RemovePackSizeResponse{}
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemovePackSizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemovePackSizeResponse) ProtoMessage() {}
+
+func (x *RemovePackSizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packcalculator_v1_packcalculator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemovePackSizeResponse.ProtoReflect.Descriptor instead.
+func (*RemovePackSizeResponse) Descriptor() ([]byte, []int) {
+	return file_packcalculator_v1_packcalculator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RemovePackSizeResponse) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+var File_packcalculator_v1_packcalculator_proto protoreflect.FileDescriptor
+
+const file_packcalculator_v1_packcalculator_proto_rawDesc = "" +
+	"\n" +
+	"&packcalculator/v1/packcalculator.proto\x12\x11packcalculator.v1\"I\n" +
+	"\x10CalculateRequest\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x02 \x03(\x03R\tpackSizes\"\xa1\x02\n" +
+	"\x11CalculateResponse\x12!\n" +
+	"\forder_amount\x18\x01 \x01(\x03R\vorderAmount\x12\x1f\n" +
+	"\vtotal_items\x18\x02 \x01(\x03R\n" +
+	"totalItems\x12\x1f\n" +
+	"\vtotal_packs\x18\x03 \x01(\x03R\n" +
+	"totalPacks\x12E\n" +
+	"\x05packs\x18\x04 \x03(\v2/.packcalculator.v1.CalculateResponse.PacksEntryR\x05packs\x12&\n" +
+	"\x0fpack_sizes_used\x18\x05 \x03(\x03R\rpackSizesUsed\x1a8\n" +
+	"\n" +
+	"PacksEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x03R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"P\n" +
+	"\x15BatchCalculateRequest\x12\x18\n" +
+	"\aamounts\x18\x01 \x03(\x03R\aamounts\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x02 \x03(\x03R\tpackSizes\"\xb0\x01\n" +
+	"\x14BatchCalculateResult\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12<\n" +
+	"\x06result\x18\x02 \x01(\v2$.packcalculator.v1.CalculateResponseR\x06result\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x03 \x01(\tR\terrorCode\x12#\n" +
+	"\rerror_message\x18\x04 \x01(\tR\ferrorMessage\"[\n" +
+	"\x16BatchCalculateResponse\x12A\n" +
+	"\aresults\x18\x01 \x03(\v2'.packcalculator.v1.BatchCalculateResultR\aresults\"\x15\n" +
+	"\x13GetPackSizesRequest\"5\n" +
+	"\x14GetPackSizesResponse\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x01 \x03(\x03R\tpackSizes\"4\n" +
+	"\x13SetPackSizesRequest\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x01 \x03(\x03R\tpackSizes\"5\n" +
+	"\x14SetPackSizesResponse\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x01 \x03(\x03R\tpackSizes\"(\n" +
+	"\x12AddPackSizeRequest\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\"4\n" +
+	"\x13AddPackSizeResponse\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x01 \x03(\x03R\tpackSizes\"+\n" +
+	"\x15RemovePackSizeRequest\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\"7\n" +
+	"\x16RemovePackSizeResponse\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x01 \x03(\x03R\tpackSizes2\xdd\x04\n" +
+	"\x15PackCalculatorService\x12V\n" +
+	"\tCalculate\x12#.packcalculator.v1.CalculateRequest\x1a$.packcalculator.v1.CalculateResponse\x12e\n" +
+	"\x0eBatchCalculate\x12(.packcalculator.v1.BatchCalculateRequest\x1a).packcalculator.v1.BatchCalculateResponse\x12_\n" +
+	"\fGetPackSizes\x12&.packcalculator.v1.GetPackSizesRequest\x1a'.packcalculator.v1.GetPackSizesResponse\x12_\n" +
+	"\fSetPackSizes\x12&.packcalculator.v1.SetPackSizesRequest\x1a'.packcalculator.v1.SetPackSizesResponse\x12\\\n" +
+	"\vAddPackSize\x12%.packcalculator.v1.AddPackSizeRequest\x1a&.packcalculator.v1.AddPackSizeResponse\x12e\n" +
+	"\x0eRemovePackSize\x12(.packcalculator.v1.RemovePackSizeRequest\x1a).packcalculator.v1.RemovePackSizeResponseBaZ_github.com/willianbsanches13/pack-calculator/internal/grpcapi/packcalculatorv1;packcalculatorv1b\x06proto3"
+
+var (
+	file_packcalculator_v1_packcalculator_proto_rawDescOnce sync.Once
+	file_packcalculator_v1_packcalculator_proto_rawDescData []byte
+)
+
+func file_packcalculator_v1_packcalculator_proto_rawDescGZIP() []byte {
+	file_packcalculator_v1_packcalculator_proto_rawDescOnce.Do(func() {
+		file_packcalculator_v1_packcalculator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_packcalculator_v1_packcalculator_proto_rawDesc), len(file_packcalculator_v1_packcalculator_proto_rawDesc)))
+	})
+	return file_packcalculator_v1_packcalculator_proto_rawDescData
+}
+
+var file_packcalculator_v1_packcalculator_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_packcalculator_v1_packcalculator_proto_goTypes = []any{
+	(*CalculateRequest)(nil),       // 0: packcalculator.v1.CalculateRequest
+	(*CalculateResponse)(nil),      // 1: packcalculator.v1.CalculateResponse
+	(*BatchCalculateRequest)(nil),  // 2: packcalculator.v1.BatchCalculateRequest
+	(*BatchCalculateResult)(nil),   // 3: packcalculator.v1.BatchCalculateResult
+	(*BatchCalculateResponse)(nil), // 4: packcalculator.v1.BatchCalculateResponse
+	(*GetPackSizesRequest)(nil),    // 5: packcalculator.v1.GetPackSizesRequest
+	(*GetPackSizesResponse)(nil),   // 6: packcalculator.v1.GetPackSizesResponse
+	(*SetPackSizesRequest)(nil),    // 7: packcalculator.v1.SetPackSizesRequest
+	(*SetPackSizesResponse)(nil),   // 8: packcalculator.v1.SetPackSizesResponse
+	(*AddPackSizeRequest)(nil),     // 9: packcalculator.v1.AddPackSizeRequest
+	(*AddPackSizeResponse)(nil),    // 10: packcalculator.v1.AddPackSizeResponse
+	(*RemovePackSizeRequest)(nil),  // 11: packcalculator.v1.RemovePackSizeRequest
+	(*RemovePackSizeResponse)(nil), // 12: packcalculator.v1.RemovePackSizeResponse
+	nil,                            // 13: packcalculator.v1.CalculateResponse.PacksEntry
+}
+var file_packcalculator_v1_packcalculator_proto_depIdxs = []int32{
+	13, // 0: packcalculator.v1.CalculateResponse.packs:type_name -> packcalculator.v1.CalculateResponse.PacksEntry
+	1,  // 1: packcalculator.v1.BatchCalculateResult.result:type_name -> packcalculator.v1.CalculateResponse
+	3,  // 2: packcalculator.v1.BatchCalculateResponse.results:type_name -> packcalculator.v1.BatchCalculateResult
+	0,  // 3: packcalculator.v1.PackCalculatorService.Calculate:input_type -> packcalculator.v1.CalculateRequest
+	2,  // 4: packcalculator.v1.PackCalculatorService.BatchCalculate:input_type -> packcalculator.v1.BatchCalculateRequest
+	5,  // 5: packcalculator.v1.PackCalculatorService.GetPackSizes:input_type -> packcalculator.v1.GetPackSizesRequest
+	7,  // 6: packcalculator.v1.PackCalculatorService.SetPackSizes:input_type -> packcalculator.v1.SetPackSizesRequest
+	9,  // 7: packcalculator.v1.PackCalculatorService.AddPackSize:input_type -> packcalculator.v1.AddPackSizeRequest
+	11, // 8: packcalculator.v1.PackCalculatorService.RemovePackSize:input_type -> packcalculator.v1.RemovePackSizeRequest
+	1,  // 9: packcalculator.v1.PackCalculatorService.Calculate:output_type -> packcalculator.v1.CalculateResponse
+	4,  // 10: packcalculator.v1.PackCalculatorService.BatchCalculate:output_type -> packcalculator.v1.BatchCalculateResponse
+	6,  // 11: packcalculator.v1.PackCalculatorService.GetPackSizes:output_type -> packcalculator.v1.GetPackSizesResponse
+	8,  // 12: packcalculator.v1.PackCalculatorService.SetPackSizes:output_type -> packcalculator.v1.SetPackSizesResponse
+	10, // 13: packcalculator.v1.PackCalculatorService.AddPackSize:output_type -> packcalculator.v1.AddPackSizeResponse
+	12, // 14: packcalculator.v1.PackCalculatorService.RemovePackSize:output_type -> packcalculator.v1.RemovePackSizeResponse
+	9,  // [9:15] is the sub-list for method output_type
+	3,  // [3:9] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_packcalculator_v1_packcalculator_proto_init() }
+func file_packcalculator_v1_packcalculator_proto_init() {
+	if File_packcalculator_v1_packcalculator_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_packcalculator_v1_packcalculator_proto_rawDesc), len(file_packcalculator_v1_packcalculator_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_packcalculator_v1_packcalculator_proto_goTypes,
+		DependencyIndexes: file_packcalculator_v1_packcalculator_proto_depIdxs,
+		MessageInfos:      file_packcalculator_v1_packcalculator_proto_msgTypes,
+	}.Build()
+	File_packcalculator_v1_packcalculator_proto = out.File
+	file_packcalculator_v1_packcalculator_proto_goTypes = nil
+	file_packcalculator_v1_packcalculator_proto_depIdxs = nil
+}
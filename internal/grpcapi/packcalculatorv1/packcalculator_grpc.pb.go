@@ -0,0 +1,331 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: packcalculator/v1/packcalculator.proto
+
+package packcalculatorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PackCalculatorService_Calculate_FullMethodName      = "/packcalculator.v1.PackCalculatorService/Calculate"
+	PackCalculatorService_BatchCalculate_FullMethodName = "/packcalculator.v1.PackCalculatorService/BatchCalculate"
+	PackCalculatorService_GetPackSizes_FullMethodName   = "/packcalculator.v1.PackCalculatorService/GetPackSizes"
+	PackCalculatorService_SetPackSizes_FullMethodName   = "/packcalculator.v1.PackCalculatorService/SetPackSizes"
+	PackCalculatorService_AddPackSize_FullMethodName    = "/packcalculator.v1.PackCalculatorService/AddPackSize"
+	PackCalculatorService_RemovePackSize_FullMethodName = "/packcalculator.v1.PackCalculatorService/RemovePackSize"
+)
+
+// PackCalculatorServiceClient is the client API for PackCalculatorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PackCalculatorService exposes the same calculator and pack size storage as the REST API.
+type PackCalculatorServiceClient interface {
+	// Calculate is a plain POST /api/calculate. Unlike REST it always uses the
+	// default dynamic programming solver and takes no constraints, fulfilment
+	// mode, profile or idempotency key. It works from pack sizes alone, so the
+	// response has no weights. It writes no audit record, sends no
+	// calculation.completed webhook and does not count towards demand
+	// statistics.
+	Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error)
+	// BatchCalculate is Calculate for several amounts, with the same
+	// differences from REST.
+	BatchCalculate(ctx context.Context, in *BatchCalculateRequest, opts ...grpc.CallOption) (*BatchCalculateResponse, error)
+	GetPackSizes(ctx context.Context, in *GetPackSizesRequest, opts ...grpc.CallOption) (*GetPackSizesResponse, error)
+	SetPackSizes(ctx context.Context, in *SetPackSizesRequest, opts ...grpc.CallOption) (*SetPackSizesResponse, error)
+	AddPackSize(ctx context.Context, in *AddPackSizeRequest, opts ...grpc.CallOption) (*AddPackSizeResponse, error)
+	RemovePackSize(ctx context.Context, in *RemovePackSizeRequest, opts ...grpc.CallOption) (*RemovePackSizeResponse, error)
+}
+
+type packCalculatorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPackCalculatorServiceClient(cc grpc.ClientConnInterface) PackCalculatorServiceClient {
+	return &packCalculatorServiceClient{cc}
+}
+
+func (c *packCalculatorServiceClient) Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*CalculateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CalculateResponse)
+	err := c.cc.Invoke(ctx, PackCalculatorService_Calculate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorServiceClient) BatchCalculate(ctx context.Context, in *BatchCalculateRequest, opts ...grpc.CallOption) (*BatchCalculateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCalculateResponse)
+	err := c.cc.Invoke(ctx, PackCalculatorService_BatchCalculate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorServiceClient) GetPackSizes(ctx context.Context, in *GetPackSizesRequest, opts ...grpc.CallOption) (*GetPackSizesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPackSizesResponse)
+	err := c.cc.Invoke(ctx, PackCalculatorService_GetPackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorServiceClient) SetPackSizes(ctx context.Context, in *SetPackSizesRequest, opts ...grpc.CallOption) (*SetPackSizesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPackSizesResponse)
+	err := c.cc.Invoke(ctx, PackCalculatorService_SetPackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorServiceClient) AddPackSize(ctx context.Context, in *AddPackSizeRequest, opts ...grpc.CallOption) (*AddPackSizeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddPackSizeResponse)
+	err := c.cc.Invoke(ctx, PackCalculatorService_AddPackSize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packCalculatorServiceClient) RemovePackSize(ctx context.Context, in *RemovePackSizeRequest, opts ...grpc.CallOption) (*RemovePackSizeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemovePackSizeResponse)
+	err := c.cc.Invoke(ctx, PackCalculatorService_RemovePackSize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PackCalculatorServiceServer is the server API for PackCalculatorService service.
+// All implementations must embed UnimplementedPackCalculatorServiceServer
+// for forward compatibility.
+//
+// PackCalculatorService exposes the same calculator and pack size storage as the REST API.
+type PackCalculatorServiceServer interface {
+	// Calculate is a plain POST /api/calculate. Unlike REST it always uses the
+	// default dynamic programming solver and takes no constraints, fulfilment
+	// mode, profile or idempotency key. It works from pack sizes alone, so the
+	// response has no weights. It writes no audit record, sends no
+	// calculation.completed webhook and does not count towards demand
+	// statistics.
+	Calculate(context.Context, *CalculateRequest) (*CalculateResponse, error)
+	// BatchCalculate is Calculate for several amounts, with the same
+	// differences from REST.
+	BatchCalculate(context.Context, *BatchCalculateRequest) (*BatchCalculateResponse, error)
+	GetPackSizes(context.Context, *GetPackSizesRequest) (*GetPackSizesResponse, error)
+	SetPackSizes(context.Context, *SetPackSizesRequest) (*SetPackSizesResponse, error)
+	AddPackSize(context.Context, *AddPackSizeRequest) (*AddPackSizeResponse, error)
+	RemovePackSize(context.Context, *RemovePackSizeRequest) (*RemovePackSizeResponse, error)
+	mustEmbedUnimplementedPackCalculatorServiceServer()
+}
+
+// UnimplementedPackCalculatorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPackCalculatorServiceServer struct{}
+
+func (UnimplementedPackCalculatorServiceServer) Calculate(context.Context, *CalculateRequest) (*CalculateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Calculate not implemented")
+}
+func (UnimplementedPackCalculatorServiceServer) BatchCalculate(context.Context, *BatchCalculateRequest) (*BatchCalculateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCalculate not implemented")
+}
+func (UnimplementedPackCalculatorServiceServer) GetPackSizes(context.Context, *GetPackSizesRequest) (*GetPackSizesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPackSizes not implemented")
+}
+func (UnimplementedPackCalculatorServiceServer) SetPackSizes(context.Context, *SetPackSizesRequest) (*SetPackSizesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPackSizes not implemented")
+}
+func (UnimplementedPackCalculatorServiceServer) AddPackSize(context.Context, *AddPackSizeRequest) (*AddPackSizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPackSize not implemented")
+}
+func (UnimplementedPackCalculatorServiceServer) RemovePackSize(context.Context, *RemovePackSizeRequest) (*RemovePackSizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemovePackSize not implemented")
+}
+func (UnimplementedPackCalculatorServiceServer) mustEmbedUnimplementedPackCalculatorServiceServer() {}
+func (UnimplementedPackCalculatorServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafePackCalculatorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PackCalculatorServiceServer will
+// result in compilation errors.
+type UnsafePackCalculatorServiceServer interface {
+	mustEmbedUnimplementedPackCalculatorServiceServer()
+}
+
+func RegisterPackCalculatorServiceServer(s grpc.ServiceRegistrar, srv PackCalculatorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPackCalculatorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PackCalculatorService_ServiceDesc, srv)
+}
+
+func _PackCalculatorService_Calculate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServiceServer).Calculate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculatorService_Calculate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServiceServer).Calculate(ctx, req.(*CalculateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculatorService_BatchCalculate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCalculateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServiceServer).BatchCalculate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculatorService_BatchCalculate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServiceServer).BatchCalculate(ctx, req.(*BatchCalculateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculatorService_GetPackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServiceServer).GetPackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculatorService_GetPackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServiceServer).GetPackSizes(ctx, req.(*GetPackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculatorService_SetPackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServiceServer).SetPackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculatorService_SetPackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServiceServer).SetPackSizes(ctx, req.(*SetPackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculatorService_AddPackSize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddPackSizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServiceServer).AddPackSize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculatorService_AddPackSize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServiceServer).AddPackSize(ctx, req.(*AddPackSizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackCalculatorService_RemovePackSize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemovePackSizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackCalculatorServiceServer).RemovePackSize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackCalculatorService_RemovePackSize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackCalculatorServiceServer).RemovePackSize(ctx, req.(*RemovePackSizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PackCalculatorService_ServiceDesc is the grpc.ServiceDesc for PackCalculatorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PackCalculatorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "packcalculator.v1.PackCalculatorService",
+	HandlerType: (*PackCalculatorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Calculate",
+			Handler:    _PackCalculatorService_Calculate_Handler,
+		},
+		{
+			MethodName: "BatchCalculate",
+			Handler:    _PackCalculatorService_BatchCalculate_Handler,
+		},
+		{
+			MethodName: "GetPackSizes",
+			Handler:    _PackCalculatorService_GetPackSizes_Handler,
+		},
+		{
+			MethodName: "SetPackSizes",
+			Handler:    _PackCalculatorService_SetPackSizes_Handler,
+		},
+		{
+			MethodName: "AddPackSize",
+			Handler:    _PackCalculatorService_AddPackSize_Handler,
+		},
+		{
+			MethodName: "RemovePackSize",
+			Handler:    _PackCalculatorService_RemovePackSize_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "packcalculator/v1/packcalculator.proto",
+}
@@ -0,0 +1,258 @@
+// Package grpcapi serves the pack calculator over gRPC, backed by the same
+// calculator and storage as the HTTP handler.
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	pb "github.com/willianbsanches13/pack-calculator/internal/grpcapi/packcalculatorv1"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// maxBatchSize bounds the work a single BatchCalculate call can request
+const maxBatchSize = 1000
+
+type Server struct {
+	pb.UnimplementedPackCalculatorServiceServer
+
+	storage   storage.Storage
+	history   storage.HistoryStore // may be nil
+	apiKeys   storage.APIKeyStore  // authentication is disabled when this and jwt are nil
+	jwt       *auth.JWTValidator
+	limiter   *ratelimit.Limiter     // may be nil
+	tenants   storage.TenantRegistry // nil serves every call from the stores above
+	maxAmount int
+}
+
+func NewServer(s storage.Storage, history storage.HistoryStore, opts ...Option) *Server {
+	srv := &Server{storage: s, history: history, maxAmount: calculator.DefaultMaxAmount}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	return srv
+}
+
+// NewGRPCServer returns a grpc.Server with the service and reflection
+// registered and the server's interceptor installed.
+func NewGRPCServer(srv *Server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(srv.intercept)}, opts...)
+	gs := grpc.NewServer(opts...)
+	pb.RegisterPackCalculatorServiceServer(gs, srv)
+	reflection.Register(gs)
+	return gs
+}
+
+// Calculate differs from POST /api/calculate as the proto documents: the
+// default solver, no constraints, weights, audit, webhooks or demand.
+func (s *Server) Calculate(ctx context.Context, req *pb.CalculateRequest) (*pb.CalculateResponse, error) {
+	amount := int(req.GetAmount())
+	calc, packSizes, err := s.newCalculator(ctx, req.GetPackSizes())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	size, err := calc.TableSize(amount, calculator.Constraints{})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.chargeTables(ctx, size); err != nil {
+		return nil, err
+	}
+
+	resp, err := calculate(calc, packSizes, amount)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return resp, nil
+}
+
+// BatchCalculate reports per-amount failures in the results instead of failing
+// the call. The amounts are charged up front, so a batch over the rate limit
+// fails as a whole.
+func (s *Server) BatchCalculate(ctx context.Context, req *pb.BatchCalculateRequest) (*pb.BatchCalculateResponse, error) {
+	if len(req.GetAmounts()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d amounts per batch", maxBatchSize)
+	}
+
+	calc, packSizes, err := s.newCalculator(ctx, req.GetPackSizes())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// amounts the calculator rejects are reported, not charged
+	amounts := toInts(req.GetAmounts())
+	checks := make([]error, len(amounts))
+	var sizes []int
+	for i, amount := range amounts {
+		size, err := calc.TableSize(amount, calculator.Constraints{})
+		if err != nil {
+			checks[i] = err
+			continue
+		}
+		sizes = append(sizes, size)
+	}
+	if err := s.chargeTables(ctx, sizes...); err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.BatchCalculateResult, 0, len(amounts))
+	for i, amount := range amounts {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		result := &pb.BatchCalculateResult{Amount: int64(amount)}
+		err := checks[i]
+		var resp *pb.CalculateResponse
+		if err == nil {
+			resp, err = calculate(calc, packSizes, amount)
+		}
+		if err != nil {
+			result.ErrorCode = calculator.ErrorCode(err)
+			result.ErrorMessage = err.Error()
+		} else {
+			result.Result = resp
+		}
+		results = append(results, result)
+	}
+
+	return &pb.BatchCalculateResponse{Results: results}, nil
+}
+
+func (s *Server) GetPackSizes(ctx context.Context, req *pb.GetPackSizesRequest) (*pb.GetPackSizesResponse, error) {
+	store, _ := s.stores(ctx)
+	return &pb.GetPackSizesResponse{PackSizes: toInt64s(store.GetPackSizes())}, nil
+}
+
+func (s *Server) SetPackSizes(ctx context.Context, req *pb.SetPackSizesRequest) (*pb.SetPackSizesResponse, error) {
+	sizes := toInts(req.GetPackSizes())
+	if len(sizes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, calculator.ErrNoPackSizes.Error())
+	}
+	for _, size := range sizes {
+		if size <= 0 {
+			return nil, status.Error(codes.InvalidArgument, calculator.ErrInvalidPackSize.Error())
+		}
+	}
+
+	store, history := s.stores(ctx)
+	if err := store.SetPackSizes(sizes); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	current := store.GetPackSizes()
+	record(ctx, history, storage.ChangeSet, 0, current)
+	return &pb.SetPackSizesResponse{PackSizes: toInt64s(current)}, nil
+}
+
+func (s *Server) AddPackSize(ctx context.Context, req *pb.AddPackSizeRequest) (*pb.AddPackSizeResponse, error) {
+	size := int(req.GetSize())
+	if size <= 0 {
+		return nil, status.Error(codes.InvalidArgument, calculator.ErrInvalidPackSize.Error())
+	}
+
+	store, history := s.stores(ctx)
+	if !store.AddPackSize(size) {
+		return nil, status.Error(codes.AlreadyExists, "pack size already exists")
+	}
+
+	current := store.GetPackSizes()
+	record(ctx, history, storage.ChangeAdd, size, current)
+	return &pb.AddPackSizeResponse{PackSizes: toInt64s(current)}, nil
+}
+
+func (s *Server) RemovePackSize(ctx context.Context, req *pb.RemovePackSizeRequest) (*pb.RemovePackSizeResponse, error) {
+	size := int(req.GetSize())
+	if size <= 0 {
+		return nil, status.Error(codes.InvalidArgument, calculator.ErrInvalidPackSize.Error())
+	}
+
+	store, history := s.stores(ctx)
+	if !store.RemovePackSize(size) {
+		return nil, status.Error(codes.NotFound, "pack size not found")
+	}
+
+	current := store.GetPackSizes()
+	record(ctx, history, storage.ChangeRemove, size, current)
+	return &pb.RemovePackSizeResponse{PackSizes: toInt64s(current)}, nil
+}
+
+// newCalculator builds the calculator of the override, or the stored pack
+// sizes, bounded by the maximum amount
+func (s *Server) newCalculator(ctx context.Context, override []int64) (*calculator.Calculator, []int, error) {
+	packSizes := toInts(override)
+	if len(packSizes) == 0 {
+		store, _ := s.stores(ctx)
+		packSizes = store.GetPackSizes()
+	}
+
+	calc, err := calculator.New(packSizes)
+	if err != nil {
+		return nil, nil, err
+	}
+	calc.SetMaxAmount(s.maxAmount)
+	return calc, packSizes, nil
+}
+
+func record(ctx context.Context, history storage.HistoryStore, action string, size int, sizes []int) {
+	if history == nil {
+		return
+	}
+	history.RecordChange(storage.Change{
+		Action:    action,
+		Size:      size,
+		PackSizes: sizes,
+		Actor:     actor(ctx),
+	})
+}
+
+// actor names the caller in the change history, like the REST handler
+func actor(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		return principal.Subject
+	}
+	return "anonymous"
+}
+
+func calculate(calc *calculator.Calculator, packSizes []int, amount int) (*pb.CalculateResponse, error) {
+	result, err := calc.CalculateWithDetails(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make(map[int64]int64, len(result.Packs))
+	for size, qty := range result.Packs {
+		packs[int64(size)] = int64(qty)
+	}
+
+	return &pb.CalculateResponse{
+		OrderAmount:   int64(result.OrderAmount),
+		TotalItems:    int64(result.TotalItems),
+		TotalPacks:    int64(result.TotalPacks),
+		Packs:         packs,
+		PackSizesUsed: toInt64s(packSizes),
+	}, nil
+}
+
+func toInts(in []int64) []int {
+	out := make([]int, len(in))
+	for i, v := range in {
+		out[i] = int(v)
+	}
+	return out
+}
+
+func toInt64s(in []int) []int64 {
+	out := make([]int64, len(in))
+	for i, v := range in {
+		out[i] = int64(v)
+	}
+	return out
+}
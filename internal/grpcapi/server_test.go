@@ -0,0 +1,285 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	pb "github.com/willianbsanches13/pack-calculator/internal/grpcapi/packcalculatorv1"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func setupTestClient(t *testing.T, opts ...Option) (pb.PackCalculatorServiceClient, *grpc.ClientConn, *storage.MemoryHistory) {
+	t.Helper()
+
+	history := storage.NewMemoryHistory(10)
+	lis := bufconn.Listen(1024 * 1024)
+	gs := NewGRPCServer(NewServer(storage.NewMemoryStorage(), history, opts...))
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewPackCalculatorServiceClient(conn), conn, history
+}
+
+func TestCalculate(t *testing.T) {
+	client, _, _ := setupTestClient(t)
+
+	resp, err := client.Calculate(context.Background(), &pb.CalculateRequest{Amount: 12001})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.TotalItems != 12250 || resp.TotalPacks != 4 {
+		t.Errorf("expected 12250 items in 4 packs, got %d in %d", resp.TotalItems, resp.TotalPacks)
+	}
+	if resp.Packs[5000] != 2 {
+		t.Errorf("expected 2x5000, got %v", resp.Packs)
+	}
+}
+
+func TestCalculateInvalidAmount(t *testing.T) {
+	client, _, _ := setupTestClient(t)
+
+	_, err := client.Calculate(context.Background(), &pb.CalculateRequest{Amount: 0})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestBatchCalculate(t *testing.T) {
+	client, _, _ := setupTestClient(t)
+
+	resp, err := client.BatchCalculate(context.Background(), &pb.BatchCalculateRequest{
+		Amounts:   []int64{100, -1},
+		PackSizes: []int64{23, 31, 53},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(resp.Results))
+	}
+	if resp.Results[0].Result == nil || resp.Results[0].Result.TotalItems < 100 {
+		t.Errorf("unexpected first result %v", resp.Results[0])
+	}
+	if resp.Results[1].ErrorCode != "invalid_amount" {
+		t.Errorf("expected invalid_amount, got %q", resp.Results[1].ErrorCode)
+	}
+}
+
+func TestPackSizeManagement(t *testing.T) {
+	client, _, history := setupTestClient(t)
+	ctx := context.Background()
+
+	if _, err := client.AddPackSize(ctx, &pb.AddPackSizeRequest{Size: 750}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.AddPackSize(ctx, &pb.AddPackSizeRequest{Size: 750}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists, got %v", err)
+	}
+	if _, err := client.RemovePackSize(ctx, &pb.RemovePackSizeRequest{Size: 999}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+
+	set, err := client.SetPackSizes(ctx, &pb.SetPackSizesRequest{PackSizes: []int64{10, 20}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(set.PackSizes) != 2 {
+		t.Errorf("expected 2 pack sizes, got %v", set.PackSizes)
+	}
+	if _, err := client.SetPackSizes(ctx, &pb.SetPackSizesRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+
+	got, _ := client.GetPackSizes(ctx, &pb.GetPackSizesRequest{})
+	if len(got.PackSizes) != 2 {
+		t.Errorf("expected 2 pack sizes, got %v", got.PackSizes)
+	}
+
+	changes := history.History()
+	if len(changes) != 2 || changes[0].Actor != "anonymous" {
+		t.Errorf("expected 2 anonymous changes, got %+v", changes)
+	}
+}
+
+func TestReflection(t *testing.T) {
+	_, conn, _ := setupTestClient(t)
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		if svc.Name == pb.PackCalculatorService_ServiceDesc.ServiceName {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected the service to be listed by reflection")
+	}
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, key)
+}
+
+func TestAuthentication(t *testing.T) {
+	keys := storage.NewMemoryAPIKeyStore()
+	keys.CreateAPIKey(storage.APIKey{ID: "calc", KeyHash: auth.HashKey("pk_calc"), Scopes: []string{string(auth.ScopeCalculate)}})
+	keys.CreateAPIKey(storage.APIKey{ID: "reader", KeyHash: auth.HashKey("pk_reader"), Scopes: []string{string(auth.ScopePackSizesRead)}})
+	client, _, _ := setupTestClient(t, WithAPIKeys(keys))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"no credentials", context.Background(), codes.Unauthenticated},
+		{"unknown key", withKey("pk_nope"), codes.Unauthenticated},
+		{"missing scope", withKey("pk_reader"), codes.PermissionDenied},
+		{"calculate scope", withKey("pk_calc"), codes.OK},
+		{"bearer without JWT", metadata.AppendToOutgoingContext(context.Background(), authorizationMetadata, "Bearer x"), codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Calculate(tt.ctx, &pb.CalculateRequest{Amount: 251})
+			if status.Code(err) != tt.want {
+				t.Errorf("expected %s, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := client.SetPackSizes(withKey("pk_reader"), &pb.SetPackSizesRequest{PackSizes: []int64{1}}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied writing with a read key, got %v", err)
+	}
+}
+
+func TestChangesRecordTheCaller(t *testing.T) {
+	keys := storage.NewMemoryAPIKeyStore()
+	keys.CreateAPIKey(storage.APIKey{ID: "writer", KeyHash: auth.HashKey("pk_writer"), Scopes: []string{string(auth.ScopePackSizesWrite)}})
+	client, _, history := setupTestClient(t, WithAPIKeys(keys))
+
+	if _, err := client.AddPackSize(withKey("pk_writer"), &pb.AddPackSizeRequest{Size: 42}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changes := history.History(); len(changes) != 1 || changes[0].Actor != "apikey:writer" {
+		t.Errorf("expected the change by apikey:writer, got %+v", changes)
+	}
+}
+
+func TestMaxAmount(t *testing.T) {
+	client, _, _ := setupTestClient(t, WithMaxAmount(1000))
+
+	sizes := []int64{250, 500}
+	if _, err := client.Calculate(context.Background(), &pb.CalculateRequest{Amount: 1001, PackSizes: sizes}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+	// the stored sizes include 5000, above the maximum
+	if _, err := client.Calculate(context.Background(), &pb.CalculateRequest{Amount: 1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a pack size above the maximum, got %v", err)
+	}
+
+	resp, err := client.BatchCalculate(context.Background(), &pb.BatchCalculateRequest{Amounts: []int64{1000, 1001}, PackSizes: sizes})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Results[0].Result == nil || resp.Results[1].ErrorCode != "invalid_amount" {
+		t.Errorf("expected only the second amount to be rejected, got %v", resp.Results)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Default:        ratelimit.Limit{Rate: 0.001, Burst: 5},
+		AmountPerToken: 1000,
+	}, nil)
+	client, _, _ := setupTestClient(t, WithRateLimit(limiter))
+	ctx := context.Background()
+
+	// one token per call plus one per 1000 items of the table, the amount
+	// and the largest pack
+	sizes := []int64{250}
+	if _, err := client.Calculate(ctx, &pb.CalculateRequest{Amount: 2500, PackSizes: sizes}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var header metadata.MD
+	_, err := client.Calculate(ctx, &pb.CalculateRequest{Amount: 2500, PackSizes: sizes}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted || len(header.Get("retry-after")) != 1 {
+		t.Errorf("expected ResourceExhausted with retry-after, got %v %v", err, header)
+	}
+
+	// a batch is charged for all of its amounts, on its own bucket
+	_, err = client.BatchCalculate(ctx, &pb.BatchCalculateRequest{Amounts: []int64{2000, 2000, 2000}, PackSizes: sizes})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted for 7 tokens, got %v", err)
+	}
+	if _, err := client.BatchCalculate(ctx, &pb.BatchCalculateRequest{Amounts: []int64{2000, 1000}, PackSizes: sizes}); err != nil {
+		t.Errorf("expected a 4 token batch to pass, got %v", err)
+	}
+}
+
+func TestTenants(t *testing.T) {
+	tenants := storage.NewMemoryTenantRegistry(func(string) storage.TenantStores {
+		return storage.TenantStores{Storage: storage.NewMemoryStorage(), History: storage.NewMemoryHistory(10)}
+	})
+	acme, _ := tenants.CreateTenant("acme")
+	client, _, history := setupTestClient(t, WithTenants(tenants))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tenantMetadata, "acme")
+	if _, err := client.SetPackSizes(ctx, &pb.SetPackSizesRequest{PackSizes: []int64{7}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sizes := acme.Storage.GetPackSizes(); len(sizes) != 1 || len(acme.History.History()) != 1 {
+		t.Errorf("expected the change in acme's stores, got %v", sizes)
+	}
+	if len(history.History()) != 0 {
+		t.Errorf("expected the default history to be untouched, got %+v", history.History())
+	}
+
+	resp, err := client.Calculate(ctx, &pb.CalculateRequest{Amount: 10})
+	if err != nil || resp.TotalItems != 14 {
+		t.Errorf("expected acme's sizes to be used, got %v %v", resp, err)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), tenantMetadata, "initech")
+	if _, err := client.GetPackSizes(ctx, &pb.GetPackSizesRequest{}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unregistered tenant, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+
+	maxRequestIDLength = 128
+
+	defaultCalculationsLimit = 50
+	maxCalculationsLimit     = 500
+)
+
+// CalculationsResponse is one page of the audit log. NextCursor is empty on
+// the last page.
+type CalculationsResponse struct {
+	Calculations []storage.CalculationRecord `json:"calculations"`
+	NextCursor   string                      `json:"next_cursor,omitempty"`
+}
+
+// WithAudit records every REST calculation and enables GET /api/calculations.
+func WithAudit(store storage.AuditStore) Option {
+	return func(h *Handler) {
+		h.audit = store
+	}
+}
+
+// RequestID tags every response with X-Request-ID, keeping a client supplied
+// value when it is reasonable.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID(c)
+		c.Next()
+	}
+}
+
+// requestID returns the request's ID, assigning one if RequestID did not run
+func requestID(c *gin.Context) string {
+	if id := c.GetString(requestIDKey); id != "" {
+		return id
+	}
+
+	id := c.GetHeader(requestIDHeader)
+	if id == "" || len(id) > maxRequestIDLength {
+		id = newRequestID()
+	}
+	c.Set(requestIDKey, id)
+	c.Header(requestIDHeader, id)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// auditCalculation records a successful calculation
+func (h *Handler) auditCalculation(c *gin.Context, resp CalculateResponse) {
+	audit := h.stores(c).Audit
+	if audit == nil {
+		return
+	}
+	audit.RecordCalculation(storage.CalculationRecord{
+		RequestID:   requestID(c),
+		Caller:      actor(c),
+		OrderAmount: resp.OrderAmount,
+		PackSizes:   resp.PackSizes,
+		TotalItems:  resp.TotalItems,
+		TotalPacks:  resp.TotalPacks,
+		Packs:       resp.Packs,
+	})
+}
+
+// ListCalculations serves the audit log, newest first. Filters: from and to
+// (RFC 3339), min_amount, max_amount and caller; pages via limit and cursor.
+func (h *Handler) ListCalculations(c *gin.Context) {
+	filter := storage.CalculationFilter{
+		Caller: c.Query("caller"),
+		Limit:  defaultCalculationsLimit,
+	}
+
+	var fieldErrors []FieldError
+	timeParam := func(name string) time.Time {
+		v := c.Query(name)
+		if v == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Reason: "datetime", Param: time.RFC3339})
+		}
+		return t
+	}
+	intParam := func(name string, fallback, max int) int {
+		v := c.Query(name)
+		if v == "" {
+			return fallback
+		}
+		n, err := parsePositiveInt(v)
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Reason: "gt", Param: "0"})
+		} else if max > 0 && n > max {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Reason: "lte", Param: strconv.Itoa(max)})
+		}
+		return n
+	}
+
+	filter.From = timeParam("from")
+	filter.To = timeParam("to")
+	filter.MinAmount = intParam("min_amount", 0, 0)
+	filter.MaxAmount = intParam("max_amount", 0, 0)
+	filter.Limit = intParam("limit", defaultCalculationsLimit, maxCalculationsLimit)
+	filter.BeforeID = int64(intParam("cursor", 0, 0))
+
+	if len(fieldErrors) > 0 {
+		resp := newProblem(c, CodeValidationFailed, "One or more query parameters are invalid")
+		resp.Errors = fieldErrors
+		writeProblem(c, resp)
+		return
+	}
+
+	records, more := h.stores(c).Audit.ListCalculations(filter)
+	resp := CalculationsResponse{Calculations: records}
+	if more {
+		resp.NextCursor = strconv.FormatInt(records[len(records)-1].ID, 10)
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func setupAuditRouter() (*gin.Engine, *storage.MemoryAuditLog) {
+	gin.SetMode(gin.TestMode)
+	audit := storage.NewMemoryAuditLog(time.Hour, 100)
+
+	r := gin.New()
+	r.Use(RequestID())
+	New(storage.NewMemoryStorage(), WithAudit(audit)).RegisterRoutes(r)
+	return r, audit
+}
+
+func listCalculations(t *testing.T, r *gin.Engine, query string) (int, CalculationsResponse) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/calculations"+query, nil))
+
+	var resp CalculationsResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestCalculationsAreAudited(t *testing.T) {
+	r, _ := setupAuditRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/calculate?amount=251", nil)
+	req.Header.Set(requestIDHeader, "order-42")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Header().Get(requestIDHeader) != "order-42" {
+		t.Errorf("expected client request id to be echoed, got %q", w.Header().Get(requestIDHeader))
+	}
+
+	// failed calculations are not audited
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/calculate?amount=-1", nil))
+
+	code, resp := listCalculations(t, r, "")
+	if code != http.StatusOK || len(resp.Calculations) != 1 {
+		t.Fatalf("expected 1 audited calculation, got %d %+v", code, resp)
+	}
+
+	record := resp.Calculations[0]
+	if record.RequestID != "order-42" || record.Caller != "anonymous" || record.OrderAmount != 251 {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if record.TotalItems != 500 || record.Packs[500] != 1 || len(record.PackSizes) != 5 {
+		t.Errorf("unexpected result %+v", record)
+	}
+	if record.Timestamp.IsZero() {
+		t.Error("expected timestamp")
+	}
+}
+
+func TestListCalculationsFiltersAndPages(t *testing.T) {
+	r, audit := setupAuditRouter()
+	for _, amount := range []int{100, 200, 300, 400} {
+		audit.RecordCalculation(storage.CalculationRecord{OrderAmount: amount, Caller: "alice"})
+	}
+	audit.RecordCalculation(storage.CalculationRecord{OrderAmount: 300, Caller: "bob"})
+
+	_, resp := listCalculations(t, r, "?caller=alice&min_amount=200&limit=2")
+	if len(resp.Calculations) != 2 || resp.Calculations[0].OrderAmount != 400 || resp.NextCursor == "" {
+		t.Fatalf("unexpected first page %+v", resp)
+	}
+
+	_, resp = listCalculations(t, r, "?caller=alice&min_amount=200&limit=2&cursor="+resp.NextCursor)
+	if len(resp.Calculations) != 1 || resp.Calculations[0].OrderAmount != 200 || resp.NextCursor != "" {
+		t.Errorf("unexpected last page %+v", resp)
+	}
+
+	from := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	if _, resp = listCalculations(t, r, "?from="+from); len(resp.Calculations) != 0 {
+		t.Errorf("expected no calculations after %s, got %d", from, len(resp.Calculations))
+	}
+}
+
+func TestListCalculationsValidation(t *testing.T) {
+	r, _ := setupAuditRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/calculations?from=yesterday&limit=1000", nil))
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusBadRequest || resp.Code != CodeValidationFailed || len(resp.Errors) != 2 {
+		t.Errorf("expected 2 field errors, got %d %+v", w.Code, resp)
+	}
+}
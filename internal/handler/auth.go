@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	principalKey = "principal"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	Tenant string   `json:"tenant,omitempty"`
+}
+
+// CreateAPIKeyResponse is the only response that carries the raw key.
+type CreateAPIKeyResponse struct {
+	storage.APIKey
+	Key string `json:"key"`
+}
+
+type APIKeysResponse struct {
+	Keys []storage.APIKey `json:"keys"`
+}
+
+// WithAPIKeys enables API key authentication backed by the given store.
+func WithAPIKeys(keys storage.APIKeyStore) Option {
+	return func(h *Handler) {
+		h.apiKeys = keys
+	}
+}
+
+// WithJWT enables bearer token authentication with the given validator.
+func WithJWT(v *auth.JWTValidator) Option {
+	return func(h *Handler) {
+		h.jwt = v
+	}
+}
+
+func (h *Handler) authenticator() auth.Authenticator {
+	return auth.Authenticator{APIKeys: h.apiKeys, JWT: h.jwt}
+}
+
+func (h *Handler) authEnabled() bool {
+	return h.authenticator().Enabled()
+}
+
+// requireScope rejects requests whose credentials lack the scope.
+// It is a no-op when authentication is not configured.
+func (h *Handler) requireScope(scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.authEnabled() {
+			if h.resolveTenant(c) {
+				c.Next()
+			}
+			return
+		}
+
+		principal, ok := h.authenticate(c)
+		if !ok {
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			problem(c, CodeForbidden, "Credentials lack the "+string(scope)+" scope")
+			return
+		}
+
+		setPrincipal(c, principal)
+		if h.resolveTenant(c) {
+			c.Next()
+		}
+	}
+}
+
+// authenticated rejects anonymous callers but leaves scope checks to the
+// route, for endpoints such as GraphQL that serve several scopes.
+func (h *Handler) authenticated() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.authEnabled() {
+			if h.resolveTenant(c) {
+				c.Next()
+			}
+			return
+		}
+
+		principal, ok := h.authenticate(c)
+		if !ok {
+			return
+		}
+
+		setPrincipal(c, principal)
+		if h.resolveTenant(c) {
+			c.Next()
+		}
+	}
+}
+
+func setPrincipal(c *gin.Context, principal auth.Principal) {
+	c.Set(principalKey, principal)
+	c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+}
+
+// authenticate returns false after writing a problem when the caller is
+// rejected
+func (h *Handler) authenticate(c *gin.Context) (auth.Principal, bool) {
+	principal, err := h.authenticator().Authenticate(c.GetHeader("Authorization"), c.GetHeader(apiKeyHeader))
+	if err != nil {
+		authProblem(c, err)
+		return auth.Principal{}, false
+	}
+	return principal, true
+}
+
+// authProblem writes the problem of an auth.Authenticate or
+// auth.ResolveTenant error
+func authProblem(c *gin.Context, err error) {
+	code := CodeUnauthorized
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken):
+		code = CodeInvalidToken
+	case errors.Is(err, auth.ErrInvalidAPIKey):
+		code = CodeInvalidAPIKey
+	case errors.Is(err, auth.ErrInvalidTenantID):
+		code = CodeValidationFailed
+	case errors.Is(err, auth.ErrTenantMismatch), errors.Is(err, auth.ErrTenantNotBound):
+		code = CodeForbidden
+	case errors.Is(err, auth.ErrUnknownTenant):
+		code = CodeNotFound
+	}
+	detail := err.Error()
+	problem(c, code, strings.ToUpper(detail[:1])+detail[1:])
+}
+
+// actor names the caller for audit records
+func actor(c *gin.Context) string {
+	if v, ok := c.Get(principalKey); ok {
+		return v.(auth.Principal).Subject
+	}
+	return "anonymous"
+}
+
+// ListAPIKeys lists every key, or only the tenant's keys for tenant-bound callers.
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys := h.apiKeys.ListAPIKeys()
+	if tenant := boundTenant(c); tenant != "" {
+		owned := []storage.APIKey{}
+		for _, key := range keys {
+			if key.Tenant == tenant {
+				owned = append(owned, key)
+			}
+		}
+		keys = owned
+	}
+	c.JSON(http.StatusOK, APIKeysResponse{Keys: keys})
+}
+
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	for _, s := range req.Scopes {
+		if _, err := auth.ParseScope(s); err != nil {
+			problem(c, CodeInvalidScope, "Unknown scope: "+s)
+			return
+		}
+	}
+
+	if req.Tenant != "" && !storage.ValidTenantID(req.Tenant) {
+		problem(c, CodeValidationFailed, "Tenant ID must be 1-64 lowercase letters, digits, '-' or '_'")
+		return
+	}
+	if req.Tenant != "" && h.tenants != nil {
+		if _, ok := h.tenants.Tenant(req.Tenant); !ok {
+			problem(c, CodeValidationFailed, "Tenant "+req.Tenant+" is not registered")
+			return
+		}
+	}
+	if tenant := boundTenant(c); tenant != "" {
+		if req.Tenant != "" && req.Tenant != tenant {
+			problem(c, CodeForbidden, "Credentials are bound to another tenant")
+			return
+		}
+		req.Tenant = tenant
+	}
+
+	id, raw, err := auth.NewKey()
+	if err != nil {
+		problem(c, CodeKeyGenerationError, err.Error())
+		return
+	}
+
+	key := storage.APIKey{
+		ID:        id,
+		Name:      req.Name,
+		KeyHash:   auth.HashKey(raw),
+		Scopes:    req.Scopes,
+		Tenant:    req.Tenant,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if !h.apiKeys.CreateAPIKey(key) {
+		problem(c, CodeAlreadyExists, "API key already exists")
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: raw})
+}
+
+func (h *Handler) DeleteAPIKey(c *gin.Context) {
+	id := c.Param("id")
+	if tenant := boundTenant(c); tenant != "" && !h.ownsAPIKey(tenant, id) {
+		problem(c, CodeNotFound, "API key not found")
+		return
+	}
+
+	if !h.apiKeys.DeleteAPIKey(id) {
+		problem(c, CodeNotFound, "API key not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ownsAPIKey(tenant, id string) bool {
+	for _, key := range h.apiKeys.ListAPIKeys() {
+		if key.ID == id {
+			return key.Tenant == tenant
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const testAdminKey = "pk_test_admin"
+
+func setupAuthRouter() (*gin.Engine, *storage.MemoryAPIKeyStore) {
+	gin.SetMode(gin.TestMode)
+	keys := storage.NewMemoryAPIKeyStore()
+	keys.CreateAPIKey(storage.APIKey{
+		ID:        "admin",
+		Name:      "admin",
+		KeyHash:   auth.HashKey(testAdminKey),
+		Scopes:    []string{string(auth.ScopeAdmin)},
+		CreatedAt: time.Now(),
+	})
+
+	r := gin.New()
+	h := New(storage.NewMemoryStorage(), WithAPIKeys(keys))
+	h.RegisterRoutes(r)
+	return r, keys
+}
+
+func createKey(t *testing.T, r *gin.Engine, scopes ...string) string {
+	t.Helper()
+
+	body, _ := json.Marshal(CreateAPIKeyRequest{Name: "test", Scopes: scopes})
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/api-keys", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(apiKeyHeader, testAdminKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp CreateAPIKeyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	return resp.Key
+}
+
+func TestAuthMissingKey(t *testing.T) {
+	r, _ := setupAuthRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", w.Code)
+	}
+}
+
+func TestAuthInvalidKey(t *testing.T) {
+	r, _ := setupAuthRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes", nil)
+	req.Header.Set(apiKeyHeader, "pk_wrong")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", w.Code)
+	}
+}
+
+func TestAuthHealthIsPublic(t *testing.T) {
+	r, _ := setupAuthRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAuthScopes(t *testing.T) {
+	r, _ := setupAuthRouter()
+	readKey := createKey(t, r, string(auth.ScopePackSizesRead))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"read allowed", http.MethodGet, "/api/pack-sizes", "", http.StatusOK},
+		{"write forbidden", http.MethodPut, "/api/pack-sizes", `{"pack_sizes": [1]}`, http.StatusForbidden},
+		{"calculate forbidden", http.MethodGet, "/api/calculate?amount=1", "", http.StatusForbidden},
+		{"admin forbidden", http.MethodGet, "/api/admin/api-keys", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(apiKeyHeader, readKey)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestCreateAPIKeyStoresHashOnly(t *testing.T) {
+	r, keys := setupAuthRouter()
+	raw := createKey(t, r, string(auth.ScopeCalculate))
+
+	for _, k := range keys.ListAPIKeys() {
+		if k.KeyHash == raw {
+			t.Fatal("raw key must not be stored")
+		}
+	}
+
+	if _, ok := keys.GetAPIKeyByHash(auth.HashKey(raw)); !ok {
+		t.Error("expected key to be found by hash")
+	}
+}
+
+func TestCreateAPIKeyInvalidScope(t *testing.T) {
+	r, _ := setupAuthRouter()
+
+	body := `{"name": "bad", "scopes": ["everything"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/api-keys", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(apiKeyHeader, testAdminKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	r, keys := setupAuthRouter()
+	raw := createKey(t, r, string(auth.ScopeCalculate))
+	key, _ := keys.GetAPIKeyByHash(auth.HashKey(raw))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/api-keys/"+key.ID, nil)
+	req.Header.Set(apiKeyHeader, testAdminKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/calculate?amount=1", nil)
+	req.Header.Set(apiKeyHeader, raw)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected revoked key to get 401, got %d", w.Code)
+	}
+}
+
+func setupJWTRouter(t *testing.T) (*gin.Engine, *rsa.PrivateKey) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("failed to write jwks: %v", err)
+	}
+
+	v, err := auth.NewJWTValidator(auth.JWTConfig{JWKSFile: path, Issuer: "sso", Audience: "api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := gin.New()
+	New(storage.NewMemoryStorage(), WithJWT(v)).RegisterRoutes(r)
+	return r, key
+}
+
+func bearerFor(t *testing.T, key *rsa.PrivateKey, sub string, roles ...string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub":   sub,
+		"iss":   "sso",
+		"aud":   "api",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	})
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return "Bearer " + signed
+}
+
+func TestJWTRoleGuardsMutations(t *testing.T) {
+	r, key := setupJWTRouter(t)
+
+	body := `{"size": 750}`
+	req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/add", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", bearerFor(t, key, "bob", "viewer"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected viewer to get 403, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/pack-sizes", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected malformed token to get 401, got %d", w.Code)
+	}
+}
+
+func TestJWTSubjectRecordedAsActor(t *testing.T) {
+	r, key := setupJWTRouter(t)
+
+	body := `{"size": 750}`
+	req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/add", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", bearerFor(t, key, "alice", "operator"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/pack-sizes/history", nil)
+	req.Header.Set("Authorization", bearerFor(t, key, "alice", "viewer"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp HistoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if len(resp.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(resp.Changes))
+	}
+	if resp.Changes[0].Actor != "alice" || resp.Changes[0].Action != storage.ChangeAdd {
+		t.Errorf("unexpected change %+v", resp.Changes[0])
+	}
+}
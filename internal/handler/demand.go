@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/demand"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// DemandResponse is the recorded demand histogram. Dropped counts amounts the
+// collector could not queue since it started.
+type DemandResponse struct {
+	BucketSeconds int64                  `json:"bucket_seconds"`
+	Orders        int                    `json:"orders"`
+	Dropped       int64                  `json:"dropped"`
+	Buckets       []storage.DemandBucket `json:"buckets"`
+}
+
+// WithDemand records the amount of every REST calculation and enables the
+// /api/demand export.
+func WithDemand(c *demand.Collector) Option {
+	return func(h *Handler) {
+		h.demand = c
+	}
+}
+
+// recordDemand queues the amount of a successful calculation
+func (h *Handler) recordDemand(resp CalculateResponse) {
+	if h.demand != nil {
+		h.demand.Record(resp.OrderAmount)
+	}
+}
+
+// GetDemand exports the histogram as JSON, or as CSV rows of bucket start,
+// amount and orders with ?format=csv.
+func (h *Handler) GetDemand(c *gin.Context) {
+	var fieldErrors []FieldError
+	timeParam := func(name string) time.Time {
+		v := c.Query(name)
+		if v == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Reason: "datetime", Param: time.RFC3339})
+		}
+		return t
+	}
+	from, to := timeParam("from"), timeParam("to")
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "format", Reason: "oneof", Param: "json csv"})
+	}
+	if len(fieldErrors) > 0 {
+		resp := newProblem(c, CodeValidationFailed, "One or more query parameters are invalid")
+		resp.Errors = fieldErrors
+		writeProblem(c, resp)
+		return
+	}
+
+	h.demand.Flush()
+	buckets := h.demand.Store().ListDemand(from, to)
+
+	if format == "csv" {
+		writeDemandCSV(c, buckets)
+		return
+	}
+
+	resp := DemandResponse{
+		BucketSeconds: int64(h.demand.Store().BucketWidth() / time.Second),
+		Dropped:       h.demand.Dropped(),
+		Buckets:       buckets,
+	}
+	for _, b := range buckets {
+		resp.Orders += b.Orders
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func writeDemandCSV(c *gin.Context, buckets []storage.DemandBucket) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="demand.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"bucket_start", "amount", "orders"})
+	for _, b := range buckets {
+		start := b.Start.Format(time.RFC3339)
+		amounts := make([]int, 0, len(b.Amounts))
+		for amount := range b.Amounts {
+			amounts = append(amounts, amount)
+		}
+		slices.Sort(amounts)
+		for _, amount := range amounts {
+			w.Write([]string{start, strconv.Itoa(amount), strconv.Itoa(b.Amounts[amount])})
+		}
+	}
+	w.Flush()
+}
+
+// ResetDemand clears the histogram, including amounts still queued.
+func (h *Handler) ResetDemand(c *gin.Context) {
+	h.demand.Flush()
+	h.demand.Store().ResetDemand()
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/demand"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func setupDemandRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	collector := demand.NewCollector(storage.NewMemoryDemandStore(time.Hour, 0), demand.Config{FlushInterval: time.Hour})
+	t.Cleanup(collector.Close)
+
+	r := gin.New()
+	New(storage.NewMemoryStorage(), WithDemand(collector)).RegisterRoutes(r)
+	return r
+}
+
+func TestCalculationsRecordDemand(t *testing.T) {
+	r := setupDemandRouter(t)
+
+	for _, amount := range []int{251, 251, 12001} {
+		doJSON(r, http.MethodPost, "/api/calculate", CalculateRequest{Amount: amount})
+	}
+	doJSON(r, http.MethodPost, "/api/calculate/shipments", ShipmentsRequest{Amount: 500, ShipmentLimit: calculator.ShipmentLimit{MaxPacks: 1}})
+	// failed calculations are not demand
+	doJSON(r, http.MethodPost, "/api/calculate", CalculateRequest{Amount: 1, PackSizes: []int{-1}})
+
+	var resp DemandResponse
+	w := doJSON(r, http.MethodGet, "/api/demand", nil)
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Orders != 4 || resp.BucketSeconds != 3600 || len(resp.Buckets) != 1 {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if amounts := resp.Buckets[0].Amounts; amounts[251] != 2 || amounts[12001] != 1 || amounts[500] != 1 {
+		t.Errorf("unexpected histogram %v", amounts)
+	}
+}
+
+func TestDemandCSVAndReset(t *testing.T) {
+	r := setupDemandRouter(t)
+	doJSON(r, http.MethodPost, "/api/calculate", CalculateRequest{Amount: 12001})
+	doJSON(r, http.MethodPost, "/api/calculate", CalculateRequest{Amount: 251})
+
+	w := doJSON(r, http.MethodGet, "/api/demand?format=csv", nil)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") || len(lines) != 3 {
+		t.Fatalf("unexpected CSV %d %q", w.Code, w.Body.String())
+	}
+	if lines[0] != "bucket_start,amount,orders" || !strings.HasSuffix(lines[1], ",251,1") || !strings.HasSuffix(lines[2], ",12001,1") {
+		t.Errorf("unexpected rows %q", lines)
+	}
+
+	if w := doJSON(r, http.MethodDelete, "/api/demand", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	var resp DemandResponse
+	json.Unmarshal(doJSON(r, http.MethodGet, "/api/demand", nil).Body.Bytes(), &resp)
+	if resp.Orders != 0 || len(resp.Buckets) != 0 {
+		t.Errorf("expected an empty histogram after reset, got %+v", resp)
+	}
+}
+
+func TestDemandInvalidQuery(t *testing.T) {
+	r := setupDemandRouter(t)
+
+	for _, query := range []string{"?format=xml", "?from=yesterday"} {
+		if w := doJSON(r, http.MethodGet, "/api/demand"+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestDemandDisabled(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	if w := doJSON(r, http.MethodGet, "/api/demand", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a collector, got %d", w.Code)
+	}
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/demand"
+	"github.com/willianbsanches13/pack-calculator/internal/pricing"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+	"github.com/willianbsanches13/pack-calculator/internal/simulation"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+	"github.com/willianbsanches13/pack-calculator/internal/webhook"
+)
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadSpec(t *testing.T) openAPIDoc {
+	t.Helper()
+
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	return doc
+}
+
+// fullRouter registers every optional route so the spec check covers them
+func fullRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := New(storage.NewMemoryStorage(),
+		WithAPIKeys(storage.NewMemoryAPIKeyStore()),
+		WithRateLimit(ratelimit.New(ratelimit.Config{Default: ratelimit.Limit{Rate: 1, Burst: 1}}, nil)),
+		WithWebhooks(webhook.NewDispatcher(storage.NewMemoryWebhookStore(), webhook.Config{})),
+		WithAudit(storage.NewMemoryAuditLog(0, 0)),
+		WithDemand(demand.NewCollector(storage.NewMemoryDemandStore(0, 0), demand.Config{})),
+		WithTenants(storage.NewMemoryTenantRegistry(func(string) storage.TenantStores { return storage.TenantStores{} })),
+	)
+	h.RegisterRoutes(r)
+	return r
+}
+
+// openAPIPath converts gin's ":param" and "*param" segments to "{param}"
+func openAPIPath(ginPath string) string {
+	parts := strings.Split(ginPath, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func TestOpenAPICoversAllRoutes(t *testing.T) {
+	doc := loadSpec(t)
+
+	for _, route := range fullRouter().Routes() {
+		path := openAPIPath(route.Path)
+		ops, ok := doc.Paths[path]
+		if !ok {
+			t.Errorf("route %s %s is missing from openapi.json", route.Method, route.Path)
+			continue
+		}
+		if _, ok := ops[strings.ToLower(route.Method)]; !ok {
+			t.Errorf("operation %s %s is missing from openapi.json", route.Method, route.Path)
+		}
+	}
+}
+
+func TestOpenAPISchemasMatchTypes(t *testing.T) {
+	doc := loadSpec(t)
+
+	types := map[string]interface{}{
+		"PackSizesResponse":     PackSizesResponse{},
+		"CalculateRequest":      CalculateRequest{},
+		"CalculateResponse":     CalculateResponse{},
+		"ErrorResponse":         ErrorResponse{},
+		"FieldError":            FieldError{},
+		"AddPackSizeRequest":    AddPackSizeRequest{},
+		"RemovePackSizeRequest": RemovePackSizeRequest{},
+		"HistoryResponse":       HistoryResponse{},
+		"Change":                storage.Change{},
+		"APIKey":                storage.APIKey{},
+		"APIKeysResponse":       APIKeysResponse{},
+		"CreateAPIKeyRequest":   CreateAPIKeyRequest{},
+		"EnvelopeMeta":          EnvelopeMeta{},
+		"GraphQLRequest":        GraphQLRequest{},
+		"Webhook":               storage.Webhook{},
+		"WebhookRequest":        WebhookRequest{},
+		"WebhooksResponse":      WebhooksResponse{},
+		"Delivery":              webhook.Delivery{},
+		"DeliveriesResponse":    DeliveriesResponse{},
+		"DeadLetter":            webhook.DeadLetter{},
+		"DeadLettersResponse":   DeadLettersResponse{},
+		"WebhookEvent":          webhook.Event{},
+		"CalculationRecord":     storage.CalculationRecord{},
+		"CalculationsResponse":  CalculationsResponse{},
+		"Profile":               storage.Profile{},
+		"CreateProfileRequest":  CreateProfileRequest{},
+		"UpdateProfileRequest":  UpdateProfileRequest{},
+		"ProfilesResponse":      ProfilesResponse{},
+		"Constraints":           calculator.Constraints{},
+		"InfeasibleError":       calculator.InfeasibleError{},
+		"Pack":                  calculator.Pack{},
+		"Dimensions":            calculator.Dimensions{},
+		"ShipmentLimit":         calculator.ShipmentLimit{},
+		"Shipment":              calculator.Shipment{},
+		"ShipmentsRequest":      ShipmentsRequest{},
+		"ShipmentsResponse":     ShipmentsResponse{},
+		"CartonType":            calculator.CartonType{},
+		"Packaging":             calculator.Packaging{},
+		"HandlingUnit":          calculator.HandlingUnit{},
+		"PackagingRequest":      PackagingRequest{},
+		"PackagingResponse":     PackagingResponse{},
+		"Tolerance":             calculator.Tolerance{},
+		"Backorder":             calculator.Backorder{},
+		"PriceList":             pricing.PriceList{},
+		"TenantsResponse":       TenantsResponse{},
+		"TenantResponse":        TenantResponse{},
+		"VolumeDiscount":        pricing.VolumeDiscount{},
+		"PriceListsResponse":    PriceListsResponse{},
+		"LineItem":              pricing.LineItem{},
+		"Quote":                 pricing.Quote{},
+		"QuoteRequest":          QuoteRequest{},
+		"QuoteResponse":         QuoteResponse{},
+		"Distribution":          simulation.Distribution{},
+		"Catalogue":             simulation.Catalogue{},
+		"SimulationRequest":     SimulationRequest{},
+		"CatalogueSummary":      simulation.Summary{},
+		"Outcome":               simulation.Outcome{},
+		"Difference":            simulation.Difference{},
+		"SimulationReport":      simulation.Report{},
+		"DemandBucket":          storage.DemandBucket{},
+		"DemandResponse":        DemandResponse{},
+	}
+
+	for name, v := range types {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing from openapi.json", name)
+			continue
+		}
+
+		var specFields []string
+		for field := range schema.Properties {
+			specFields = append(specFields, field)
+		}
+		sort.Strings(specFields)
+
+		goFields := jsonFields(reflect.TypeOf(v))
+		if !reflect.DeepEqual(specFields, goFields) {
+			t.Errorf("schema %s has fields %v, Go type has %v", name, specFields, goFields)
+		}
+	}
+}
+
+func jsonFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			fields = append(fields, jsonFields(f.Type)...)
+			continue
+		}
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestServeOpenAPIAndDocs(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil || spec["openapi"] == nil {
+		t.Errorf("expected an OpenAPI document, got error %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/docs", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/api/openapi.json") {
+		t.Errorf("expected docs page referencing the spec, got %d", w.Code)
+	}
+	if refs := strings.Count(docsPage, "https://"); refs != strings.Count(docsPage, "swagger-ui-dist@5.17.14/") || strings.Count(docsPage, `crossorigin="anonymous"`) != refs {
+		t.Errorf("expected every CDN asset pinned and loaded anonymously, got %s", docsPage)
+	}
+}
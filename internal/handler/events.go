@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const (
+	defaultHeartbeat = 15 * time.Second
+
+	// retryMillis tells EventSource clients how long to wait before reconnecting
+	retryMillis = 3000
+
+	packSizesEvent = "pack-sizes"
+)
+
+// WithHeartbeat sets how often idle event streams send a keep-alive comment.
+func WithHeartbeat(d time.Duration) Option {
+	return func(h *Handler) {
+		if d > 0 {
+			h.heartbeat = d
+		}
+	}
+}
+
+// PackSizeEvents streams pack size changes as Server-Sent Events. Clients
+// reconnecting with Last-Event-ID receive the changes they missed first.
+func (h *Handler) PackSizeEvents(c *gin.Context) {
+	feed, ok := h.stores(c).History.(storage.ChangeFeed)
+	if !ok {
+		problem(c, CodeNotFound, "change events are not available")
+		return
+	}
+
+	lastID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	sub := feed.Subscribe(lastID)
+	defer sub.Close()
+
+	w := c.Writer
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+	for _, change := range sub.Replay {
+		writeEvent(w, change)
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case change, ok := <-sub.C:
+			if !ok {
+				// dropped for falling behind; the client resumes from its last ID
+				return
+			}
+			writeEvent(w, change)
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		w.Flush()
+	}
+}
+
+func writeEvent(w gin.ResponseWriter, change storage.Change) {
+	data, _ := json.Marshal(change)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.ID, packSizesEvent, data)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+type sseEvent struct {
+	id, event, data, comment string
+}
+
+// readEvent reads lines up to the next blank line
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return ev
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.id = value
+		case "event":
+			ev.event = value
+		case "data":
+			ev.data = value
+		case "":
+			ev.comment = value
+		}
+	}
+}
+
+func openStream(t *testing.T, url, lastID string) *bufio.Reader {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, url+"/api/v2/pack-sizes/events", nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	if ev := readEvent(t, r); ev.comment != "" || ev.id != "" {
+		t.Fatalf("expected retry preamble, got %+v", ev)
+	}
+	return r
+}
+
+func setupEventServer(t *testing.T, opts ...Option) (*httptest.Server, *gin.Engine) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	New(storage.NewMemoryStorage(), opts...).RegisterRoutes(r)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, r
+}
+
+func TestPackSizeEventsPublishesChanges(t *testing.T) {
+	srv, r := setupEventServer(t)
+	stream := openStream(t, srv.URL, "")
+
+	body, _ := json.Marshal(AddPackSizeRequest{Size: 750})
+	req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/add", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	ev := readEvent(t, stream)
+	if ev.id != "1" || ev.event != packSizesEvent {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	var change storage.Change
+	json.Unmarshal([]byte(ev.data), &change)
+	if change.Action != storage.ChangeAdd || change.Size != 750 || len(change.PackSizes) != 6 {
+		t.Errorf("unexpected change %+v", change)
+	}
+}
+
+func TestPackSizeEventsReplaysAfterLastEventID(t *testing.T) {
+	history := storage.NewMemoryHistory(10)
+	history.RecordChange(storage.Change{Action: storage.ChangeAdd, Size: 10})
+	history.RecordChange(storage.Change{Action: storage.ChangeAdd, Size: 20})
+	history.RecordChange(storage.Change{Action: storage.ChangeAdd, Size: 30})
+
+	srv, _ := setupEventServer(t, WithHistory(history))
+	stream := openStream(t, srv.URL, "1")
+
+	for _, want := range []string{"2", "3"} {
+		if ev := readEvent(t, stream); ev.id != want {
+			t.Errorf("expected replayed event %s, got %+v", want, ev)
+		}
+	}
+}
+
+func TestPackSizeEventsHeartbeat(t *testing.T) {
+	srv, _ := setupEventServer(t, WithHeartbeat(10*time.Millisecond))
+	stream := openStream(t, srv.URL, "")
+
+	if ev := readEvent(t, stream); ev.comment != "heartbeat" {
+		t.Errorf("expected heartbeat, got %+v", ev)
+	}
+}
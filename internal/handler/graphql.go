@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/gqlapi"
+)
+
+var errMissingScope = errors.New("credentials lack the required scope")
+
+// ginContextKey carries the request's gin context into the resolvers, so
+// calculations go through the same path as REST
+type ginContextKey struct{}
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+func (h *Handler) newGraphQLSchema() graphql.Schema {
+	schema, err := gqlapi.NewSchema(&gqlapi.Resolver{
+		Storage:   h.storage,
+		History:   h.history,
+		Stores:    h.storesFor,
+		Authorize: h.authorizeContext,
+		Actor: func(ctx context.Context) string {
+			if p, ok := auth.PrincipalFrom(ctx); ok {
+				return p.Subject
+			}
+			return "anonymous"
+		},
+		Calculate: h.graphQLCalculate,
+		Charge:    h.graphQLCharge,
+	})
+	if err != nil {
+		// the schema is static, so this only fails on a programming error
+		panic("building graphql schema: " + err.Error())
+	}
+	return schema
+}
+
+func (h *Handler) authorizeContext(ctx context.Context, scope auth.Scope) error {
+	if !h.authEnabled() {
+		return nil
+	}
+	if p, ok := auth.PrincipalFrom(ctx); ok && p.HasScope(scope) {
+		return nil
+	}
+	return errMissingScope
+}
+
+// graphQLCalculate runs the calculate field like POST /calculate: profiles,
+// pack weights, the amount based rate limit cost, the audit log, webhooks and
+// demand all apply
+func (h *Handler) graphQLCalculate(ctx context.Context, in gqlapi.CalculateInput) (*calculator.CalculationResult, *calculator.Calculator, error) {
+	c := ctx.Value(ginContextKey{}).(*gin.Context)
+	resp, calc, p := h.runCalculation(c, calculationInput{
+		amount:      in.Amount,
+		packSizes:   in.PackSizes,
+		profile:     in.Profile,
+		constraints: in.Constraints,
+		solver:      in.Solver,
+	})
+	if p != nil {
+		return nil, nil, graphQLError(p)
+	}
+
+	return &calculator.CalculationResult{
+		Packs:       resp.Packs,
+		TotalItems:  resp.TotalItems,
+		TotalPacks:  resp.TotalPacks,
+		OrderAmount: resp.OrderAmount,
+		TotalWeight: resp.TotalWeight,
+		Backorder:   resp.Backorder,
+		Solver:      resp.Solver,
+	}, calc, nil
+}
+
+// graphQLCharge charges the amount based cost of alternatives, which build
+// their own table
+func (h *Handler) graphQLCharge(ctx context.Context, calc *calculator.Calculator, amount int) error {
+	c := ctx.Value(ginContextKey{}).(*gin.Context)
+	if p := h.chargeCalculation(c, calc, amount, calculator.Constraints{}); p != nil {
+		return graphQLError(p)
+	}
+	return nil
+}
+
+// graphQLError reports a problem as a GraphQL error with the same code
+func graphQLError(p *ErrorResponse) error {
+	return &gqlapi.Error{Code: p.Code, Err: errors.New(p.Detail)}
+}
+
+// GraphQL executes a query or mutation. Like any GraphQL server it answers
+// 200 with an "errors" array when resolvers fail.
+func (h *Handler) GraphQL(c *gin.Context) {
+	var req GraphQLRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.graphqlSchema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        context.WithValue(c.Request.Context(), ginContextKey{}, c),
+	})
+
+	c.JSON(http.StatusOK, result)
+}
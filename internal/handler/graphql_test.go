@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+)
+
+type graphQLResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, r *gin.Engine, key, query string) (int, graphQLResponse) {
+	t.Helper()
+
+	body, _ := json.Marshal(GraphQLRequest{Query: query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(apiKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp graphQLResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	code, resp := postGraphQL(t, r, "", `{ calculate(amount: 251) { totalItems totalPacks } }`)
+	if code != http.StatusOK || len(resp.Errors) != 0 {
+		t.Fatalf("expected success, got %d %+v", code, resp.Errors)
+	}
+
+	calc := resp.Data["calculate"].(map[string]interface{})
+	if calc["totalItems"] != float64(500) {
+		t.Errorf("expected totalItems 500, got %v", calc["totalItems"])
+	}
+}
+
+func TestGraphQLScopes(t *testing.T) {
+	r, _ := setupAuthRouter()
+
+	if code, _ := postGraphQL(t, r, "", `{ packSizes }`); code != http.StatusUnauthorized {
+		t.Errorf("expected anonymous request to get 401, got %d", code)
+	}
+
+	readKey := createKey(t, r, string(auth.ScopePackSizesRead))
+
+	if _, resp := postGraphQL(t, r, readKey, `{ packSizes }`); len(resp.Errors) != 0 {
+		t.Errorf("expected read to succeed, got %+v", resp.Errors)
+	}
+
+	_, resp := postGraphQL(t, r, readKey, `mutation { addPackSize(size: 750) }`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "forbidden" {
+		t.Errorf("expected forbidden error, got %+v", resp.Errors)
+	}
+}
+
+func TestGraphQLMutationRecordsActor(t *testing.T) {
+	r, _ := setupAuthRouter()
+
+	_, resp := postGraphQL(t, r, testAdminKey, `mutation { addPackSize(size: 750) }`)
+	if len(resp.Errors) != 0 {
+		t.Fatalf("unexpected errors %+v", resp.Errors)
+	}
+
+	_, resp = postGraphQL(t, r, testAdminKey, `{ history(limit: 1) { actor action } }`)
+	changes := resp.Data["history"].([]interface{})
+	if len(changes) != 1 || changes[0].(map[string]interface{})["actor"] != "apikey:admin" {
+		t.Errorf("unexpected history %v", changes)
+	}
+}
+
+func TestGraphQLCalculateMatchesREST(t *testing.T) {
+	r, _ := setupAuditRouter()
+
+	doJSON(r, http.MethodPost, "/api/profiles", CreateProfileRequest{Name: "holiday", PackSizes: []int{23, 31, 53}})
+
+	_, resp := postGraphQL(t, r, "", `{ calculate(amount: 500000, profile: "holiday", solver: "dp") { totalItems solver packs { size quantity } } }`)
+	if len(resp.Errors) != 0 {
+		t.Fatalf("unexpected errors %+v", resp.Errors)
+	}
+	calc := resp.Data["calculate"].(map[string]interface{})
+	if calc["totalItems"] != float64(500000) || calc["solver"] != "dp" {
+		t.Errorf("unexpected calculation %v", calc)
+	}
+
+	if code, list := listCalculations(t, r, ""); code != http.StatusOK || len(list.Calculations) != 1 {
+		t.Errorf("expected the calculation to be audited, got %d %+v", code, list)
+	}
+
+	_, resp = postGraphQL(t, r, "", `{ calculate(amount: 10, profile: "missing") { totalItems } }`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != CodeNotFound {
+		t.Errorf("expected not_found, got %+v", resp.Errors)
+	}
+}
+
+func TestGraphQLChargesEachCalculation(t *testing.T) {
+	r := setupRateLimitedRouter(ratelimit.Config{
+		Default:        ratelimit.Limit{Rate: 0.001, Burst: 10},
+		AmountPerToken: 1000,
+	})
+
+	// 1 base token + 4 per alias leaves a single token for the third
+	_, resp := postGraphQL(t, r, "", `{
+		a: calculate(amount: 4000) { totalItems }
+		b: calculate(amount: 4000) { totalItems }
+		c: calculate(amount: 4000) { totalItems }
+	}`)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != CodeRateLimited {
+		t.Fatalf("expected the third alias to be rate limited, got %+v", resp.Errors)
+	}
+}
@@ -2,33 +2,82 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
 	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/demand"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
 	"github.com/willianbsanches13/pack-calculator/internal/storage"
+	"github.com/willianbsanches13/pack-calculator/internal/webhook"
 )
 
 type Handler struct {
-	storage storage.Storage
+	storage  storage.Storage
+	history  storage.HistoryStore
+	apiKeys  storage.APIKeyStore // nil disables API key authentication
+	jwt      *auth.JWTValidator  // nil disables bearer token authentication
+	limiter  *ratelimit.Limiter  // nil disables rate limiting
+	webhooks *webhook.Dispatcher // nil disables webhooks
+	audit    storage.AuditStore  // nil disables the calculation audit log
+	demand   *demand.Collector   // nil disables demand recording
+
+	tenants storage.TenantRegistry // nil serves every request from the stores above
+
+	idempotency    storage.IdempotencyStore // nil disables Idempotency-Key support
+	idempotencyTTL time.Duration
+
+	heartbeat time.Duration
+	maxAmount int
+
+	graphqlSchema graphql.Schema
 }
 
-func New(s storage.Storage) *Handler {
-	return &Handler{storage: s}
+// Option configures optional Handler features.
+type Option func(*Handler)
+
+func New(s storage.Storage, opts ...Option) *Handler {
+	h := &Handler{
+		storage:   s,
+		history:   storage.NewMemoryHistory(storage.DefaultHistoryLimit),
+		heartbeat: defaultHeartbeat,
+		maxAmount: calculator.DefaultMaxAmount,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.graphqlSchema = h.newGraphQLSchema()
+	return h
 }
 
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+// WithHistory shares a change history with other servers, e.g. gRPC.
+func WithHistory(history storage.HistoryStore) Option {
+	return func(h *Handler) {
+		h.history = history
+	}
+}
+
+type HistoryResponse struct {
+	Changes []storage.Change `json:"changes"`
 }
 
+// PackSizesResponse lists the sizes and, when the storage keeps them, the full
+// pack definitions. Requests may send either pack_sizes or packs.
 type PackSizesResponse struct {
-	PackSizes []int  `json:"pack_sizes"`
-	Message   string `json:"message,omitempty"`
+	PackSizes []int             `json:"pack_sizes"`
+	Packs     []calculator.Pack `json:"packs,omitempty"`
+	Message   string            `json:"message,omitempty"`
 }
 
 type CalculateRequest struct {
-	Amount    int   `json:"amount" binding:"required,gt=0"`
-	PackSizes []int `json:"pack_sizes,omitempty"`
+	Amount      int                    `json:"amount" binding:"required,gt=0"`
+	PackSizes   []int                  `json:"pack_sizes,omitempty"`
+	Constraints calculator.Constraints `json:"constraints,omitempty"`
+	Solver      string                 `json:"solver,omitempty"`
+	calculator.Fulfilment
 }
 
 type CalculateResponse struct {
@@ -37,6 +86,10 @@ type CalculateResponse struct {
 	TotalPacks  int         `json:"total_packs"`
 	Packs       map[int]int `json:"packs"`
 	PackSizes   []int       `json:"pack_sizes_used"`
+	TotalWeight float64     `json:"total_weight_kg,omitempty"`
+
+	Backorder *calculator.Backorder `json:"backorder,omitempty"`
+	Solver    string                `json:"solver,omitempty"`
 }
 
 type AddPackSizeRequest struct {
@@ -48,201 +101,399 @@ type RemovePackSizeRequest struct {
 }
 
 func (h *Handler) GetPackSizes(c *gin.Context) {
-	sizes := h.storage.GetPackSizes()
-	c.JSON(http.StatusOK, PackSizesResponse{PackSizes: sizes})
+	c.JSON(http.StatusOK, h.packSizesResponse(c))
 }
 
 func (h *Handler) SetPackSizes(c *gin.Context) {
-	var req PackSizesResponse
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_json",
-			Message: "Failed to parse request body",
-		})
+	if !h.replacePackSizes(c) {
 		return
 	}
 
-	if len(req.PackSizes) == 0 {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_pack_sizes",
-			Message: "Pack sizes cannot be empty",
-		})
-		return
+	resp := h.packSizesResponse(c)
+	resp.Message = "Pack sizes updated successfully"
+	c.JSON(http.StatusOK, resp)
+}
+
+// packSizesResponse reads the tenant's sizes and pack definitions
+func (h *Handler) packSizesResponse(c *gin.Context) PackSizesResponse {
+	store := h.stores(c).Storage
+	resp := PackSizesResponse{PackSizes: store.GetPackSizes()}
+	if ps, ok := store.(storage.PackStore); ok {
+		resp.Packs = ps.GetPacks()
+	}
+	return resp
+}
+
+// packs returns the tenant's pack definitions, bare sizes when the storage
+// keeps no attributes
+func (h *Handler) packs(c *gin.Context) []calculator.Pack {
+	store := h.stores(c).Storage
+	if ps, ok := store.(storage.PackStore); ok {
+		return ps.GetPacks()
+	}
+	return calculator.PacksFromSizes(store.GetPackSizes())
+}
+
+// replacePackSizes validates and stores the requested list or pack
+// definitions, writing a problem and returning false when the request is
+// rejected
+func (h *Handler) replacePackSizes(c *gin.Context) bool {
+	var req PackSizesResponse
+	if !bindJSON(c, &req) {
+		return false
 	}
 
-	for _, size := range req.PackSizes {
-		if size <= 0 {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "invalid_pack_size",
-				Message: "All pack sizes must be greater than zero",
-			})
-			return
+	store := h.stores(c).Storage
+	if len(req.Packs) > 0 {
+		if len(req.PackSizes) > 0 {
+			problem(c, CodeValidationFailed, "pack_sizes and packs cannot be combined")
+			return false
+		}
+		ps, ok := store.(storage.PackStore)
+		if !ok {
+			problem(c, CodeValidationFailed, "Pack definitions are not supported by this storage")
+			return false
+		}
+		if !validPacks(c, req.Packs) {
+			return false
 		}
+		ps.SetPacks(req.Packs)
+	} else {
+		if !validPackSizes(c, req.PackSizes) {
+			return false
+		}
+		store.SetPackSizes(req.PackSizes)
 	}
 
-	h.storage.SetPackSizes(req.PackSizes)
-	c.JSON(http.StatusOK, PackSizesResponse{
-		PackSizes: h.storage.GetPackSizes(),
-		Message:   "Pack sizes updated successfully",
-	})
+	h.recordChange(c, storage.ChangeSet, 0, store.GetPackSizes())
+	return true
 }
 
 func (h *Handler) Calculate(c *gin.Context) {
-	var amount int
-	var packSizes []int
+	resp, ok := h.calculate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// calculationInput is a calculation requested over REST or GraphQL
+type calculationInput struct {
+	amount      int
+	packSizes   []int
+	profile     string
+	constraints calculator.Constraints
+	fulfilment  calculator.Fulfilment
+	solver      string
+}
+
+// calculate reads the amount from the query (GET) or body (POST) and runs the
+// calculation, writing a problem and returning false on failure
+func (h *Handler) calculate(c *gin.Context) (CalculateResponse, bool) {
+	in := calculationInput{profile: c.Query("profile")}
 
 	if c.Request.Method == http.MethodGet {
 		amountQuery := c.Query("amount")
 		if amountQuery == "" {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "missing_amount",
-				Message: "Amount query parameter is required",
-			})
-			return
+			problem(c, CodeMissingAmount, "Amount query parameter is required")
+			return CalculateResponse{}, false
 		}
 
 		if n, err := parsePositiveInt(amountQuery); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "invalid_amount",
-				Message: "Amount must be a valid positive integer",
-			})
-			return
+			problem(c, CodeInvalidAmount, "Amount must be a valid positive integer")
+			return CalculateResponse{}, false
 		} else {
-			amount = n
+			in.amount = n
 		}
-
-		packSizes = h.storage.GetPackSizes()
+		in.solver = c.Query("solver")
 	} else {
 		var req CalculateRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error:   "invalid_json",
-				Message: "Failed to parse request body or amount must be greater than zero",
-			})
-			return
+		if !bindJSON(c, &req) {
+			return CalculateResponse{}, false
 		}
 
-		amount = req.Amount
+		in.amount = req.Amount
+		in.packSizes = req.PackSizes
+		in.constraints = req.Constraints
+		in.fulfilment = req.Fulfilment
+		in.solver = req.Solver
+	}
 
-		if len(req.PackSizes) > 0 {
-			packSizes = req.PackSizes
-		} else {
-			packSizes = h.storage.GetPackSizes()
-		}
+	resp, _, p := h.runCalculation(c, in)
+	if p != nil {
+		writeProblem(c, *p)
+		return CalculateResponse{}, false
 	}
+	return resp, true
+}
 
-	if amount <= 0 {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_amount",
-			Message: "Amount must be greater than zero",
-		})
-		return
+// runCalculation picks the packs, charges and runs a calculation, then
+// audits, publishes and records it. It writes nothing, so GraphQL can share
+// it: a failure comes back as the problem to report. The calculator is
+// returned for follow-up queries such as alternatives.
+func (h *Handler) runCalculation(c *gin.Context, in calculationInput) (CalculateResponse, *calculator.Calculator, *ErrorResponse) {
+	fail := func(code, detail string) (CalculateResponse, *calculator.Calculator, *ErrorResponse) {
+		p := newProblem(c, code, detail)
+		return CalculateResponse{}, nil, &p
+	}
+	failCalculator := func(err error) (CalculateResponse, *calculator.Calculator, *ErrorResponse) {
+		p := newCalculatorProblem(c, err)
+		return CalculateResponse{}, nil, &p
 	}
 
-	calc, err := calculator.New(packSizes)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "calculator_error",
-			Message: err.Error(),
-		})
-		return
+	packs, p := h.selectPacks(c, in.profile, in.packSizes)
+	if p != nil {
+		return CalculateResponse{}, nil, p
+	}
+
+	if in.amount <= 0 {
+		return fail(CodeInvalidAmount, "Amount must be greater than zero")
+	}
+
+	calc, p := h.newCalculator(c, packs, in.amount, in.constraints)
+	if p != nil {
+		return CalculateResponse{}, nil, p
+	}
+	if err := calc.SetSolver(in.solver); err != nil {
+		return failCalculator(err)
 	}
 
-	result, err := calc.CalculateWithDetails(amount)
+	result, err := calc.CalculateFulfilment(in.amount, in.constraints, in.fulfilment)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "calculation_error",
-			Message: err.Error(),
-		})
-		return
+		return failCalculator(err)
 	}
 
-	c.JSON(http.StatusOK, CalculateResponse{
+	resp := newCalculateResponse(result, packs)
+	h.auditCalculation(c, resp)
+	h.publishCalculation(c, resp)
+	h.recordDemand(resp)
+	return resp, calc, nil
+}
+
+// calculationPacks picks the packs to calculate with: an explicit list wins
+// over the active profile, and a named profile may not be combined with one
+func (h *Handler) calculationPacks(c *gin.Context, packSizes []int) ([]calculator.Pack, bool) {
+	packs, p := h.selectPacks(c, c.Query("profile"), packSizes)
+	if p != nil {
+		writeProblem(c, *p)
+		return nil, false
+	}
+	return packs, true
+}
+
+// selectPacks is calculationPacks for a given profile, returning the problem
+// unwritten
+func (h *Handler) selectPacks(c *gin.Context, profile string, packSizes []int) ([]calculator.Pack, *ErrorResponse) {
+	if profile != "" {
+		if len(packSizes) > 0 {
+			p := newProblem(c, CodeValidationFailed, "pack_sizes and profile cannot be combined")
+			return nil, &p
+		}
+		return h.profilePacks(c, profile)
+	}
+	if len(packSizes) == 0 {
+		return h.packs(c), nil
+	}
+	return calculator.PacksFromSizes(packSizes), nil
+}
+
+func newCalculateResponse(result *calculator.CalculationResult, packs []calculator.Pack) CalculateResponse {
+	return CalculateResponse{
 		OrderAmount: result.OrderAmount,
 		TotalItems:  result.TotalItems,
 		TotalPacks:  result.TotalPacks,
 		Packs:       result.Packs,
-		PackSizes:   packSizes,
-	})
+		PackSizes:   calculator.Sizes(packs),
+		TotalWeight: result.TotalWeight,
+		Backorder:   result.Backorder,
+		Solver:      result.Solver,
+	}
 }
 
 func (h *Handler) AddPackSize(c *gin.Context) {
 	var req AddPackSizeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_json",
-			Message: "Failed to parse request body or size must be greater than zero",
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if !h.storage.AddPackSize(req.Size) {
-		c.JSON(http.StatusConflict, ErrorResponse{
-			Error:   "already_exists",
-			Message: "Pack size already exists",
-		})
+	if !h.stores(c).Storage.AddPackSize(req.Size) {
+		problem(c, CodeAlreadyExists, "Pack size already exists")
 		return
 	}
 
+	sizes := h.stores(c).Storage.GetPackSizes()
+	h.recordChange(c, storage.ChangeAdd, req.Size, sizes)
+
 	c.JSON(http.StatusCreated, PackSizesResponse{
-		PackSizes: h.storage.GetPackSizes(),
+		PackSizes: sizes,
 		Message:   "Pack size added successfully",
 	})
 }
 
 func (h *Handler) RemovePackSize(c *gin.Context) {
 	var req RemovePackSizeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_json",
-			Message: "Failed to parse request body or size must be greater than zero",
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if !h.storage.RemovePackSize(req.Size) {
-		c.JSON(http.StatusNotFound, ErrorResponse{
-			Error:   "not_found",
-			Message: "Pack size not found",
-		})
+	if !h.stores(c).Storage.RemovePackSize(req.Size) {
+		problem(c, CodeNotFound, "Pack size not found")
 		return
 	}
 
+	sizes := h.stores(c).Storage.GetPackSizes()
+	h.recordChange(c, storage.ChangeRemove, req.Size, sizes)
+
 	c.JSON(http.StatusOK, PackSizesResponse{
-		PackSizes: h.storage.GetPackSizes(),
+		PackSizes: sizes,
 		Message:   "Pack size removed successfully",
 	})
 }
 
+func (h *Handler) GetHistory(c *gin.Context) {
+	c.JSON(http.StatusOK, HistoryResponse{Changes: h.stores(c).History.History()})
+}
+
+func (h *Handler) recordChange(c *gin.Context, action string, size int, sizes []int) {
+	h.stores(c).History.RecordChange(storage.Change{
+		Action:    action,
+		Size:      size,
+		PackSizes: sizes,
+		Actor:     actor(c),
+	})
+}
+
 func (h *Handler) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	r.GET("/health", h.Health)
+	r.POST("/graphql", h.authenticated(), h.rateLimit(), h.idempotent(), h.GraphQL)
+
+	read := h.requireScope(auth.ScopePackSizesRead)
+	write := h.requireScope(auth.ScopePackSizesWrite)
+	calc := h.requireScope(auth.ScopeCalculate)
+	limit := h.rateLimit()
+	idem := h.idempotent()
 
 	api := r.Group("/api")
 	{
-		api.GET("/pack-sizes", h.GetPackSizes)
-		api.PUT("/pack-sizes", h.SetPackSizes)
-		api.POST("/pack-sizes", h.SetPackSizes)
-		api.POST("/pack-sizes/add", h.AddPackSize)
-		api.POST("/pack-sizes/remove", h.RemovePackSize)
-		api.DELETE("/pack-sizes/remove", h.RemovePackSize)
-		api.GET("/calculate", h.Calculate)
-		api.POST("/calculate", h.Calculate)
+		api.GET("/openapi.json", h.OpenAPI)
+		api.GET("/docs", h.Docs)
+	}
+
+	v1 := api.Group("")
+	{
+		v1.GET("/pack-sizes", deprecated("/api/v2/pack-sizes"), read, limit, h.GetPackSizes)
+		v1.GET("/pack-sizes/history", deprecated("/api/v2/pack-sizes/history"), read, limit, h.GetHistory)
+		v1.GET("/pack-sizes/events", deprecated("/api/v2/pack-sizes/events"), read, limit, h.PackSizeEvents)
+		v1.PUT("/pack-sizes", deprecated("/api/v2/pack-sizes"), write, limit, idem, h.SetPackSizes)
+		v1.POST("/pack-sizes", deprecated("/api/v2/pack-sizes"), write, limit, idem, h.SetPackSizes)
+		v1.POST("/pack-sizes/add", deprecated("/api/v2/pack-sizes/{size}"), write, limit, idem, h.AddPackSize)
+		v1.POST("/pack-sizes/remove", deprecated("/api/v2/pack-sizes/{size}"), write, limit, idem, h.RemovePackSize)
+		v1.DELETE("/pack-sizes/remove", deprecated("/api/v2/pack-sizes/{size}"), write, limit, idem, h.RemovePackSize)
+		v1.GET("/calculate", deprecated("/api/v2/calculate"), calc, limit, h.Calculate)
+		v1.POST("/calculate", deprecated("/api/v2/calculate"), calc, limit, idem, h.Calculate)
+	}
+	api.POST("/calculate/shipments", calc, limit, idem, h.CalculateShipments)
+	api.POST("/simulate", calc, limit, idem, h.Simulate)
+
+	v2 := api.Group("/v2")
+	{
+		v2.GET("/pack-sizes", read, limit, h.GetPackSizesV2)
+		v2.PUT("/pack-sizes", write, limit, idem, h.SetPackSizesV2)
+		v2.GET("/pack-sizes/history", read, limit, h.GetHistoryV2)
+		v2.GET("/pack-sizes/events", read, limit, h.PackSizeEvents)
+		v2.PUT("/pack-sizes/:size", write, limit, idem, h.PutPackSizeV2)
+		v2.DELETE("/pack-sizes/:size", write, limit, idem, h.DeletePackSizeV2)
+		v2.GET("/calculate", calc, limit, h.CalculateV2)
+		v2.POST("/calculate", calc, limit, idem, h.CalculateV2)
+	}
+
+	if h.apiKeys != nil {
+		admin := r.Group("/api/admin", h.requireScope(auth.ScopeAdmin), limit, idem)
+		{
+			admin.GET("/api-keys", h.ListAPIKeys)
+			admin.POST("/api-keys", h.CreateAPIKey)
+			admin.DELETE("/api-keys/:id", h.DeleteAPIKey)
+		}
+	}
+
+	if _, ok := h.storage.(storage.ProfileStore); ok {
+		profiles := api.Group("/profiles")
+		{
+			profiles.GET("", read, limit, h.ListProfiles)
+			profiles.POST("", write, limit, idem, h.CreateProfile)
+			profiles.GET("/:name", read, limit, h.GetProfile)
+			profiles.PUT("/:name", write, limit, idem, h.UpdateProfile)
+			profiles.DELETE("/:name", write, limit, idem, h.DeleteProfile)
+			profiles.POST("/:name/activate", write, limit, idem, h.ActivateProfile)
+		}
+	}
+
+	if _, ok := h.storage.(storage.PackagingStore); ok {
+		api.GET("/packaging", read, limit, h.GetPackaging)
+		api.PUT("/packaging", write, limit, idem, h.SetPackaging)
+		api.POST("/calculate/packaging", calc, limit, idem, h.CalculatePackaging)
+	}
+
+	if _, ok := h.storage.(storage.PriceStore); ok {
+		prices := api.Group("/price-lists")
+		{
+			prices.GET("", read, limit, h.ListPriceLists)
+			prices.GET("/:id", read, limit, h.GetPriceList)
+			prices.PUT("/:id", write, limit, idem, h.PutPriceList)
+			prices.DELETE("/:id", write, limit, idem, h.DeletePriceList)
+		}
+		api.POST("/quote", calc, limit, idem, h.Quote)
+	}
+
+	if h.audit != nil {
+		api.GET("/calculations", h.requireScope(auth.ScopeAdmin), limit, h.ListCalculations)
+	}
+
+	if h.tenants != nil {
+		tenants := api.Group("/tenants", h.requireScope(auth.ScopeAdmin), h.deploymentWide(), limit, idem)
+		{
+			tenants.GET("", h.ListTenants)
+			tenants.PUT("/:id", h.CreateTenant)
+		}
+	}
+
+	if h.demand != nil {
+		histogram := api.Group("/demand", h.requireScope(auth.ScopeAdmin), h.deploymentWide(), limit)
+		{
+			histogram.GET("", h.GetDemand)
+			histogram.DELETE("", idem, h.ResetDemand)
+		}
+	}
+
+	if h.webhooks != nil {
+		hooks := api.Group("/webhooks", h.requireScope(auth.ScopeAdmin), h.deploymentWide(), limit, idem)
+		{
+			hooks.GET("", h.ListWebhooks)
+			hooks.POST("", h.CreateWebhook)
+			hooks.GET("/dead-letters", h.ListDeadLetters)
+			hooks.POST("/dead-letters/:id/redeliver", h.RedeliverDeadLetter)
+			hooks.GET("/:id", h.GetWebhook)
+			hooks.PUT("/:id", h.UpdateWebhook)
+			hooks.DELETE("/:id", h.DeleteWebhook)
+			hooks.GET("/:id/deliveries", h.ListWebhookDeliveries)
+		}
 	}
 }
 
+// parsePositiveInt accepts plain digits only, no sign, and rejects values
+// that overflow an int rather than letting them wrap
 func parsePositiveInt(s string) (int, error) {
-	var n int
 	for _, c := range s {
 		if c < '0' || c > '9' {
 			return 0, calculator.ErrInvalidAmount
 		}
-		n = n*10 + int(c-'0')
 	}
-	if n <= 0 {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
 		return 0, calculator.ErrInvalidAmount
 	}
 	return n, nil
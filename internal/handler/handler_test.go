@@ -181,6 +181,21 @@ func TestCalculateGetMissingAmount(t *testing.T) {
 	}
 }
 
+func TestCalculateGetAmountOverflow(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	// 2^64 + 251 used to wrap around to 251
+	req := httptest.NewRequest(http.MethodGet, "/api/calculate?amount=18446744073709551867", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusBadRequest || resp.Code != CodeInvalidAmount {
+		t.Errorf("expected 400 invalid_amount, got %d %s", w.Code, w.Body.String())
+	}
+}
+
 func TestCalculateGetInvalidAmount(t *testing.T) {
 	r, _ := setupTestRouter()
 
@@ -326,3 +341,214 @@ func TestRemovePackSizeNotFound(t *testing.T) {
 		t.Errorf("expected status 404, got %d", w.Code)
 	}
 }
+
+func TestCalculateWithConstraints(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	body := `{"amount": 12001, "constraints": {"max_packs": {"5000": 1}}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp CalculateResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Packs[5000] != 1 || resp.TotalItems != 12250 {
+		t.Errorf("unexpected result %d %+v", w.Code, resp)
+	}
+}
+
+func TestCalculateInfeasibleConstraints(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{`{"amount": 12001, "constraints": {"max_total_packs": 2}}`, http.StatusUnprocessableEntity, CodeInfeasible},
+		{`{"amount": 12001, "constraints": {"max_packs": {"300": 1}}}`, http.StatusBadRequest, CodeInvalidConstraint},
+		{`{"amount": 1, "constraints": {"min_packs": {"5000": 20000}}}`, http.StatusBadRequest, CodeInvalidConstraint},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/calculate", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != tt.wantStatus || resp.Code != tt.wantCode {
+			t.Errorf("%s: expected %d %s, got %d %+v", tt.body, tt.wantStatus, tt.wantCode, w.Code, resp)
+		}
+		if tt.wantCode == CodeInfeasible && (resp.Infeasible == nil || resp.Infeasible.Constraint != "max_total_packs" || resp.Infeasible.Limit != 2) {
+			t.Errorf("expected the binding constraint, got %+v", resp.Infeasible)
+		}
+	}
+}
+
+func TestSetPacksWithWeights(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	body := `{"packs": [{"size": 250, "weight_kg": 1.5, "label": "small box"}, {"size": 500, "weight_kg": 2.5}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/pack-sizes", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var sizes PackSizesResponse
+	json.Unmarshal(w.Body.Bytes(), &sizes)
+	if w.Code != http.StatusOK || len(sizes.PackSizes) != 2 || len(sizes.Packs) != 2 || sizes.Packs[0].Label != "small box" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/calculate?amount=750", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp CalculateResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.TotalWeight != 4 {
+		t.Errorf("expected 4 kg, got %+v", resp)
+	}
+
+	// three small boxes weigh 4.5 kg, a large and a small one 4 kg
+	body = `{"amount": 750, "constraints": {"max_weight_kg": 3}}`
+	req = httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var problem ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if w.Code != http.StatusUnprocessableEntity || problem.Infeasible == nil || problem.Infeasible.Constraint != "max_weight" {
+		t.Errorf("expected the weight limit to be reported, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSetPacksInvalid(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		body     string
+		wantCode string
+	}{
+		{`{"packs": [{"size": 250, "weight_kg": -1}]}`, CodeInvalidPack},
+		{`{"packs": [{"size": 250, "dimensions": {"length_cm": 10, "width_cm": -1, "height_cm": 5}}]}`, CodeInvalidPack},
+		{`{"packs": [{"size": 0}]}`, CodeInvalidPackSize},
+		{`{"packs": [{"size": 250}], "pack_sizes": [500]}`, CodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/api/v2/pack-sizes", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Code != tt.wantCode {
+			t.Errorf("%s: expected 400 %s, got %d %+v", tt.body, tt.wantCode, w.Code, resp)
+		}
+	}
+}
+
+func TestCalculateFulfilmentModes(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantItems  int
+		wantCode   string
+	}{
+		{`{"amount": 4001, "mode": "tolerance", "tolerance": {"percent": 25}}`, http.StatusOK, 5000, ""},
+		{`{"amount": 12000, "mode": "exact"}`, http.StatusOK, 12000, ""},
+		{`{"amount": 12001, "mode": "exact"}`, http.StatusUnprocessableEntity, 0, CodeInfeasible},
+		{`{"amount": 12001, "mode": "cheapest"}`, http.StatusBadRequest, 0, CodeInvalidConstraint},
+		{`{"amount": 12001, "tolerance": {"items": 100}}`, http.StatusBadRequest, 0, CodeInvalidConstraint},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected %d, got %d: %s", tt.body, tt.wantStatus, w.Code, w.Body.String())
+			continue
+		}
+		if tt.wantCode != "" {
+			var resp ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Code != tt.wantCode {
+				t.Errorf("%s: expected %s, got %+v", tt.body, tt.wantCode, resp)
+			}
+			continue
+		}
+		var resp CalculateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.TotalItems != tt.wantItems {
+			t.Errorf("%s: expected %d items, got %+v", tt.body, tt.wantItems, resp)
+		}
+	}
+}
+
+func TestCalculateBackorder(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	body := `{"amount": 12001, "mode": "backorder", "inventory": {"5000": 1, "2000": 1, "1000": 0, "500": 0, "250": 4}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/calculate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp struct {
+		Data CalculateResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Data.TotalItems != 8000 {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if b := resp.Data.Backorder; b == nil || b.Amount != 4001 || b.TotalItems != 4250 {
+		t.Errorf("expected 4001 items backordered, got %+v", b)
+	}
+}
+
+func TestCalculateSolver(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		method, path, body string
+		wantStatus         int
+		wantSolver         string
+		wantPacks          int
+	}{
+		{http.MethodPost, "/api/calculate", `{"amount": 251, "solver": "greedy"}`, http.StatusOK, "greedy", 2},
+		{http.MethodPost, "/api/calculate", `{"amount": 251, "solver": "memory_bounded"}`, http.StatusOK, "memory_bounded", 1},
+		{http.MethodGet, "/api/calculate?amount=251&solver=auto", "", http.StatusOK, "branch_and_bound", 1},
+		{http.MethodPost, "/api/calculate", `{"amount": 251}`, http.StatusOK, "", 1},
+		{http.MethodPost, "/api/calculate", `{"amount": 251, "solver": "simplex"}`, http.StatusBadRequest, "", 0},
+		{http.MethodPost, "/api/calculate", `{"amount": 251, "solver": "dp", "constraints": {"max_total_packs": 3}}`, http.StatusBadRequest, "", 0},
+		{http.MethodPost, "/api/calculate", `{"amount": 250, "solver": "greedy", "mode": "exact"}`, http.StatusBadRequest, "", 0},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s %s: expected %d, got %d: %s", tt.path, tt.body, tt.wantStatus, w.Code, w.Body.String())
+			continue
+		}
+		var resp CalculateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if tt.wantStatus == http.StatusOK && (resp.Solver != tt.wantSolver || resp.TotalPacks != tt.wantPacks) {
+			t.Errorf("%s %s: expected %d packs from %q, got %+v", tt.path, tt.body, tt.wantPacks, tt.wantSolver, resp)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	replayedHeader       = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize bounds the body buffered to fingerprint a request
+	maxIdempotentBodySize = 4 << 20
+)
+
+// WithIdempotency enables Idempotency-Key support on mutating routes. Stored
+// responses are replayed for ttl; a zero ttl uses storage.DefaultIdempotencyTTL.
+func WithIdempotency(store storage.IdempotencyStore, ttl time.Duration) Option {
+	return func(h *Handler) {
+		if ttl <= 0 {
+			ttl = storage.DefaultIdempotencyTTL
+		}
+		h.idempotency = store
+		h.idempotencyTTL = ttl
+	}
+}
+
+// idempotent replays the first response sent for a repeated Idempotency-Key.
+// Keys are scoped to the caller and must be reused with the same request.
+// Safe methods and requests without the header pass through. It must run
+// after requireScope so keys are scoped by identity.
+func (h *Handler) idempotent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if h.idempotency == nil || key == "" || !mutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			problem(c, CodeValidationFailed, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem(c, CodeRequestTooLarge, "Request body must be at most 4 MiB with an Idempotency-Key")
+			return
+		}
+		if err != nil {
+			problem(c, CodeInvalidJSON, "Could not read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		scoped := tenantScope(c, actor(c)+"\x00"+key)
+		record := storage.IdempotencyRecord{
+			Fingerprint: requestFingerprint(c, body),
+			ExpiresAt:   now.Add(h.idempotencyTTL),
+		}
+
+		existing, reserved := h.idempotency.Reserve(scoped, record, now)
+		if !reserved {
+			replay(c, existing, record.Fingerprint)
+			return
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				h.idempotency.Release(scoped)
+				panic(r)
+			}
+		}()
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		// let clients retry failures that were not the request's fault
+		status := w.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			h.idempotency.Release(scoped)
+			return
+		}
+
+		h.idempotency.Complete(scoped, storage.StoredResponse{
+			Status:      status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        w.body.Bytes(),
+		})
+	}
+}
+
+func replay(c *gin.Context, existing storage.IdempotencyRecord, fingerprint string) {
+	switch {
+	case existing.Fingerprint != fingerprint:
+		problem(c, CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+	case existing.Response == nil:
+		problem(c, CodeIdempotencyKeyInUse, "A request with this Idempotency-Key is still being processed")
+	default:
+		c.Header(replayedHeader, "true")
+		if existing.Response.ContentType != "" {
+			c.Header("Content-Type", existing.Response.ContentType)
+		}
+		c.Status(existing.Response.Status)
+		c.Writer.Write(existing.Response.Body)
+		c.Abort()
+	}
+}
+
+// requestFingerprint identifies the method, route, query and body of a request
+func requestFingerprint(c *gin.Context, body []byte) string {
+	sum := sha256.New()
+	io.WriteString(sum, c.Request.Method+" "+c.Request.URL.Path+"?"+c.Request.URL.RawQuery+"\n")
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func mutating(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodDelete
+}
+
+// recordingWriter keeps a copy of the body written through it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func setupIdempotencyRouter(opts ...Option) (*gin.Engine, *Handler) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	opts = append(opts, WithIdempotency(storage.NewMemoryIdempotencyStore(), time.Hour))
+	h := New(storage.NewMemoryStorage(), opts...)
+	h.RegisterRoutes(r)
+	return r, h
+}
+
+func sendWithKey(r *gin.Engine, method, path, key string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(data))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotentRetryReplaysResponse(t *testing.T) {
+	r, h := setupIdempotencyRouter()
+
+	first := sendWithKey(r, http.MethodPost, "/api/pack-sizes/add", "retry-1", AddPackSizeRequest{Size: 750})
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", first.Code)
+	}
+
+	retry := sendWithKey(r, http.MethodPost, "/api/pack-sizes/add", "retry-1", AddPackSizeRequest{Size: 750})
+	if retry.Code != http.StatusCreated {
+		t.Errorf("expected replayed 201 instead of 409, got %d", retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("expected identical body, got %s", retry.Body.String())
+	}
+	if retry.Header().Get(replayedHeader) != "true" {
+		t.Error("expected replayed header")
+	}
+	if len(h.history.History()) != 1 {
+		t.Errorf("expected the change to be applied once, got %d history entries", len(h.history.History()))
+	}
+
+	// without a key the duplicate is rejected as before
+	if w := sendWithKey(r, http.MethodPost, "/api/pack-sizes/add", "", AddPackSizeRequest{Size: 750}); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 without a key, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyKeyReuseWithDifferentBody(t *testing.T) {
+	r, _ := setupIdempotencyRouter()
+
+	sendWithKey(r, http.MethodPost, "/api/v2/calculate", "job-1", CalculateRequest{Amount: 251})
+	w := sendWithKey(r, http.MethodPost, "/api/v2/calculate", "job-1", CalculateRequest{Amount: 501})
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusUnprocessableEntity || resp.Code != CodeIdempotencyKeyReused {
+		t.Errorf("expected 422 %s, got %d %s", CodeIdempotencyKeyReused, w.Code, resp.Code)
+	}
+
+	// same key on another route is also a different request
+	w = sendWithKey(r, http.MethodPut, "/api/v2/pack-sizes/750", "job-1", nil)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for another route, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyRejectsLargeBodies(t *testing.T) {
+	r, _ := setupIdempotencyRouter()
+
+	body := map[string]interface{}{"amount": 251, "padding": strings.Repeat(" ", maxIdempotentBodySize)}
+	w := sendWithKey(r, http.MethodPost, "/api/v2/calculate", "big-1", body)
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusRequestEntityTooLarge || resp.Code != CodeRequestTooLarge {
+		t.Errorf("expected 413 %s, got %d %s", CodeRequestTooLarge, w.Code, resp.Code)
+	}
+
+	// the key was never reserved
+	if w := sendWithKey(r, http.MethodPost, "/api/v2/calculate", "big-1", CalculateRequest{Amount: 251}); w.Code != http.StatusOK {
+		t.Errorf("expected the key to stay free, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyKeyInUse(t *testing.T) {
+	store := storage.NewMemoryIdempotencyStore()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	New(storage.NewMemoryStorage(), WithIdempotency(store, time.Hour)).RegisterRoutes(r)
+
+	// simulate a request still in flight under the same caller and key
+	data, _ := json.Marshal(AddPackSizeRequest{Size: 750})
+	req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/add", bytes.NewBuffer(data))
+	fingerprintCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	fingerprintCtx.Request = req
+	store.Reserve("anonymous\x00busy", storage.IdempotencyRecord{
+		Fingerprint: requestFingerprint(fingerprintCtx, data),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}, time.Now())
+
+	w := sendWithKey(r, http.MethodPost, "/api/pack-sizes/add", "busy", AddPackSizeRequest{Size: 750})
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusConflict || resp.Code != CodeIdempotencyKeyInUse {
+		t.Errorf("expected 409 %s, got %d %s", CodeIdempotencyKeyInUse, w.Code, resp.Code)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/boom", (&Handler{idempotency: storage.NewMemoryIdempotencyStore(), idempotencyTTL: time.Hour}).idempotent(), func(c *gin.Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	sendWithKey(r, http.MethodPost, "/boom", "k", nil)
+	if w := sendWithKey(r, http.MethodPost, "/boom", "k", nil); w.Header().Get(replayedHeader) != "" {
+		t.Error("5xx responses must not be replayed")
+	}
+}
+
+func TestIdempotencyKeysAreScopedToCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := storage.NewMemoryAPIKeyStore()
+	r := gin.New()
+	New(storage.NewMemoryStorage(), WithAPIKeys(keys), WithIdempotency(storage.NewMemoryIdempotencyStore(), time.Hour)).RegisterRoutes(r)
+	keys.CreateAPIKey(storage.APIKey{ID: "a", KeyHash: auth.HashKey("pk_a"), Scopes: []string{"calculate"}})
+	keys.CreateAPIKey(storage.APIKey{ID: "b", KeyHash: auth.HashKey("pk_b"), Scopes: []string{"calculate"}})
+
+	send := func(apiKey string, amount int) int {
+		data, _ := json.Marshal(CalculateRequest{Amount: amount})
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/calculate", bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(apiKeyHeader, apiKey)
+		req.Header.Set(idempotencyKeyHeader, "shared")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	send("pk_a", 251)
+	if code := send("pk_b", 501); code != http.StatusOK {
+		t.Errorf("expected another caller's key to be independent, got %d", code)
+	}
+}
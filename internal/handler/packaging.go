@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// PackagingRequest calculates an order and rolls the packs up into cartons
+// and pallets. Packaging overrides the stored hierarchy for this request.
+type PackagingRequest struct {
+	Amount      int                    `json:"amount" binding:"required,gt=0"`
+	PackSizes   []int                  `json:"pack_sizes,omitempty"`
+	Constraints calculator.Constraints `json:"constraints,omitempty"`
+	Packaging   *calculator.Packaging  `json:"packaging,omitempty"`
+	calculator.Fulfilment
+}
+
+// PackagingResponse is the calculation followed by its packaging tree.
+type PackagingResponse struct {
+	CalculateResponse
+	calculator.PackagingPlan
+}
+
+// packaging returns the tenant's storage as a PackagingStore when it keeps one
+func (h *Handler) packaging(c *gin.Context) (storage.PackagingStore, bool) {
+	ps, ok := h.stores(c).Storage.(storage.PackagingStore)
+	return ps, ok
+}
+
+func (h *Handler) GetPackaging(c *gin.Context) {
+	ps, _ := h.packaging(c)
+	c.JSON(http.StatusOK, ps.GetPackaging())
+}
+
+func (h *Handler) SetPackaging(c *gin.Context) {
+	var req calculator.Packaging
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+
+	ps, _ := h.packaging(c)
+	ps.SetPackaging(req)
+	c.JSON(http.StatusOK, ps.GetPackaging())
+}
+
+// CalculatePackaging calculates the packs for an order and plans the cartons
+// and pallets that carry them with the fewest handling units.
+func (h *Handler) CalculatePackaging(c *gin.Context) {
+	var req PackagingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	var hierarchy calculator.Packaging
+	if req.Packaging != nil {
+		hierarchy = *req.Packaging
+	} else {
+		ps, _ := h.packaging(c)
+		hierarchy = ps.GetPackaging()
+	}
+	if err := hierarchy.Validate(); err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+
+	packs, ok := h.calculationPacks(c, req.PackSizes)
+	if !ok {
+		return
+	}
+	calc, ok := h.calculatorFor(c, packs, req.Amount, req.Constraints)
+	if !ok {
+		return
+	}
+	result, err := calc.CalculateFulfilment(req.Amount, req.Constraints, req.Fulfilment)
+	if err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+	plan, err := calculator.PlanPackaging(result.Packs, hierarchy)
+	if err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+
+	resp := PackagingResponse{CalculateResponse: newCalculateResponse(result, packs), PackagingPlan: *plan}
+	h.auditCalculation(c, resp.CalculateResponse)
+	h.publishCalculation(c, resp.CalculateResponse)
+	h.recordDemand(resp.CalculateResponse)
+	c.JSON(http.StatusOK, resp)
+}
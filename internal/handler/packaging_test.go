@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+)
+
+func TestPackagingRoundTrip(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	packaging := calculator.Packaging{
+		Cartons:        []calculator.CartonType{{Name: "case", PackSize: 250, Capacity: 4}},
+		PalletCapacity: 10,
+	}
+	if w := doJSON(r, http.MethodPut, "/api/packaging", packaging); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got calculator.Packaging
+	json.Unmarshal(doJSON(r, http.MethodGet, "/api/packaging", nil).Body.Bytes(), &got)
+	if len(got.Cartons) != 1 || got.Cartons[0].Capacity != 4 || got.PalletCapacity != 10 {
+		t.Errorf("unexpected packaging %+v", got)
+	}
+
+	// 1250 items are 5 packs of 250 in two cases on one pallet
+	w := doJSON(r, http.MethodPost, "/api/calculate/packaging", PackagingRequest{Amount: 1250, PackSizes: []int{250}})
+	var resp PackagingResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.TotalPacks != 5 || resp.Cartons != 2 || resp.Pallets != 1 || resp.HandlingUnits != 1 {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if cases := resp.Units[0].Contents; len(cases) != 2 || cases[0].Contents[0].Quantity != 4 {
+		t.Errorf("unexpected tree %+v", resp.Units)
+	}
+}
+
+func TestCalculatePackagingOverride(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	// without stored packaging every pack ships loose
+	w := doJSON(r, http.MethodPost, "/api/calculate/packaging", PackagingRequest{Amount: 12001})
+	var resp PackagingResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.LoosePacks != 4 || resp.HandlingUnits != 4 {
+		t.Errorf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+
+	req := PackagingRequest{Amount: 12001, Packaging: &calculator.Packaging{
+		Cartons: []calculator.CartonType{{Name: "crate", PackSize: 5000, Capacity: 2}},
+	}}
+	json.Unmarshal(doJSON(r, http.MethodPost, "/api/calculate/packaging", req).Body.Bytes(), &resp)
+	if resp.Cartons != 1 || resp.LoosePacks != 2 || resp.HandlingUnits != 3 {
+		t.Errorf("expected the override to be used, got %+v", resp.PackagingPlan)
+	}
+}
+
+func TestPackagingInvalid(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	invalid := calculator.Packaging{Cartons: []calculator.CartonType{{Name: "case", PackSize: 250}}}
+	for _, w := range []*httptest.ResponseRecorder{
+		doJSON(r, http.MethodPut, "/api/packaging", invalid),
+		doJSON(r, http.MethodPost, "/api/calculate/packaging", PackagingRequest{Amount: 1, Packaging: &invalid}),
+	} {
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Code != CodeInvalidPackaging {
+			t.Errorf("expected 400 invalid_packaging, got %d %+v", w.Code, resp)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+)
+
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix namespaces the RFC 7807 type URI of every code
+const problemTypePrefix = "urn:pack-calculator:problem:"
+
+// Stable error codes. Clients branch on these, so they are never renamed.
+// Calculator errors use the codes of calculator.ErrorCode.
+const (
+	CodeInvalidJSON          = "invalid_json"
+	CodeValidationFailed     = calculator.CodeValidationFailed
+	CodeMissingAmount        = "missing_amount"
+	CodeInvalidAmount        = calculator.CodeInvalidAmount
+	CodeNoPackSizes          = calculator.CodeNoPackSizes
+	CodeInvalidPackSize      = calculator.CodeInvalidPackSize
+	CodeCalculationError     = calculator.CodeCalculationError
+	CodeAlreadyExists        = "already_exists"
+	CodeNotFound             = "not_found"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidAPIKey        = "invalid_api_key"
+	CodeInvalidToken         = "invalid_token"
+	CodeForbidden            = "forbidden"
+	CodeInvalidScope         = "invalid_scope"
+	CodeKeyGenerationError   = "key_generation_error"
+	CodeRateLimited          = "rate_limited"
+	CodeInvalidEvent         = "invalid_event"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeIdempotencyKeyInUse  = "idempotency_key_in_use"
+	CodeRequestTooLarge      = "request_too_large"
+	CodeProfileActive        = "profile_active"
+	CodeInvalidConstraint    = calculator.CodeInvalidConstraint
+	CodeInfeasible           = calculator.CodeInfeasible
+	CodeInvalidPack          = calculator.CodeInvalidPack
+	CodeInvalidPackaging     = calculator.CodeInvalidPackaging
+	CodeInvalidPriceList     = "invalid_price_list"
+	CodePriceUnavailable     = "price_unavailable"
+	CodeInvalidSimulation    = "invalid_simulation"
+)
+
+type problemDef struct {
+	Status int
+	Title  string
+}
+
+var problemCatalogue = map[string]problemDef{
+	CodeInvalidJSON:          {http.StatusBadRequest, "Request body is not valid JSON"},
+	CodeValidationFailed:     {http.StatusBadRequest, "Request failed validation"},
+	CodeMissingAmount:        {http.StatusBadRequest, "Amount is required"},
+	CodeInvalidAmount:        {http.StatusBadRequest, "Amount must be a positive integer"},
+	CodeNoPackSizes:          {http.StatusBadRequest, "No pack sizes configured"},
+	CodeInvalidPackSize:      {http.StatusBadRequest, "Pack size must be greater than zero"},
+	CodeCalculationError:     {http.StatusInternalServerError, "Calculation failed"},
+	CodeAlreadyExists:        {http.StatusConflict, "Resource already exists"},
+	CodeNotFound:             {http.StatusNotFound, "Resource not found"},
+	CodeUnauthorized:         {http.StatusUnauthorized, "Authentication required"},
+	CodeInvalidAPIKey:        {http.StatusUnauthorized, "API key is not valid"},
+	CodeInvalidToken:         {http.StatusUnauthorized, "Bearer token is not valid"},
+	CodeForbidden:            {http.StatusForbidden, "Insufficient scope"},
+	CodeInvalidScope:         {http.StatusBadRequest, "Unknown scope"},
+	CodeKeyGenerationError:   {http.StatusInternalServerError, "Could not generate API key"},
+	CodeRateLimited:          {http.StatusTooManyRequests, "Too many requests"},
+	CodeInvalidEvent:         {http.StatusBadRequest, "Unknown webhook event"},
+	CodeIdempotencyKeyReused: {http.StatusUnprocessableEntity, "Idempotency key reused with a different request"},
+	CodeIdempotencyKeyInUse:  {http.StatusConflict, "Idempotency key in use"},
+	CodeRequestTooLarge:      {http.StatusRequestEntityTooLarge, "Request body is too large"},
+	CodeProfileActive:        {http.StatusConflict, "Profile is active"},
+	CodeInvalidConstraint:    {http.StatusBadRequest, "Constraint is not valid"},
+	CodeInfeasible:           {http.StatusUnprocessableEntity, "No pack combination satisfies the constraints"},
+	CodeInvalidPack:          {http.StatusBadRequest, "Pack weight and dimensions must not be negative"},
+	CodeInvalidPackaging:     {http.StatusBadRequest, "Packaging hierarchy is not valid"},
+	CodeInvalidPriceList:     {http.StatusBadRequest, "Price list is not valid"},
+	CodePriceUnavailable:     {http.StatusUnprocessableEntity, "No price for the calculation"},
+	CodeInvalidSimulation:    {http.StatusBadRequest, "Simulation is not valid"},
+}
+
+// ErrorResponse is an RFC 7807 problem details document with a stable Code.
+type ErrorResponse struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+
+	// Infeasible names the constraint that ruled out every combination.
+	Infeasible *calculator.InfeasibleError `json:"infeasible,omitempty"`
+}
+
+// FieldError explains why a single request field was rejected.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Param  string `json:"param,omitempty"`
+}
+
+func newProblem(c *gin.Context, code, detail string) ErrorResponse {
+	def, ok := problemCatalogue[code]
+	if !ok {
+		def = problemDef{http.StatusInternalServerError, "Internal error"}
+	}
+
+	return ErrorResponse{
+		Type:     problemTypePrefix + code,
+		Title:    def.Title,
+		Status:   def.Status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+	}
+}
+
+// problem writes the catalogued problem for code and aborts the chain.
+func problem(c *gin.Context, code, detail string) {
+	writeProblem(c, newProblem(c, code, detail))
+}
+
+func writeProblem(c *gin.Context, p ErrorResponse) {
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+func calculatorProblem(c *gin.Context, err error) {
+	writeProblem(c, newCalculatorProblem(c, err))
+}
+
+// newCalculatorProblem is the problem of a calculator error
+func newCalculatorProblem(c *gin.Context, err error) ErrorResponse {
+	p := newProblem(c, calculator.ErrorCode(err), err.Error())
+	errors.As(err, &p.Infeasible)
+	return p
+}
+
+// bindJSON binds the body into obj, writing a problem with field level
+// details on failure. It returns false when the request was rejected.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		p := newProblem(c, CodeValidationFailed, "One or more fields are invalid")
+		for _, fe := range validationErrs {
+			p.Errors = append(p.Errors, FieldError{
+				Field:  fieldPath(fe.Namespace()),
+				Reason: fe.Tag(),
+				Param:  fe.Param(),
+			})
+		}
+		writeProblem(c, p)
+
+	case errors.As(err, &typeErr):
+		p := newProblem(c, CodeValidationFailed, "A field has the wrong type")
+		p.Errors = []FieldError{{
+			Field:  typeErr.Field,
+			Reason: "type",
+			Param:  typeErr.Type.String(),
+		}}
+		writeProblem(c, p)
+
+	case errors.Is(err, io.EOF):
+		problem(c, CodeInvalidJSON, "Request body is empty")
+
+	default:
+		problem(c, CodeInvalidJSON, err.Error())
+	}
+	return false
+}
+
+// fieldPath strips the struct name from a validator namespace,
+// "CalculateRequest.amount" becomes "amount"
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// init makes validation errors report JSON field names. It runs before any
+// request is bound, as the validator is shared by every engine in the process.
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return f.Name
+		}
+		return name
+	})
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemValidationDetails(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantCode   string
+		wantField  string
+		wantReason string
+	}{
+		{"zero amount", "/api/calculate", `{"amount": 0}`, CodeValidationFailed, "amount", "required"},
+		{"negative size", "/api/pack-sizes/add", `{"size": -1}`, CodeValidationFailed, "size", "gt"},
+		{"wrong type", "/api/calculate", `{"amount": "ten"}`, CodeValidationFailed, "amount", "type"},
+		{"malformed", "/api/calculate", `{"amount":`, CodeInvalidJSON, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != problemContentType {
+				t.Errorf("expected content type %s, got %s", problemContentType, ct)
+			}
+
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+
+			if resp.Code != tt.wantCode || resp.Type != problemTypePrefix+tt.wantCode || resp.Status != 400 {
+				t.Errorf("unexpected problem %+v", resp)
+			}
+			if tt.wantField == "" {
+				return
+			}
+			if len(resp.Errors) != 1 || resp.Errors[0].Field != tt.wantField || resp.Errors[0].Reason != tt.wantReason {
+				t.Errorf("unexpected field errors %+v", resp.Errors)
+			}
+		})
+	}
+}
+
+func TestProblemCatalogueIsComplete(t *testing.T) {
+	for code, def := range problemCatalogue {
+		if def.Status < 400 || def.Title == "" {
+			t.Errorf("code %s has incomplete definition %+v", code, def)
+		}
+	}
+}
+
+func TestCalculateInvalidPackSizeProblem(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	body := `{"amount": 10, "pack_sizes": [5, 0]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if w.Code != http.StatusBadRequest || resp.Code != CodeInvalidPackSize {
+		t.Errorf("expected 400 invalid_pack_size, got %d %s", w.Code, resp.Code)
+	}
+}
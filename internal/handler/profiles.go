@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// profileNamePattern keeps names usable in paths and query strings
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type CreateProfileRequest struct {
+	Name      string `json:"name" binding:"required"`
+	PackSizes []int  `json:"pack_sizes"`
+}
+
+type UpdateProfileRequest struct {
+	PackSizes []int `json:"pack_sizes"`
+}
+
+type ProfilesResponse struct {
+	Profiles []storage.Profile `json:"profiles"`
+	Active   string            `json:"active"`
+}
+
+// profiles returns the tenant's storage as a ProfileStore when it supports profiles
+func (h *Handler) profiles(c *gin.Context) (storage.ProfileStore, bool) {
+	ps, ok := h.stores(c).Storage.(storage.ProfileStore)
+	return ps, ok
+}
+
+func (h *Handler) ListProfiles(c *gin.Context) {
+	ps, _ := h.profiles(c)
+	profiles := ps.ListProfiles()
+
+	resp := ProfilesResponse{Profiles: profiles}
+	for _, p := range profiles {
+		if p.Active {
+			resp.Active = p.Name
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) GetProfile(c *gin.Context) {
+	ps, _ := h.profiles(c)
+	profile, ok := ps.GetProfile(c.Param("name"))
+	if !ok {
+		problem(c, CodeNotFound, "Profile not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *Handler) CreateProfile(c *gin.Context) {
+	var req CreateProfileRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !profileNamePattern.MatchString(req.Name) {
+		problem(c, CodeValidationFailed, "Profile name must be 1-64 lowercase letters, digits, '-' or '_'")
+		return
+	}
+	if !validPackSizes(c, req.PackSizes) {
+		return
+	}
+
+	ps, _ := h.profiles(c)
+	if !ps.CreateProfile(req.Name, req.PackSizes) {
+		problem(c, CodeAlreadyExists, "Profile already exists")
+		return
+	}
+
+	profile, _ := ps.GetProfile(req.Name)
+	c.JSON(http.StatusCreated, profile)
+}
+
+// UpdateProfile replaces a profile's pack sizes. Changes to the active profile
+// are recorded in the history like any other pack size change.
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !validPackSizes(c, req.PackSizes) {
+		return
+	}
+
+	ps, _ := h.profiles(c)
+	name := c.Param("name")
+	if !ps.UpdateProfile(name, req.PackSizes) {
+		problem(c, CodeNotFound, "Profile not found")
+		return
+	}
+
+	profile, _ := ps.GetProfile(name)
+	if profile.Active {
+		h.recordProfileChange(c, storage.ChangeSet, profile)
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *Handler) DeleteProfile(c *gin.Context) {
+	ps, _ := h.profiles(c)
+
+	err := ps.DeleteProfile(c.Param("name"))
+	switch {
+	case errors.Is(err, storage.ErrProfileNotFound):
+		problem(c, CodeNotFound, "Profile not found")
+	case errors.Is(err, storage.ErrProfileActive):
+		problem(c, CodeProfileActive, "Activate another profile before deleting this one")
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ActivateProfile makes the profile's pack sizes the live list in one step.
+func (h *Handler) ActivateProfile(c *gin.Context) {
+	ps, _ := h.profiles(c)
+	profile, ok := ps.ActivateProfile(c.Param("name"))
+	if !ok {
+		problem(c, CodeNotFound, "Profile not found")
+		return
+	}
+
+	h.recordProfileChange(c, storage.ChangeActivate, profile)
+	c.JSON(http.StatusOK, profile)
+}
+
+// profilePacks resolves the profile of a calculation, returning a problem
+// when it is unknown
+func (h *Handler) profilePacks(c *gin.Context, name string) ([]calculator.Pack, *ErrorResponse) {
+	ps, ok := h.profiles(c)
+	if !ok {
+		p := newProblem(c, CodeNotFound, "Profiles are not supported")
+		return nil, &p
+	}
+
+	profile, ok := ps.GetProfile(name)
+	if !ok {
+		p := newProblem(c, CodeNotFound, "Profile not found: "+name)
+		return nil, &p
+	}
+	return profile.Packs, nil
+}
+
+func (h *Handler) recordProfileChange(c *gin.Context, action string, profile storage.Profile) {
+	h.stores(c).History.RecordChange(storage.Change{
+		Action:    action,
+		PackSizes: profile.PackSizes,
+		Profile:   profile.Name,
+		Actor:     actor(c),
+	})
+}
+
+// validPacks writes a problem and returns false for an invalid definition
+func validPacks(c *gin.Context, packs []calculator.Pack) bool {
+	for _, p := range packs {
+		if err := p.Validate(); err != nil {
+			calculatorProblem(c, err)
+			return false
+		}
+	}
+	return true
+}
+
+// validPackSizes writes a problem and returns false for an empty or
+// non-positive list
+func validPackSizes(c *gin.Context, sizes []int) bool {
+	if len(sizes) == 0 {
+		problem(c, CodeNoPackSizes, "Pack sizes cannot be empty")
+		return false
+	}
+	for _, size := range sizes {
+		if size <= 0 {
+			problem(c, CodeInvalidPackSize, "All pack sizes must be greater than zero")
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func TestProfileLifecycle(t *testing.T) {
+	r, h := setupTestRouter()
+
+	w := doJSON(r, http.MethodPost, "/api/profiles", CreateProfileRequest{Name: "holiday", PackSizes: []int{23, 31, 53}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(r, http.MethodPost, "/api/profiles", CreateProfileRequest{Name: "holiday", PackSizes: []int{1}}); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for duplicate, got %d", w.Code)
+	}
+
+	var list ProfilesResponse
+	json.Unmarshal(doJSON(r, http.MethodGet, "/api/profiles", nil).Body.Bytes(), &list)
+	if len(list.Profiles) != 2 || list.Active != storage.DefaultProfile {
+		t.Errorf("unexpected profiles %+v", list)
+	}
+
+	w = doJSON(r, http.MethodPost, "/api/profiles/holiday/activate", nil)
+	var activated storage.Profile
+	json.Unmarshal(w.Body.Bytes(), &activated)
+	if w.Code != http.StatusOK || !activated.Active {
+		t.Fatalf("expected holiday to be active, got %d %+v", w.Code, activated)
+	}
+
+	var sizes PackSizesResponse
+	json.Unmarshal(doJSON(r, http.MethodGet, "/api/pack-sizes", nil).Body.Bytes(), &sizes)
+	if len(sizes.PackSizes) != 3 || sizes.PackSizes[0] != 23 {
+		t.Errorf("expected holiday sizes to be live, got %v", sizes.PackSizes)
+	}
+
+	changes := h.history.History()
+	if len(changes) != 1 || changes[0].Action != storage.ChangeActivate || changes[0].Profile != "holiday" {
+		t.Errorf("expected activation in history, got %+v", changes)
+	}
+
+	w = doJSON(r, http.MethodDelete, "/api/profiles/holiday", nil)
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusConflict || resp.Code != CodeProfileActive {
+		t.Errorf("expected 409 profile_active, got %d %s", w.Code, resp.Code)
+	}
+
+	if w := doJSON(r, http.MethodDelete, "/api/profiles/default", nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected inactive profile to be deleted, got %d", w.Code)
+	}
+	if w := doJSON(r, http.MethodGet, "/api/profiles/default", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	r, h := setupTestRouter()
+	doJSON(r, http.MethodPost, "/api/profiles", CreateProfileRequest{Name: "bulk", PackSizes: []int{10000}})
+
+	if w := doJSON(r, http.MethodPut, "/api/profiles/bulk", UpdateProfileRequest{PackSizes: []int{10000, 20000}}); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if len(h.history.History()) != 0 {
+		t.Error("inactive profile updates must not be recorded as live changes")
+	}
+
+	if w := doJSON(r, http.MethodPut, "/api/profiles/default", UpdateProfileRequest{PackSizes: []int{100}}); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if changes := h.history.History(); len(changes) != 1 || changes[0].Profile != storage.DefaultProfile {
+		t.Errorf("expected active profile update in history, got %+v", changes)
+	}
+
+	if w := doJSON(r, http.MethodPut, "/api/profiles/missing", UpdateProfileRequest{PackSizes: []int{1}}); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+	if w := doJSON(r, http.MethodPut, "/api/profiles/bulk", UpdateProfileRequest{PackSizes: []int{0}}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid size, got %d", w.Code)
+	}
+}
+
+func TestCreateProfileValidation(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		req  CreateProfileRequest
+		code string
+	}{
+		{CreateProfileRequest{Name: "Holiday 2024", PackSizes: []int{1}}, CodeValidationFailed},
+		{CreateProfileRequest{Name: "empty"}, CodeNoPackSizes},
+		{CreateProfileRequest{Name: "zero", PackSizes: []int{0}}, CodeInvalidPackSize},
+	}
+
+	for _, tt := range tests {
+		w := doJSON(r, http.MethodPost, "/api/profiles", tt.req)
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Code != tt.code {
+			t.Errorf("%+v: expected 400 %s, got %d %s", tt.req, tt.code, w.Code, resp.Code)
+		}
+	}
+}
+
+func TestCalculateWithProfile(t *testing.T) {
+	r, _ := setupTestRouter()
+	doJSON(r, http.MethodPost, "/api/profiles", CreateProfileRequest{Name: "holiday", PackSizes: []int{23, 31, 53}})
+
+	var resp CalculateResponse
+	w := doJSON(r, http.MethodGet, "/api/calculate?amount=263&profile=holiday", nil)
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.TotalItems != 263 || resp.PackSizes[0] != 23 {
+		t.Errorf("expected calculation against holiday, got %d %+v", w.Code, resp)
+	}
+
+	// the active profile is unchanged
+	json.Unmarshal(doJSON(r, http.MethodGet, "/api/calculate?amount=263", nil).Body.Bytes(), &resp)
+	if resp.TotalItems != 500 {
+		t.Errorf("expected default profile result 500, got %d", resp.TotalItems)
+	}
+
+	if w := doJSON(r, http.MethodPost, "/api/v2/calculate?profile=missing", CalculateRequest{Amount: 1}); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown profile, got %d", w.Code)
+	}
+	w = doJSON(r, http.MethodPost, "/api/v2/calculate?profile=holiday", CalculateRequest{Amount: 1, PackSizes: []int{1}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when combining profile and pack_sizes, got %d", w.Code)
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/pricing"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// QuoteRequest calculates an order and prices it. PriceList names the list to
+// use; otherwise the one in effect at At, default now, in Currency is used.
+type QuoteRequest struct {
+	Amount      int                    `json:"amount" binding:"required,gt=0"`
+	PackSizes   []int                  `json:"pack_sizes,omitempty"`
+	Constraints calculator.Constraints `json:"constraints,omitempty"`
+	Currency    string                 `json:"currency,omitempty"`
+	PriceList   string                 `json:"price_list,omitempty"`
+	At          *time.Time             `json:"at,omitempty"`
+	calculator.Fulfilment
+}
+
+// QuoteResponse is the calculation followed by its price.
+type QuoteResponse struct {
+	CalculateResponse
+	Quote pricing.Quote `json:"quote"`
+}
+
+type PriceListsResponse struct {
+	PriceLists []pricing.PriceList `json:"price_lists"`
+}
+
+// prices returns the tenant's storage as a PriceStore when it keeps price lists
+func (h *Handler) prices(c *gin.Context) (storage.PriceStore, bool) {
+	ps, ok := h.stores(c).Storage.(storage.PriceStore)
+	return ps, ok
+}
+
+// pricingProblem maps pricing sentinel errors to codes:
+//
+//	pricing.ErrInvalidPriceList -> invalid_price_list (400)
+//	pricing.ErrNoPriceList      -> price_unavailable  (422)
+//	pricing.ErrMissingPrice     -> price_unavailable  (422)
+func pricingProblem(c *gin.Context, err error) {
+	code := CodeCalculationError
+	switch {
+	case errors.Is(err, pricing.ErrInvalidPriceList):
+		code = CodeInvalidPriceList
+	case errors.Is(err, pricing.ErrNoPriceList), errors.Is(err, pricing.ErrMissingPrice):
+		code = CodePriceUnavailable
+	}
+	problem(c, code, err.Error())
+}
+
+func (h *Handler) ListPriceLists(c *gin.Context) {
+	ps, _ := h.prices(c)
+	c.JSON(http.StatusOK, PriceListsResponse{PriceLists: ps.ListPriceLists()})
+}
+
+func (h *Handler) GetPriceList(c *gin.Context) {
+	ps, _ := h.prices(c)
+	pl, ok := ps.GetPriceList(c.Param("id"))
+	if !ok {
+		problem(c, CodeNotFound, "Price list not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, pl)
+}
+
+// PutPriceList creates or replaces the price list named in the path.
+func (h *Handler) PutPriceList(c *gin.Context) {
+	var pl pricing.PriceList
+	if !bindJSON(c, &pl) {
+		return
+	}
+	pl.ID = c.Param("id")
+	if !profileNamePattern.MatchString(pl.ID) {
+		problem(c, CodeValidationFailed, "Price list id must be 1-64 lowercase letters, digits, '-' or '_'")
+		return
+	}
+	if err := pl.Validate(); err != nil {
+		pricingProblem(c, err)
+		return
+	}
+
+	ps, _ := h.prices(c)
+	status := http.StatusOK
+	if ps.PutPriceList(pl) {
+		status = http.StatusCreated
+	}
+	c.JSON(status, pl)
+}
+
+func (h *Handler) DeletePriceList(c *gin.Context) {
+	ps, _ := h.prices(c)
+	if !ps.DeletePriceList(c.Param("id")) {
+		problem(c, CodeNotFound, "Price list not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Quote calculates the packs for an order and prices them with a price list.
+func (h *Handler) Quote(c *gin.Context) {
+	var req QuoteRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	pl, ok := h.quotePriceList(c, req)
+	if !ok {
+		return
+	}
+	packs, ok := h.calculationPacks(c, req.PackSizes)
+	if !ok {
+		return
+	}
+	calc, ok := h.calculatorFor(c, packs, req.Amount, req.Constraints)
+	if !ok {
+		return
+	}
+	result, err := calc.CalculateFulfilment(req.Amount, req.Constraints, req.Fulfilment)
+	if err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+	quote, err := pricing.NewQuote(pl, result)
+	if err != nil {
+		pricingProblem(c, err)
+		return
+	}
+
+	resp := QuoteResponse{CalculateResponse: newCalculateResponse(result, packs), Quote: *quote}
+	h.auditCalculation(c, resp.CalculateResponse)
+	h.publishCalculation(c, resp.CalculateResponse)
+	h.recordDemand(resp.CalculateResponse)
+	c.JSON(http.StatusOK, resp)
+}
+
+// quotePriceList picks the named price list, or the one in effect. A named
+// list must be in effect too.
+func (h *Handler) quotePriceList(c *gin.Context, req QuoteRequest) (pricing.PriceList, bool) {
+	ps, _ := h.prices(c)
+
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+
+	if req.PriceList != "" {
+		pl, ok := ps.GetPriceList(req.PriceList)
+		if !ok {
+			problem(c, CodePriceUnavailable, "Price list "+req.PriceList+" not found")
+			return pricing.PriceList{}, false
+		}
+		if req.Currency != "" && req.Currency != pl.Currency {
+			problem(c, CodeValidationFailed, "Price list "+pl.ID+" is in "+pl.Currency+", not "+req.Currency)
+			return pricing.PriceList{}, false
+		}
+		if !pl.EffectiveAt(at) {
+			problem(c, CodePriceUnavailable, "Price list "+pl.ID+" is not in effect at "+at.UTC().Format(time.RFC3339))
+			return pricing.PriceList{}, false
+		}
+		return pl, true
+	}
+
+	pl, err := pricing.Select(ps.ListPriceLists(), req.Currency, at)
+	if err != nil {
+		pricingProblem(c, err)
+		return pricing.PriceList{}, false
+	}
+	return pl, true
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/willianbsanches13/pack-calculator/internal/pricing"
+)
+
+const standardPrices = `{
+	"currency": "EUR",
+	"effective_from": "2026-01-01T00:00:00Z",
+	"prices": {"250": 1, "500": "1.90", "1000": "3.60", "2000": "7.00", "5000": "16.50"},
+	"volume_discounts": [{"min_packs": 2, "percent": 10}]
+}`
+
+func TestPriceListCRUD(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	if w := doJSON(r, http.MethodPut, "/api/price-lists/standard", json.RawMessage(standardPrices)); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(r, http.MethodPut, "/api/price-lists/standard", json.RawMessage(standardPrices)); w.Code != http.StatusOK {
+		t.Errorf("expected 200 on replace, got %d", w.Code)
+	}
+
+	var pl pricing.PriceList
+	w := doJSON(r, http.MethodGet, "/api/price-lists/standard", nil)
+	json.Unmarshal(w.Body.Bytes(), &pl)
+	if w.Code != http.StatusOK || pl.ID != "standard" || !pl.Prices[500].Equal(decimal.RequireFromString("1.9")) {
+		t.Fatalf("unexpected price list %d %s", w.Code, w.Body.String())
+	}
+
+	var list PriceListsResponse
+	json.Unmarshal(doJSON(r, http.MethodGet, "/api/price-lists", nil).Body.Bytes(), &list)
+	if len(list.PriceLists) != 1 {
+		t.Errorf("expected one price list, got %+v", list)
+	}
+
+	if w := doJSON(r, http.MethodDelete, "/api/price-lists/standard", nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w := doJSON(r, http.MethodGet, "/api/price-lists/standard", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestPutPriceListInvalid(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		path string
+		body string
+		code string
+	}{
+		{"/api/price-lists/standard", `{"currency": "euro", "effective_from": "2026-01-01T00:00:00Z", "prices": {"250": 1}}`, CodeInvalidPriceList},
+		{"/api/price-lists/standard", `{"currency": "EUR", "effective_from": "2026-01-01T00:00:00Z", "prices": {"250": -1}}`, CodeInvalidPriceList},
+		{"/api/price-lists/Standard", standardPrices, CodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		var resp ErrorResponse
+		w := doJSON(r, http.MethodPut, tt.path, json.RawMessage(tt.body))
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Code != tt.code {
+			t.Errorf("%s %s: expected 400 %s, got %d %+v", tt.path, tt.body, tt.code, w.Code, resp)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	r, _ := setupTestRouter()
+	doJSON(r, http.MethodPut, "/api/price-lists/standard", json.RawMessage(standardPrices))
+
+	w := doJSON(r, http.MethodPost, "/api/quote", QuoteRequest{Amount: 12001})
+	var resp QuoteResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.TotalItems != 12250 {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+
+	// 2 x 16.50 less 10%, 7.00 and 1.00; 249 of 12250 items are surplus
+	q := resp.Quote
+	if q.PriceListID != "standard" || len(q.Lines) != 3 || !q.Lines[0].Discount.Equal(decimal.RequireFromString("3.30")) {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+	for _, check := range []struct {
+		name      string
+		got, want string
+	}{
+		{"subtotal", q.Subtotal.String(), "41"},
+		{"discount", q.Discount.String(), "3.3"},
+		{"total", q.Total.String(), "37.7"},
+		{"surplus price", q.SurplusPrice.String(), "0.77"},
+	} {
+		if check.got != check.want {
+			t.Errorf("%s: got %s, want %s", check.name, check.got, check.want)
+		}
+	}
+	if q.SurplusItems != 249 {
+		t.Errorf("expected 249 surplus items, got %d", q.SurplusItems)
+	}
+}
+
+func TestQuoteSelectsPriceList(t *testing.T) {
+	r, _ := setupTestRouter()
+	doJSON(r, http.MethodPut, "/api/price-lists/standard", json.RawMessage(standardPrices))
+	doJSON(r, http.MethodPut, "/api/price-lists/summer", json.RawMessage(`{
+		"currency": "EUR",
+		"effective_from": "2026-06-01T00:00:00Z",
+		"effective_to": "2026-09-01T00:00:00Z",
+		"prices": {"250": "0.80"}
+	}`))
+	doJSON(r, http.MethodPut, "/api/price-lists/dollars", json.RawMessage(`{
+		"currency": "USD",
+		"effective_from": "2026-01-01T00:00:00Z",
+		"prices": {"250": "1.10"}
+	}`))
+
+	july := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		req  QuoteRequest
+		want string
+	}{
+		{"in effect", QuoteRequest{Amount: 1, PackSizes: []int{250}, Currency: "EUR", At: &july}, "summer"},
+		{"after it expires", QuoteRequest{Amount: 1, PackSizes: []int{250}, Currency: "EUR", At: &october}, "standard"},
+		{"by currency", QuoteRequest{Amount: 1, PackSizes: []int{250}, Currency: "USD", At: &july}, "dollars"},
+		{"by id", QuoteRequest{Amount: 1, PackSizes: []int{250}, PriceList: "standard", At: &july}, "standard"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp QuoteResponse
+			w := doJSON(r, http.MethodPost, "/api/quote", tt.req)
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if w.Code != http.StatusOK || resp.Quote.PriceListID != tt.want {
+				t.Errorf("expected %s, got %d %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestQuoteUnavailable(t *testing.T) {
+	r, _ := setupTestRouter()
+	past := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var resp ErrorResponse
+	w := doJSON(r, http.MethodPost, "/api/quote", QuoteRequest{Amount: 250})
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusUnprocessableEntity || resp.Code != CodePriceUnavailable {
+		t.Errorf("expected 422 price_unavailable without price lists, got %d %+v", w.Code, resp)
+	}
+	doJSON(r, http.MethodPut, "/api/price-lists/standard", json.RawMessage(standardPrices))
+	doJSON(r, http.MethodPut, "/api/price-lists/spring", json.RawMessage(`{
+		"currency": "EUR",
+		"effective_from": "2025-03-01T00:00:00Z",
+		"effective_to": "2025-06-01T00:00:00Z",
+		"prices": {"250": "0.90"}
+	}`))
+
+	tests := []struct {
+		name   string
+		req    QuoteRequest
+		status int
+		code   string
+	}{
+		{"expired by id", QuoteRequest{Amount: 250, PriceList: "spring"}, http.StatusUnprocessableEntity, CodePriceUnavailable},
+		{"by id before it takes effect", QuoteRequest{Amount: 250, PriceList: "standard", At: &past}, http.StatusUnprocessableEntity, CodePriceUnavailable},
+		{"before it takes effect", QuoteRequest{Amount: 250, At: &past}, http.StatusUnprocessableEntity, CodePriceUnavailable},
+		{"unknown id", QuoteRequest{Amount: 250, PriceList: "missing"}, http.StatusUnprocessableEntity, CodePriceUnavailable},
+		{"size without a price", QuoteRequest{Amount: 250, PackSizes: []int{300}}, http.StatusUnprocessableEntity, CodePriceUnavailable},
+		{"currency mismatch", QuoteRequest{Amount: 250, PriceList: "standard", Currency: "USD"}, http.StatusBadRequest, CodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ErrorResponse
+			w := doJSON(r, http.MethodPost, "/api/quote", tt.req)
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if w.Code != tt.status || resp.Code != tt.code {
+				t.Errorf("expected %d %s, got %d %+v", tt.status, tt.code, w.Code, resp)
+			}
+		})
+	}
+}
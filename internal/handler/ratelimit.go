@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+)
+
+// WithRateLimit enables per-client rate limiting.
+func WithRateLimit(l *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.limiter = l
+	}
+}
+
+// rateLimit charges one token per request. It must run after requireScope so
+// authenticated callers are limited by identity rather than IP.
+func (h *Handler) rateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.limiter == nil {
+			c.Next()
+			return
+		}
+
+		if p := h.takeTokens(c, 1); p != nil {
+			writeProblem(c, *p)
+			return
+		}
+		c.Next()
+	}
+}
+
+// WithMaxAmount sets the largest amount a calculation may order, by default
+// calculator.DefaultMaxAmount.
+func WithMaxAmount(n int) Option {
+	return func(h *Handler) {
+		h.maxAmount = n
+	}
+}
+
+// calculatorFor builds the calculator of packs, bounded by the maximum
+// amount, and charges for calculating amount under cons with it. It returns
+// false after writing a 400 or 429 response.
+func (h *Handler) calculatorFor(c *gin.Context, packs []calculator.Pack, amount int, cons calculator.Constraints) (*calculator.Calculator, bool) {
+	calc, p := h.newCalculator(c, packs, amount, cons)
+	if p != nil {
+		writeProblem(c, *p)
+		return nil, false
+	}
+	return calc, true
+}
+
+// newCalculator is calculatorFor returning the problem unwritten
+func (h *Handler) newCalculator(c *gin.Context, packs []calculator.Pack, amount int, cons calculator.Constraints) (*calculator.Calculator, *ErrorResponse) {
+	calc, err := calculator.NewWithPacks(packs)
+	if err != nil {
+		p := newCalculatorProblem(c, err)
+		return nil, &p
+	}
+	calc.SetMaxAmount(h.maxAmount)
+	if p := h.chargeCalculation(c, calc, amount, cons); p != nil {
+		return nil, p
+	}
+	return calc, nil
+}
+
+// chargeCalculation rejects amounts, pack sizes and minimums above the
+// maximum, then takes the amount based cost of the table the calculation
+// builds: the amount plus the largest pack, or plus the largest minimum.
+func (h *Handler) chargeCalculation(c *gin.Context, calc *calculator.Calculator, amount int, cons calculator.Constraints) *ErrorResponse {
+	size, err := calc.TableSize(amount, cons)
+	if err != nil {
+		p := newCalculatorProblem(c, err)
+		return &p
+	}
+	return h.chargeTable(c, size)
+}
+
+// chargeTable takes the amount based cost of a table of size totals
+func (h *Handler) chargeTable(c *gin.Context, size int) *ErrorResponse {
+	if h.limiter == nil {
+		return nil
+	}
+
+	cost := h.limiter.CalculationCost(size)
+	if cost == 0 {
+		return nil
+	}
+	return h.takeTokens(c, cost)
+}
+
+// takeTokens charges the caller's bucket for the route and sets the
+// RateLimit headers, returning the problem of a rejected request.
+func (h *Handler) takeTokens(c *gin.Context, cost int) *ErrorResponse {
+	route := c.Request.Method + " " + c.FullPath()
+	result := h.limiter.Allow(rateLimitClient(c), route, cost)
+
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		detail := "Too many requests, retry later"
+		if cost > result.Limit {
+			detail = fmt.Sprintf("This request costs %d tokens, more than the burst of %d; order fewer items", cost, result.Limit)
+		}
+		p := newProblem(c, CodeRateLimited, detail)
+		return &p
+	}
+	return nil
+}
+
+func rateLimitClient(c *gin.Context) string {
+	if _, ok := c.Get(principalKey); ok {
+		return tenantScope(c, actor(c))
+	}
+	return tenantScope(c, "ip:"+c.ClientIP())
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
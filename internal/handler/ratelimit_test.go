@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+	"github.com/willianbsanches13/pack-calculator/internal/simulation"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func setupRateLimitedRouter(cfg ratelimit.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := New(storage.NewMemoryStorage(), WithRateLimit(ratelimit.New(cfg, nil)))
+	h.RegisterRoutes(r)
+	return r
+}
+
+func TestRateLimitHeadersAnd429(t *testing.T) {
+	r := setupRateLimitedRouter(ratelimit.Config{Default: ratelimit.Limit{Rate: 0.001, Burst: 2}})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i, w.Code)
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("expected RateLimit-Limit 2, got %q", w.Header().Get("RateLimit-Limit"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected headers %v", w.Header())
+	}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Code != CodeRateLimited {
+		t.Errorf("expected code rate_limited, got %s", resp.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/calculate?amount=1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected other routes to have their own bucket, got %d", w.Code)
+	}
+}
+
+func TestRateLimitCalculationCost(t *testing.T) {
+	r := setupRateLimitedRouter(ratelimit.Config{
+		Default:        ratelimit.Limit{Rate: 0.001, Burst: 10},
+		AmountPerToken: 1000,
+	})
+
+	send := func(amount string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"amount": `+amount+`}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 1 base token + 8 for the amount and the largest pack of 5000 leaves a
+	// single token
+	if w := send("3000"); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if w := send("1000"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", w.Code)
+	}
+}
+
+func TestRateLimitCostAboveBurst(t *testing.T) {
+	r := setupRateLimitedRouter(ratelimit.Config{
+		Default:        ratelimit.Limit{Rate: 1, Burst: 10},
+		AmountPerToken: 1000,
+	})
+
+	w := doJSON(r, http.MethodPost, "/api/calculate", CalculateRequest{Amount: 50000})
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After for a cost above the burst, got %d %v", w.Code, w.Header())
+	}
+	// the rejected cost leaves the bucket for cheaper calculations
+	if w := doJSON(r, http.MethodPost, "/api/calculate", CalculateRequest{Amount: 3000}); w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestRateLimitChargesMinPacks(t *testing.T) {
+	r := setupRateLimitedRouter(ratelimit.Config{
+		Default:        ratelimit.Limit{Rate: 0.001, Burst: 10},
+		AmountPerToken: 1000,
+	})
+
+	// a minimum of 5x2000 makes the table 10001 long, 10 tokens plus the base
+	body := CalculateRequest{Amount: 1, Constraints: calculator.Constraints{MinPacks: map[int]int{2000: 5}}}
+	if w := doJSON(r, http.MethodPost, "/api/calculate", body); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the minimum to be charged, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRateLimitRoutes(t *testing.T) {
+	routes, err := ratelimit.ParseRoutes("GET /api/v2/pack-sizes=0.001:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := setupRateLimitedRouter(ratelimit.Config{Default: ratelimit.Limit{Rate: 0.001, Burst: 5}, Routes: routes})
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		if w := doJSON(r, http.MethodGet, "/api/v2/pack-sizes", nil); w.Code != want {
+			t.Errorf("request %d: expected %d, got %d", i, want, w.Code)
+		}
+	}
+	if w := doJSON(r, http.MethodGet, "/api/pack-sizes", nil); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "5" {
+		t.Errorf("expected the default limit elsewhere, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestMaxAmount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	New(storage.NewMemoryStorage(), WithMaxAmount(10000)).RegisterRoutes(r)
+
+	if w := doJSON(r, http.MethodPost, "/api/calculate", CalculateRequest{Amount: 10000}); w.Code != http.StatusOK {
+		t.Errorf("expected 200 at the maximum, got %d", w.Code)
+	}
+	w := doJSON(r, http.MethodGet, "/api/v2/calculate?amount=10001", nil)
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusBadRequest || resp.Code != CodeInvalidAmount {
+		t.Errorf("expected 400 invalid_amount above the maximum, got %d %s", w.Code, w.Body.String())
+	}
+
+	// a pack size sizes the table as much as the amount does
+	overrides := []struct {
+		path string
+		body interface{}
+	}{
+		{"/api/calculate", CalculateRequest{Amount: 1, PackSizes: []int{2000000000}}},
+		{"/api/calculate/shipments", ShipmentsRequest{Amount: 1, PackSizes: []int{2000000000}, ShipmentLimit: calculator.ShipmentLimit{MaxItems: 10}}},
+		{"/api/simulate", SimulationRequest{Amounts: []int{1}, Catalogues: []simulation.Catalogue{{Name: "huge", PackSizes: []int{2000000000}}}}},
+	}
+	for _, tt := range overrides {
+		w := doJSON(r, http.MethodPost, tt.path, tt.body)
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Code != CodeInvalidPackSize {
+			t.Errorf("%s: expected 400 invalid_pack_size above the maximum, got %d %s", tt.path, w.Code, w.Body.String())
+		}
+	}
+}
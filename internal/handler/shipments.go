@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+)
+
+type ShipmentsRequest struct {
+	Amount        int                      `json:"amount" binding:"required,gt=0"`
+	PackSizes     []int                    `json:"pack_sizes,omitempty"`
+	Constraints   calculator.Constraints   `json:"constraints,omitempty"`
+	ShipmentLimit calculator.ShipmentLimit `json:"shipment_limit"`
+	calculator.Fulfilment
+}
+
+// ShipmentsResponse is the calculation followed by its split into shipments.
+type ShipmentsResponse struct {
+	CalculateResponse
+	Shipments []calculator.Shipment `json:"shipments"`
+}
+
+// CalculateShipments calculates the packs for an order and splits them into
+// the fewest shipments within the per-shipment limit.
+func (h *Handler) CalculateShipments(c *gin.Context) {
+	var req ShipmentsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := req.ShipmentLimit.Validate(); err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+
+	packs, ok := h.calculationPacks(c, req.PackSizes)
+	if !ok {
+		return
+	}
+	calc, ok := h.calculatorFor(c, packs, req.Amount, req.Constraints)
+	if !ok {
+		return
+	}
+	result, err := calc.CalculateFulfilment(req.Amount, req.Constraints, req.Fulfilment)
+	if err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+	shipments, err := calc.SplitShipments(result, req.ShipmentLimit)
+	if err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+
+	resp := ShipmentsResponse{CalculateResponse: newCalculateResponse(result, packs), Shipments: shipments}
+	h.auditCalculation(c, resp.CalculateResponse)
+	h.publishCalculation(c, resp.CalculateResponse)
+	h.recordDemand(resp.CalculateResponse)
+	c.JSON(http.StatusOK, resp)
+}
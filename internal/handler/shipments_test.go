@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCalculateShipments(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	body := `{"amount": 12001, "shipment_limit": {"max_items": 5000}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/calculate/shipments", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ShipmentsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.TotalItems != 12250 || len(resp.Shipments) != 3 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	for _, s := range resp.Shipments {
+		if s.TotalItems > 5000 {
+			t.Errorf("shipment over the limit: %+v", s)
+		}
+	}
+}
+
+func TestCalculateShipmentsErrors(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{`{"amount": 12001, "shipment_limit": {}}`, http.StatusBadRequest, CodeInvalidConstraint},
+		{`{"amount": 12001, "shipment_limit": {"max_packs": -1}}`, http.StatusBadRequest, CodeInvalidConstraint},
+		{`{"amount": 0, "shipment_limit": {"max_packs": 1}}`, http.StatusBadRequest, CodeValidationFailed},
+		{`{"amount": 12001, "shipment_limit": {"max_items": 1000}}`, http.StatusUnprocessableEntity, CodeInfeasible},
+		{`{"amount": 20000, "pack_sizes": [1], "shipment_limit": {"max_items": 1}}`, http.StatusBadRequest, CodeInvalidConstraint},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate/shipments", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != tt.wantStatus || resp.Code != tt.wantCode {
+			t.Errorf("%s: expected %d %s, got %d %+v", tt.body, tt.wantStatus, tt.wantCode, w.Code, resp)
+		}
+		if tt.wantCode == CodeInfeasible && (resp.Infeasible == nil || resp.Infeasible.Constraint != "shipment_max_items") {
+			t.Errorf("expected the shipment limit to be named, got %+v", resp.Infeasible)
+		}
+	}
+}
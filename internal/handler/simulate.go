@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/simulation"
+)
+
+// SimulationRequest replays Amounts, or samples them from Distribution,
+// against the configured pack sizes and each of Catalogues.
+type SimulationRequest struct {
+	Amounts      []int                    `json:"amounts,omitempty"`
+	Distribution *simulation.Distribution `json:"distribution,omitempty"`
+	Catalogues   []simulation.Catalogue   `json:"catalogues" binding:"required,min=1"`
+}
+
+// Simulate compares catalogues on a demand. The configured pack sizes, or
+// those of ?profile=, are the baseline every catalogue is compared with.
+func (h *Handler) Simulate(c *gin.Context) {
+	var req SimulationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	amounts := req.Amounts
+	switch {
+	case (len(amounts) > 0) == (req.Distribution != nil):
+		problem(c, CodeInvalidSimulation, "Set either amounts or distribution")
+		return
+	case req.Distribution != nil:
+		// without a max, normal and lognormal draws stop at the largest order
+		// a calculation accepts
+		dist := *req.Distribution
+		if dist.Max == 0 {
+			dist.Max = h.maxAmount
+		}
+		sampled, err := dist.Sample()
+		if err != nil {
+			problem(c, CodeInvalidSimulation, err.Error())
+			return
+		}
+		amounts = sampled
+	}
+
+	packs, ok := h.calculationPacks(c, nil)
+	if !ok {
+		return
+	}
+	catalogues := append([]simulation.Catalogue{{Name: simulation.Baseline, PackSizes: calculator.Sizes(packs)}}, req.Catalogues...)
+
+	if !h.chargeSimulation(c, amounts, catalogues) {
+		return
+	}
+
+	report, err := simulation.Run(amounts, catalogues)
+	if errors.Is(err, simulation.ErrInvalidSimulation) {
+		problem(c, CodeInvalidSimulation, err.Error())
+		return
+	}
+	if err != nil {
+		calculatorProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// chargeSimulation rejects amounts and pack sizes above the maximum, then
+// takes the amount based cost of every order for each catalogue, as if each
+// were calculated on its own, plus the catalogue's largest pack its table
+// adds. It returns false after writing a 400 or 429 response.
+func (h *Handler) chargeSimulation(c *gin.Context, amounts []int, catalogues []simulation.Catalogue) bool {
+	if largest := slices.Max(amounts); largest > h.maxAmount {
+		problem(c, CodeInvalidAmount, fmt.Sprintf("Amount must not exceed %d", h.maxAmount))
+		return false
+	}
+	largestSizes := make([]int, len(catalogues))
+	for i, cat := range catalogues {
+		for _, size := range cat.PackSizes {
+			if size > h.maxAmount {
+				problem(c, CodeInvalidPackSize, fmt.Sprintf("Pack sizes must not exceed %d", h.maxAmount))
+				return false
+			}
+			largestSizes[i] = max(largestSizes[i], size)
+		}
+	}
+	if h.limiter == nil {
+		return true
+	}
+
+	total := 0
+	for _, amount := range amounts {
+		total += max(amount, 0)
+	}
+	cost := 0
+	for _, largest := range largestSizes {
+		cost += h.limiter.CalculationCost(total + largest)
+	}
+	if cost == 0 {
+		return true
+	}
+	if p := h.takeTokens(c, cost); p != nil {
+		writeProblem(c, *p)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+	"github.com/willianbsanches13/pack-calculator/internal/simulation"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+func TestSimulate(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	req := SimulationRequest{
+		Amounts:    []int{250, 251, 251, 12001},
+		Catalogues: []simulation.Catalogue{{Name: "proposed", PackSizes: []int{300, 600, 1200, 5000}}},
+	}
+	w := doJSON(r, http.MethodPost, "/api/simulate", req)
+	var report simulation.Report
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if w.Code != http.StatusOK || len(report.Catalogues) != 2 {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if base := report.Catalogues[0]; base.Name != simulation.Baseline || base.TotalOvership != 747 {
+		t.Errorf("expected the configured sizes as baseline, got %+v", base)
+	}
+	if other := report.Catalogues[1]; other.OvershipDelta != -500 || len(report.Differences) != 3 {
+		t.Errorf("unexpected comparison %+v", report)
+	}
+}
+
+func TestSimulateDistribution(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	req := SimulationRequest{
+		Distribution: &simulation.Distribution{Kind: simulation.LogNormal, Count: 200, Seed: 42, Mean: 3000, StdDev: 2500},
+		Catalogues:   []simulation.Catalogue{{Name: "proposed", PackSizes: []int{300, 600, 1200, 5000}}},
+	}
+	var first, second simulation.Report
+	json.Unmarshal(doJSON(r, http.MethodPost, "/api/simulate", req).Body.Bytes(), &first)
+	json.Unmarshal(doJSON(r, http.MethodPost, "/api/simulate", req).Body.Bytes(), &second)
+	if first.Orders != 200 || first.OrderedItems != second.OrderedItems || first.Catalogues[1].TotalPacks != second.Catalogues[1].TotalPacks {
+		t.Errorf("expected a seeded simulation to repeat, got %+v and %+v", first.Catalogues, second.Catalogues)
+	}
+}
+
+func TestSimulateInvalid(t *testing.T) {
+	r, _ := setupTestRouter()
+	proposed := []simulation.Catalogue{{Name: "proposed", PackSizes: []int{300}}}
+
+	tests := []struct {
+		name string
+		req  SimulationRequest
+		code string
+	}{
+		{"no demand", SimulationRequest{Catalogues: proposed}, CodeInvalidSimulation},
+		{"both amounts and distribution", SimulationRequest{Amounts: []int{1}, Distribution: &simulation.Distribution{Kind: simulation.Uniform, Count: 1, Min: 1, Max: 2}, Catalogues: proposed}, CodeInvalidSimulation},
+		{"unknown distribution", SimulationRequest{Distribution: &simulation.Distribution{Kind: "poisson", Count: 1}, Catalogues: proposed}, CodeInvalidSimulation},
+		{"baseline name taken", SimulationRequest{Amounts: []int{1}, Catalogues: []simulation.Catalogue{{Name: simulation.Baseline, PackSizes: []int{300}}}}, CodeInvalidSimulation},
+		{"no catalogues", SimulationRequest{Amounts: []int{1}}, CodeValidationFailed},
+		{"invalid pack size", SimulationRequest{Amounts: []int{1}, Catalogues: []simulation.Catalogue{{Name: "bad", PackSizes: []int{-1}}}}, CodeInvalidPackSize},
+		{"invalid amount", SimulationRequest{Amounts: []int{-5}, Catalogues: proposed}, CodeInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ErrorResponse
+			w := doJSON(r, http.MethodPost, "/api/simulate", tt.req)
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if w.Code != http.StatusBadRequest || resp.Code != tt.code {
+				t.Errorf("expected 400 %s, got %d %+v", tt.code, w.Code, resp)
+			}
+		})
+	}
+}
+
+func TestSimulateClampsToMaxAmount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	New(storage.NewMemoryStorage(), WithMaxAmount(10000)).RegisterRoutes(r)
+
+	// without a max, draws far above the maximum stop at it
+	req := SimulationRequest{
+		Distribution: &simulation.Distribution{Kind: simulation.Normal, Count: 3, Mean: 1e9},
+		Catalogues:   []simulation.Catalogue{{Name: "proposed", PackSizes: []int{300}}},
+	}
+	w := doJSON(r, http.MethodPost, "/api/simulate", req)
+	var report simulation.Report
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if w.Code != http.StatusOK || report.OrderedItems != 30000 {
+		t.Fatalf("expected 3 orders of 10000, got %d %s", w.Code, w.Body.String())
+	}
+
+	req.Distribution.Max = 20000
+	if w := doJSON(r, http.MethodPost, "/api/simulate", req); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an explicit max above the maximum, got %d", w.Code)
+	}
+}
+
+func TestSimulateChargesEveryOrder(t *testing.T) {
+	r := setupRateLimitedRouter(ratelimit.Config{
+		Default:        ratelimit.Limit{Rate: 0.001, Burst: 10},
+		AmountPerToken: 1000,
+	})
+	proposed := []simulation.Catalogue{{Name: "proposed", PackSizes: []int{300}}}
+
+	// 6000 items cost 11 tokens for the baseline, whose largest pack is
+	// 5000, and 6 for the proposal
+	w := doJSON(r, http.MethodPost, "/api/simulate", SimulationRequest{Amounts: []int{3000, 3000}, Catalogues: proposed})
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 above the burst, got %d", w.Code)
+	}
+	if w := doJSON(r, http.MethodPost, "/api/simulate", SimulationRequest{Amounts: []int{500, 500}, Catalogues: proposed}); w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const (
+	tenantHeader = "X-Tenant-ID"
+	tenantKey    = "tenant"
+)
+
+type TenantsResponse struct {
+	Tenants []string `json:"tenants"`
+}
+
+type TenantResponse struct {
+	ID string `json:"id"`
+}
+
+// WithTenants gives every tenant its own pack sizes, history and audit log.
+// The tenant comes from the credentials when they are bound to one, else from
+// the X-Tenant-ID header, and must be registered.
+func WithTenants(registry storage.TenantRegistry) Option {
+	return func(h *Handler) {
+		h.tenants = registry
+	}
+}
+
+// resolveTenant picks the request's tenant from the X-Tenant-ID header and
+// the credentials, by the rules of auth.ResolveTenant. It returns false after
+// writing a problem.
+func (h *Handler) resolveTenant(c *gin.Context) bool {
+	if h.tenants == nil {
+		return true
+	}
+
+	tenant, err := auth.ResolveTenant(c.Request.Context(), h.tenants, c.GetHeader(tenantHeader))
+	if err != nil {
+		authProblem(c, err)
+		return false
+	}
+
+	c.Set(tenantKey, tenant)
+	c.Request = c.Request.WithContext(auth.WithTenant(c.Request.Context(), tenant))
+	return true
+}
+
+func (h *Handler) ListTenants(c *gin.Context) {
+	c.JSON(http.StatusOK, TenantsResponse{Tenants: h.tenants.Tenants()})
+}
+
+// CreateTenant registers the tenant named in the path with fresh stores. It
+// is idempotent: an existing tenant keeps its stores and returns 200.
+func (h *Handler) CreateTenant(c *gin.Context) {
+	id := c.Param("id")
+	if !storage.ValidTenantID(id) {
+		problem(c, CodeValidationFailed, "Tenant ID must be 1-64 lowercase letters, digits, '-' or '_'")
+		return
+	}
+
+	status := http.StatusOK
+	if _, created := h.tenants.CreateTenant(id); created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, TenantResponse{ID: id})
+}
+
+// boundTenant returns the tenant the caller's credentials are bound to, if any
+func boundTenant(c *gin.Context) string {
+	if v, ok := c.Get(principalKey); ok {
+		return v.(auth.Principal).Tenant
+	}
+	return ""
+}
+
+// deploymentWide rejects tenant-bound credentials on routes that are shared by
+// every tenant, such as webhooks.
+func (h *Handler) deploymentWide() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.tenants != nil && boundTenant(c) != "" {
+			problem(c, CodeForbidden, "Credentials bound to a tenant cannot manage deployment-wide settings")
+			return
+		}
+		c.Next()
+	}
+}
+
+// stores returns the stores of the request's tenant
+func (h *Handler) stores(c *gin.Context) storage.TenantStores {
+	return h.storesFor(c.Request.Context())
+}
+
+func (h *Handler) storesFor(ctx context.Context) storage.TenantStores {
+	if h.tenants == nil {
+		return storage.TenantStores{Storage: h.storage, History: h.history, Audit: h.audit}
+	}
+
+	tenant := auth.TenantFrom(ctx)
+	if tenant == "" {
+		tenant = storage.DefaultTenant
+	}
+	// resolveTenant only lets registered tenants through
+	stores, _ := h.tenants.Tenant(tenant)
+	return stores
+}
+
+// tenantScope prefixes per-caller keys with the tenant so rate limits and
+// idempotency keys never span tenants
+func tenantScope(c *gin.Context, key string) string {
+	if tenant := c.GetString(tenantKey); tenant != "" {
+		return "tenant:" + tenant + "\x00" + key
+	}
+	return key
+}
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/auth"
+	"github.com/willianbsanches13/pack-calculator/internal/ratelimit"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// newTenantRegistry returns a registry with acme and globex registered
+func newTenantRegistry() *storage.MemoryTenantRegistry {
+	tenants := storage.NewMemoryTenantRegistry(func(string) storage.TenantStores {
+		return storage.TenantStores{
+			Storage: storage.NewMemoryStorage(),
+			History: storage.NewMemoryHistory(storage.DefaultHistoryLimit),
+			Audit:   storage.NewMemoryAuditLog(time.Hour, 100),
+		}
+	})
+	tenants.CreateTenant("acme")
+	tenants.CreateTenant("globex")
+	return tenants
+}
+
+func setupTenantRouter(opts ...Option) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	opts = append([]Option{WithTenants(newTenantRegistry()), WithAudit(storage.NewMemoryAuditLog(time.Hour, 100))}, opts...)
+
+	r := gin.New()
+	New(storage.NewMemoryStorage(), opts...).RegisterRoutes(r)
+	return r
+}
+
+func tenantRequest(r *gin.Engine, method, path, tenant, key string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if tenant != "" {
+		req.Header.Set(tenantHeader, tenant)
+	}
+	if key != "" {
+		req.Header.Set(apiKeyHeader, key)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func tenantPackSizes(t *testing.T, r *gin.Engine, tenant string) []int {
+	t.Helper()
+
+	w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", tenant, "", nil)
+	var resp PackSizesResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.PackSizes
+}
+
+func TestTenantsHaveSeparatePackSizesAndHistory(t *testing.T) {
+	r := setupTenantRouter()
+
+	w := tenantRequest(r, http.MethodPut, "/api/pack-sizes", "acme", "", PackSizesResponse{PackSizes: []int{10, 20}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if sizes := tenantPackSizes(t, r, "acme"); len(sizes) != 2 {
+		t.Errorf("expected acme's sizes, got %v", sizes)
+	}
+	for _, tenant := range []string{"globex", ""} {
+		if sizes := tenantPackSizes(t, r, tenant); len(sizes) != 5 {
+			t.Errorf("tenant %q sees another tenant's sizes: %v", tenant, sizes)
+		}
+	}
+
+	w = tenantRequest(r, http.MethodGet, "/api/pack-sizes/history", "globex", "", nil)
+	var history HistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if len(history.Changes) != 0 {
+		t.Errorf("expected empty history for globex, got %+v", history.Changes)
+	}
+}
+
+func TestTenantsHaveSeparateAuditLogs(t *testing.T) {
+	r := setupTenantRouter()
+
+	tenantRequest(r, http.MethodGet, "/api/calculate?amount=251", "acme", "", nil)
+
+	for tenant, want := range map[string]int{"acme": 1, "globex": 0, "": 0} {
+		w := tenantRequest(r, http.MethodGet, "/api/calculations", tenant, "", nil)
+		var resp CalculationsResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Calculations) != want {
+			t.Errorf("tenant %q: expected %d calculations, got %d", tenant, want, len(resp.Calculations))
+		}
+	}
+}
+
+func TestTenantsHaveSeparateRateLimits(t *testing.T) {
+	r := setupTenantRouter(WithRateLimit(ratelimit.New(ratelimit.Config{
+		Default: ratelimit.Limit{Rate: 0.001, Burst: 1},
+	}, nil)))
+
+	if w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", "acme", "", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", "acme", "", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected acme to be limited, got %d", w.Code)
+	}
+	if w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", "globex", "", nil); w.Code != http.StatusOK {
+		t.Errorf("expected globex to have its own budget, got %d", w.Code)
+	}
+}
+
+func TestTenantBoundKeyCannotReachOtherTenants(t *testing.T) {
+	keys := storage.NewMemoryAPIKeyStore()
+	keys.CreateAPIKey(storage.APIKey{
+		ID:      "admin",
+		KeyHash: auth.HashKey(testAdminKey),
+		Scopes:  []string{string(auth.ScopeAdmin)},
+	})
+	keys.CreateAPIKey(storage.APIKey{
+		ID:      "acme",
+		KeyHash: auth.HashKey("pk_acme"),
+		Scopes:  []string{string(auth.ScopeAdmin)},
+		Tenant:  "acme",
+	})
+	keys.CreateAPIKey(storage.APIKey{
+		ID:      "reader",
+		KeyHash: auth.HashKey("pk_reader"),
+		Scopes:  []string{string(auth.ScopePackSizesRead)},
+	})
+	r := setupTenantRouter(WithAPIKeys(keys))
+
+	// the bound tenant is used without a header
+	w := tenantRequest(r, http.MethodPut, "/api/pack-sizes", "", "pk_acme", PackSizesResponse{PackSizes: []int{7}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", "acme", testAdminKey, nil); !bytes.Contains(w.Body.Bytes(), []byte("[7]")) {
+		t.Errorf("expected the write to land in acme, got %s", w.Body.String())
+	}
+
+	rejected := []struct {
+		name, method, path, tenant, key string
+	}{
+		{"bound key naming another tenant", http.MethodGet, "/api/pack-sizes", "globex", "pk_acme"},
+		{"bound key writing another tenant", http.MethodPut, "/api/pack-sizes", "globex", "pk_acme"},
+		{"unbound non-admin choosing a tenant", http.MethodGet, "/api/pack-sizes", "acme", "pk_reader"},
+		{"bound key on admin routes naming another tenant", http.MethodGet, "/api/admin/api-keys", "globex", "pk_acme"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tenantRequest(r, tt.method, tt.path, tt.tenant, tt.key, PackSizesResponse{PackSizes: []int{1}})
+			if w.Code != http.StatusForbidden {
+				t.Errorf("expected 403, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+	if w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", "", testAdminKey, nil); bytes.Contains(w.Body.Bytes(), []byte("[1]")) {
+		t.Errorf("default tenant was modified: %s", w.Body.String())
+	}
+
+	// tenant admins only see and create their own keys
+	w = tenantRequest(r, http.MethodPost, "/api/admin/api-keys", "", "pk_acme", CreateAPIKeyRequest{Name: "x", Scopes: []string{"calculate"}, Tenant: "globex"})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 creating a key for another tenant, got %d", w.Code)
+	}
+	w = tenantRequest(r, http.MethodGet, "/api/admin/api-keys", "", "pk_acme", nil)
+	var list APIKeysResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Keys) != 1 || list.Keys[0].ID != "acme" {
+		t.Errorf("expected only acme's key, got %+v", list.Keys)
+	}
+	if w := tenantRequest(r, http.MethodDelete, "/api/admin/api-keys/admin", "", "pk_acme", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting another tenant's key, got %d", w.Code)
+	}
+}
+
+func TestTenantIDValidation(t *testing.T) {
+	r := setupTenantRouter()
+
+	w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", "../Acme", "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestUnregisteredTenantNotFound(t *testing.T) {
+	keys := storage.NewMemoryAPIKeyStore()
+	keys.CreateAPIKey(storage.APIKey{ID: "admin", KeyHash: auth.HashKey(testAdminKey), Scopes: []string{string(auth.ScopeAdmin)}})
+	r := setupTenantRouter(WithAPIKeys(keys))
+
+	w := tenantRequest(r, http.MethodGet, "/api/pack-sizes", "initech", testAdminKey, nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = tenantRequest(r, http.MethodPost, "/api/admin/api-keys", "", testAdminKey, CreateAPIKeyRequest{Name: "x", Scopes: []string{"calculate"}, Tenant: "initech"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 creating a key for an unregistered tenant, got %d", w.Code)
+	}
+}
+
+func TestCreateTenant(t *testing.T) {
+	r := setupTenantRouter()
+
+	for _, want := range []int{http.StatusCreated, http.StatusOK} {
+		if w := tenantRequest(r, http.MethodPut, "/api/tenants/initech", "", "", nil); w.Code != want {
+			t.Fatalf("expected %d, got %d: %s", want, w.Code, w.Body.String())
+		}
+	}
+	if w := tenantRequest(r, http.MethodPut, "/api/tenants/Initech!", "", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid ID, got %d", w.Code)
+	}
+	if sizes := tenantPackSizes(t, r, "initech"); len(sizes) != 5 {
+		t.Errorf("expected default sizes for a new tenant, got %v", sizes)
+	}
+
+	var resp TenantsResponse
+	json.Unmarshal(tenantRequest(r, http.MethodGet, "/api/tenants", "", "", nil).Body.Bytes(), &resp)
+	if len(resp.Tenants) != 4 {
+		t.Errorf("expected default, acme, globex and initech, got %v", resp.Tenants)
+	}
+}
+
+func TestGraphQLUsesTenantStores(t *testing.T) {
+	r := setupTenantRouter()
+
+	tenantRequest(r, http.MethodPost, "/graphql", "acme", "", GraphQLRequest{Query: "mutation { setPackSizes(packSizes: [3]) }"})
+
+	if sizes := tenantPackSizes(t, r, "acme"); len(sizes) != 1 || sizes[0] != 3 {
+		t.Errorf("expected acme's sizes to change, got %v", sizes)
+	}
+	if sizes := tenantPackSizes(t, r, ""); len(sizes) != 5 {
+		t.Errorf("default tenant was modified: %v", sizes)
+	}
+}
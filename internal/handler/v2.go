@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const apiVersionV2 = "2"
+
+// Envelope wraps every successful v2 response.
+type Envelope struct {
+	Data interface{}  `json:"data"`
+	Meta EnvelopeMeta `json:"meta"`
+}
+
+type EnvelopeMeta struct {
+	APIVersion string `json:"api_version"`
+}
+
+func respondV2(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, Envelope{
+		Data: data,
+		Meta: EnvelopeMeta{APIVersion: apiVersionV2},
+	})
+}
+
+// deprecated marks a v1 route and points clients at its v2 successor
+func deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+func (h *Handler) GetPackSizesV2(c *gin.Context) {
+	respondV2(c, http.StatusOK, h.packSizesResponse(c))
+}
+
+func (h *Handler) SetPackSizesV2(c *gin.Context) {
+	if !h.replacePackSizes(c) {
+		return
+	}
+	respondV2(c, http.StatusOK, h.packSizesResponse(c))
+}
+
+// PutPackSizeV2 adds the size from the path. It is idempotent: 201 when the
+// size is created, 200 when it already existed.
+func (h *Handler) PutPackSizeV2(c *gin.Context) {
+	size, ok := packSizeParam(c)
+	if !ok {
+		return
+	}
+
+	status := http.StatusOK
+	if h.stores(c).Storage.AddPackSize(size) {
+		status = http.StatusCreated
+	}
+
+	sizes := h.stores(c).Storage.GetPackSizes()
+	if status == http.StatusCreated {
+		h.recordChange(c, storage.ChangeAdd, size, sizes)
+	}
+	respondV2(c, status, PackSizesResponse{PackSizes: sizes})
+}
+
+func (h *Handler) DeletePackSizeV2(c *gin.Context) {
+	size, ok := packSizeParam(c)
+	if !ok {
+		return
+	}
+
+	if !h.stores(c).Storage.RemovePackSize(size) {
+		problem(c, CodeNotFound, "Pack size not found")
+		return
+	}
+
+	sizes := h.stores(c).Storage.GetPackSizes()
+	h.recordChange(c, storage.ChangeRemove, size, sizes)
+	respondV2(c, http.StatusOK, PackSizesResponse{PackSizes: sizes})
+}
+
+func (h *Handler) GetHistoryV2(c *gin.Context) {
+	respondV2(c, http.StatusOK, HistoryResponse{Changes: h.stores(c).History.History()})
+}
+
+func (h *Handler) CalculateV2(c *gin.Context) {
+	resp, ok := h.calculate(c)
+	if !ok {
+		return
+	}
+	respondV2(c, http.StatusOK, resp)
+}
+
+func packSizeParam(c *gin.Context) (int, bool) {
+	size, err := strconv.Atoi(c.Param("size"))
+	if err != nil || size <= 0 {
+		problem(c, CodeInvalidPackSize, "Pack size in the path must be a positive integer")
+		return 0, false
+	}
+	return size, true
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type packSizesEnvelope struct {
+	Data PackSizesResponse `json:"data"`
+	Meta EnvelopeMeta      `json:"meta"`
+}
+
+func TestV2PutPackSizeIsIdempotent(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	for i, want := range []int{http.StatusCreated, http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPut, "/api/v2/pack-sizes/750", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("request %d: expected status %d, got %d", i, want, w.Code)
+		}
+
+		var resp packSizesEnvelope
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Meta.APIVersion != "2" || len(resp.Data.PackSizes) != 6 {
+			t.Errorf("unexpected envelope %+v", resp)
+		}
+	}
+}
+
+func TestV2DeletePackSize(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v2/pack-sizes/250", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v2/pack-sizes/250", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v2/pack-sizes/abc", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestV2Calculate(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/calculate", bytes.NewBufferString(`{"amount": 251}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var resp struct {
+		Data CalculateResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Data.TotalItems != 500 || resp.Data.TotalPacks != 1 {
+		t.Errorf("unexpected result %+v", resp.Data)
+	}
+	if w.Header().Get("Deprecation") != "" {
+		t.Error("v2 routes must not be marked deprecated")
+	}
+}
+
+func TestV1DeprecationHeaders(t *testing.T) {
+	r, _ := setupTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Header().Get("Deprecation") != "true" {
+		t.Errorf("expected Deprecation header, got %q", w.Header().Get("Deprecation"))
+	}
+	if want := `</api/v2/pack-sizes>; rel="successor-version"`; w.Header().Get("Link") != want {
+		t.Errorf("expected Link %q, got %q", want, w.Header().Get("Link"))
+	}
+}
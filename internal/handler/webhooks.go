@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+	"github.com/willianbsanches13/pack-calculator/internal/webhook"
+)
+
+// WebhookRequest registers or replaces a webhook. Events defaults to
+// pack size changes only.
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events,omitempty"`
+}
+
+// CreateWebhookResponse is the only response that carries the signing secret.
+type CreateWebhookResponse struct {
+	storage.Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []storage.Webhook `json:"webhooks"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []webhook.Delivery `json:"deliveries"`
+}
+
+type DeadLettersResponse struct {
+	DeadLetters []webhook.DeadLetter `json:"dead_letters"`
+}
+
+// CalculationEvent is the data of calculation.completed webhook events.
+type CalculationEvent struct {
+	CalculateResponse
+	Actor  string `json:"actor"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// WithWebhooks enables the webhook API and calculation events.
+func WithWebhooks(d *webhook.Dispatcher) Option {
+	return func(h *Handler) {
+		h.webhooks = d
+	}
+}
+
+// publishCalculation notifies calculation.completed subscribers
+func (h *Handler) publishCalculation(c *gin.Context, resp CalculateResponse) {
+	if h.webhooks == nil {
+		return
+	}
+	h.webhooks.Publish(webhook.EventCalculationCompleted, CalculationEvent{
+		CalculateResponse: resp,
+		Actor:             actor(c),
+		Tenant:            c.GetString(tenantKey),
+	})
+}
+
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	c.JSON(http.StatusOK, WebhooksResponse{Webhooks: h.webhooks.Store().ListWebhooks()})
+}
+
+func (h *Handler) GetWebhook(c *gin.Context) {
+	hook, ok := h.webhooks.Store().GetWebhook(c.Param("id"))
+	if !ok {
+		problem(c, CodeNotFound, "Webhook not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	req, ok := bindWebhook(c)
+	if !ok {
+		return
+	}
+
+	id, err := webhook.NewID()
+	if err != nil {
+		problem(c, CodeKeyGenerationError, err.Error())
+		return
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		problem(c, CodeKeyGenerationError, err.Error())
+		return
+	}
+
+	hook := storage.Webhook{
+		ID:        id,
+		URL:       req.URL,
+		Events:    req.Events,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if !h.webhooks.Store().CreateWebhook(hook) {
+		problem(c, CodeAlreadyExists, "Webhook already exists")
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: hook, Secret: secret})
+}
+
+// UpdateWebhook replaces the URL and events but keeps the secret.
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	req, ok := bindWebhook(c)
+	if !ok {
+		return
+	}
+
+	hook, found := h.webhooks.Store().GetWebhook(c.Param("id"))
+	if !found {
+		problem(c, CodeNotFound, "Webhook not found")
+		return
+	}
+
+	hook.URL = req.URL
+	hook.Events = req.Events
+	if !h.webhooks.Store().UpdateWebhook(hook) {
+		problem(c, CodeNotFound, "Webhook not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	if !h.webhooks.Store().DeleteWebhook(c.Param("id")) {
+		problem(c, CodeNotFound, "Webhook not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := h.webhooks.Store().GetWebhook(id); !ok {
+		problem(c, CodeNotFound, "Webhook not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, DeliveriesResponse{Deliveries: h.webhooks.Deliveries(id)})
+}
+
+func (h *Handler) ListDeadLetters(c *gin.Context) {
+	c.JSON(http.StatusOK, DeadLettersResponse{DeadLetters: h.webhooks.DeadLetters()})
+}
+
+// RedeliverDeadLetter retries a dead letter in the background.
+func (h *Handler) RedeliverDeadLetter(c *gin.Context) {
+	if !h.webhooks.Redeliver(c.Param("id")) {
+		problem(c, CodeNotFound, "Dead letter or its webhook not found")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func bindWebhook(c *gin.Context) (WebhookRequest, bool) {
+	var req WebhookRequest
+	if !bindJSON(c, &req) {
+		return req, false
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problem(c, CodeValidationFailed, "url must be an absolute http or https URL")
+		return req, false
+	}
+
+	if len(req.Events) == 0 {
+		req.Events = []string{webhook.EventPackSizesChanged}
+	}
+	for _, e := range req.Events {
+		if _, err := webhook.ParseEvent(e); err != nil {
+			problem(c, CodeInvalidEvent, "Unknown event: "+e)
+			return req, false
+		}
+	}
+
+	return req, true
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+	"github.com/willianbsanches13/pack-calculator/internal/webhook"
+)
+
+func setupWebhookRouter(t *testing.T) (*gin.Engine, *webhook.Dispatcher) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	d := webhook.NewDispatcher(storage.NewMemoryWebhookStore(), webhook.Config{MaxAttempts: 1})
+	t.Cleanup(d.Close)
+
+	r := gin.New()
+	New(storage.NewMemoryStorage(), WithWebhooks(d)).RegisterRoutes(r)
+	return r, d
+}
+
+func doJSON(r *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookCRUD(t *testing.T) {
+	r, _ := setupWebhookRouter(t)
+
+	w := doJSON(r, http.MethodPost, "/api/webhooks", WebhookRequest{URL: "http://erp.local/hook"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created CreateWebhookResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Secret == "" || len(created.Events) != 1 || created.Events[0] != webhook.EventPackSizesChanged {
+		t.Errorf("unexpected webhook %+v", created)
+	}
+
+	w = doJSON(r, http.MethodPut, "/api/webhooks/"+created.ID, WebhookRequest{
+		URL:    "https://erp.local/v2",
+		Events: []string{webhook.EventCalculationCompleted},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	w = doJSON(r, http.MethodGet, "/api/webhooks/"+created.ID, nil)
+	if bytes.Contains(w.Body.Bytes(), []byte(created.Secret)) {
+		t.Error("secret must only be returned on creation")
+	}
+	var hook storage.Webhook
+	json.Unmarshal(w.Body.Bytes(), &hook)
+	if hook.URL != "https://erp.local/v2" || hook.Events[0] != webhook.EventCalculationCompleted {
+		t.Errorf("update not applied: %+v", hook)
+	}
+
+	var list WebhooksResponse
+	json.Unmarshal(doJSON(r, http.MethodGet, "/api/webhooks", nil).Body.Bytes(), &list)
+	if len(list.Webhooks) != 1 {
+		t.Errorf("expected 1 webhook, got %d", len(list.Webhooks))
+	}
+
+	if w := doJSON(r, http.MethodDelete, "/api/webhooks/"+created.ID, nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w := doJSON(r, http.MethodGet, "/api/webhooks/"+created.ID, nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestWebhookValidation(t *testing.T) {
+	r, _ := setupWebhookRouter(t)
+
+	tests := []struct {
+		req  WebhookRequest
+		code string
+	}{
+		{WebhookRequest{URL: "not a url"}, CodeValidationFailed},
+		{WebhookRequest{URL: "ftp://erp.local"}, CodeValidationFailed},
+		{WebhookRequest{URL: "http://erp.local", Events: []string{"order.shipped"}}, CodeInvalidEvent},
+	}
+
+	for _, tt := range tests {
+		w := doJSON(r, http.MethodPost, "/api/webhooks", tt.req)
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Code != tt.code {
+			t.Errorf("%+v: expected 400 %s, got %d %s", tt.req, tt.code, w.Code, resp.Code)
+		}
+	}
+}
+
+func TestWebhookCalculationEvent(t *testing.T) {
+	received := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+	}))
+	defer receiver.Close()
+
+	r, d := setupWebhookRouter(t)
+	doJSON(r, http.MethodPost, "/api/webhooks", WebhookRequest{
+		URL:    receiver.URL,
+		Events: []string{webhook.EventCalculationCompleted},
+	})
+
+	doJSON(r, http.MethodPost, "/api/v2/calculate", CalculateRequest{Amount: 251})
+
+	var body []byte
+	select {
+	case body = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("calculation event was not delivered")
+	}
+
+	var event webhook.Event
+	json.Unmarshal(body, &event)
+	var data CalculationEvent
+	json.Unmarshal(event.Data, &data)
+	if event.Type != webhook.EventCalculationCompleted || data.TotalItems != 500 || data.Actor != "anonymous" {
+		t.Errorf("unexpected event %s", body)
+	}
+
+	hookID := d.Store().ListWebhooks()[0].ID
+	deadline := time.Now().Add(2 * time.Second)
+	var deliveries DeliveriesResponse
+	for len(deliveries.Deliveries) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		json.Unmarshal(doJSON(r, http.MethodGet, "/api/webhooks/"+hookID+"/deliveries", nil).Body.Bytes(), &deliveries)
+	}
+	if len(deliveries.Deliveries) != 1 || !deliveries.Deliveries[0].Success {
+		t.Errorf("unexpected deliveries %+v", deliveries.Deliveries)
+	}
+}
+
+func TestWebhookDeadLetters(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	r, _ := setupWebhookRouter(t)
+	doJSON(r, http.MethodPost, "/api/webhooks", WebhookRequest{
+		URL:    receiver.URL,
+		Events: []string{webhook.EventCalculationCompleted},
+	})
+	doJSON(r, http.MethodGet, "/api/v2/calculate?amount=1", nil)
+
+	deadline := time.Now().Add(2 * time.Second)
+	var letters DeadLettersResponse
+	for len(letters.DeadLetters) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		json.Unmarshal(doJSON(r, http.MethodGet, "/api/webhooks/dead-letters", nil).Body.Bytes(), &letters)
+	}
+	if len(letters.DeadLetters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %+v", letters.DeadLetters)
+	}
+
+	path := "/api/webhooks/dead-letters/" + letters.DeadLetters[0].ID + "/redeliver"
+	if w := doJSON(r, http.MethodPost, path, nil); w.Code != http.StatusAccepted {
+		t.Errorf("expected 202, got %d", w.Code)
+	}
+	if w := doJSON(r, http.MethodPost, "/api/webhooks/dead-letters/missing/redeliver", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,183 @@
+// Package pricing quotes pack calculations against price lists using decimal
+// arithmetic, so amounts never pick up binary floating point errors.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+)
+
+var (
+	ErrInvalidPriceList = errors.New("invalid price list")
+	ErrNoPriceList      = errors.New("no price list in effect")
+	ErrMissingPrice     = errors.New("price list has no price for a pack size")
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// minorUnits lists the ISO 4217 currencies that do not use two decimals
+var minorUnits = map[string]int32{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3,
+	"OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0, "XAF": 0, "XOF": 0,
+}
+
+// Decimals is the number of decimals amounts in currency are rounded to.
+func Decimals(currency string) int32 {
+	if n, ok := minorUnits[currency]; ok {
+		return n
+	}
+	return 2
+}
+
+// PriceList prices packs in one currency from EffectiveFrom until
+// EffectiveTo, exclusive; a nil EffectiveTo never expires.
+type PriceList struct {
+	ID            string                  `json:"id"`
+	Currency      string                  `json:"currency"`
+	EffectiveFrom time.Time               `json:"effective_from"`
+	EffectiveTo   *time.Time              `json:"effective_to,omitempty"`
+	Prices        map[int]decimal.Decimal `json:"prices"`
+	Discounts     []VolumeDiscount        `json:"volume_discounts,omitempty"`
+}
+
+// VolumeDiscount takes Percent off a line of at least MinPacks packs. A zero
+// PackSize applies to every size; the largest tier a line reaches wins.
+type VolumeDiscount struct {
+	PackSize int             `json:"pack_size,omitempty"`
+	MinPacks int             `json:"min_packs"`
+	Percent  decimal.Decimal `json:"percent"`
+}
+
+func (pl PriceList) Validate() error {
+	if !currencyPattern.MatchString(pl.Currency) {
+		return fmt.Errorf("%w: currency must be an ISO 4217 code such as EUR", ErrInvalidPriceList)
+	}
+	if pl.EffectiveFrom.IsZero() {
+		return fmt.Errorf("%w: effective_from is required", ErrInvalidPriceList)
+	}
+	if pl.EffectiveTo != nil && !pl.EffectiveTo.After(pl.EffectiveFrom) {
+		return fmt.Errorf("%w: effective_to must be after effective_from", ErrInvalidPriceList)
+	}
+	if len(pl.Prices) == 0 {
+		return fmt.Errorf("%w: prices are required", ErrInvalidPriceList)
+	}
+	for size, price := range pl.Prices {
+		if size <= 0 || price.IsNegative() {
+			return fmt.Errorf("%w: price for pack size %d must not be negative", ErrInvalidPriceList, size)
+		}
+	}
+	hundred := decimal.NewFromInt(100)
+	for _, d := range pl.Discounts {
+		if d.PackSize < 0 || d.MinPacks <= 0 || d.Percent.IsNegative() || d.Percent.GreaterThan(hundred) {
+			return fmt.Errorf("%w: volume discounts need min_packs above zero and a percent from 0 to 100", ErrInvalidPriceList)
+		}
+	}
+	return nil
+}
+
+// EffectiveAt reports whether the list applies at t
+func (pl PriceList) EffectiveAt(t time.Time) bool {
+	return !t.Before(pl.EffectiveFrom) && (pl.EffectiveTo == nil || t.Before(*pl.EffectiveTo))
+}
+
+// Select returns the list in effect at t, optionally in one currency. When
+// several are, the one that took effect last wins.
+func Select(lists []PriceList, currency string, t time.Time) (PriceList, error) {
+	var best *PriceList
+	for i, pl := range lists {
+		if (currency != "" && pl.Currency != currency) || !pl.EffectiveAt(t) {
+			continue
+		}
+		if best == nil || pl.EffectiveFrom.After(best.EffectiveFrom) {
+			best = &lists[i]
+		}
+	}
+	if best == nil {
+		return PriceList{}, ErrNoPriceList
+	}
+	return *best, nil
+}
+
+// LineItem prices the packs of one size.
+type LineItem struct {
+	PackSize        int             `json:"pack_size"`
+	Quantity        int             `json:"quantity"`
+	UnitPrice       decimal.Decimal `json:"unit_price"`
+	Gross           decimal.Decimal `json:"gross"`
+	DiscountPercent decimal.Decimal `json:"discount_percent"`
+	Discount        decimal.Decimal `json:"discount"`
+	Total           decimal.Decimal `json:"total"`
+}
+
+// Quote prices a calculation. Subtotal is before discounts; SurplusPrice is
+// the share of Total paid for the items shipped beyond the order amount.
+type Quote struct {
+	PriceListID  string          `json:"price_list_id"`
+	Currency     string          `json:"currency"`
+	Lines        []LineItem      `json:"lines"`
+	Subtotal     decimal.Decimal `json:"subtotal"`
+	Discount     decimal.Decimal `json:"discount"`
+	Total        decimal.Decimal `json:"total"`
+	SurplusItems int             `json:"surplus_items"`
+	SurplusPrice decimal.Decimal `json:"surplus_price"`
+}
+
+// NewQuote prices the packs of a result, one line per size, largest first.
+// Lines round to the currency's decimals, so the totals add up exactly.
+func NewQuote(pl PriceList, result *calculator.CalculationResult) (*Quote, error) {
+	places := Decimals(pl.Currency)
+
+	sizes := make([]int, 0, len(result.Packs))
+	for size := range result.Packs {
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	q := &Quote{PriceListID: pl.ID, Currency: pl.Currency, Lines: []LineItem{}}
+	for _, size := range sizes {
+		price, ok := pl.Prices[size]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no price for pack size %d", ErrMissingPrice, pl.ID, size)
+		}
+
+		qty := result.Packs[size]
+		line := LineItem{
+			PackSize:        size,
+			Quantity:        qty,
+			UnitPrice:       price,
+			Gross:           price.Mul(decimal.NewFromInt(int64(qty))).Round(places),
+			DiscountPercent: pl.discount(size, qty),
+		}
+		line.Discount = line.Gross.Mul(line.DiscountPercent).Div(decimal.NewFromInt(100)).Round(places)
+		line.Total = line.Gross.Sub(line.Discount)
+
+		q.Lines = append(q.Lines, line)
+		q.Subtotal = q.Subtotal.Add(line.Gross)
+		q.Discount = q.Discount.Add(line.Discount)
+	}
+	q.Total = q.Subtotal.Sub(q.Discount)
+
+	q.SurplusItems = result.TotalItems - result.OrderAmount
+	if q.SurplusItems > 0 {
+		q.SurplusPrice = q.Total.Mul(decimal.NewFromInt(int64(q.SurplusItems))).
+			Div(decimal.NewFromInt(int64(result.TotalItems))).Round(places)
+	}
+	return q, nil
+}
+
+// discount returns the percent of the largest tier a line reaches
+func (pl PriceList) discount(size, qty int) decimal.Decimal {
+	best, percent := 0, decimal.Zero
+	for _, d := range pl.Discounts {
+		if (d.PackSize == 0 || d.PackSize == size) && qty >= d.MinPacks && d.MinPacks > best {
+			best, percent = d.MinPacks, d.Percent
+		}
+	}
+	return percent
+}
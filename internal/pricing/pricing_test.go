@@ -0,0 +1,172 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+)
+
+var jan1 = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func testList() PriceList {
+	return PriceList{
+		ID:            "standard",
+		Currency:      "EUR",
+		EffectiveFrom: jan1,
+		Prices:        map[int]decimal.Decimal{250: d("2.10"), 500: d("3.95"), 1000: d("7.30")},
+		Discounts: []VolumeDiscount{
+			{MinPacks: 10, Percent: d("5")},
+			{PackSize: 250, MinPacks: 20, Percent: d("12.5")},
+		},
+	}
+}
+
+func TestNewQuote(t *testing.T) {
+	calc, _ := calculator.New([]int{250, 500, 1000})
+	result, _ := calc.CalculateWithDetails(1251)
+
+	q, err := NewQuote(testList(), result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(q.Lines) != 2 || q.Lines[0].PackSize != 1000 || q.Lines[1].PackSize != 500 {
+		t.Fatalf("expected lines for 1000 and 500, got %+v", q.Lines)
+	}
+	if !q.Subtotal.Equal(d("11.25")) || !q.Discount.IsZero() || !q.Total.Equal(d("11.25")) {
+		t.Errorf("unexpected totals %+v", q)
+	}
+	// 249 of 1500 items over-shipped: 11.25 * 249 / 1500 = 1.8675
+	if q.SurplusItems != 249 || !q.SurplusPrice.Equal(d("1.87")) {
+		t.Errorf("expected 249 surplus items for 1.87, got %d for %s", q.SurplusItems, q.SurplusPrice)
+	}
+}
+
+func TestNewQuoteDiscounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		qty     int
+		percent string
+		total   string
+	}{
+		{"no tier", 9, "0", "18.90"},
+		{"any size tier", 10, "5", "19.95"},
+		{"size tier beats any size", 20, "12.5", "36.75"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &calculator.CalculationResult{
+				OrderAmount: tt.qty * 250,
+				Packs:       map[int]int{250: tt.qty},
+				TotalItems:  tt.qty * 250,
+				TotalPacks:  tt.qty,
+			}
+			q, err := NewQuote(testList(), result)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			line := q.Lines[0]
+			if !line.DiscountPercent.Equal(d(tt.percent)) || !q.Total.Equal(d(tt.total)) {
+				t.Errorf("got %s%% off, total %s; want %s%%, %s", line.DiscountPercent, q.Total, tt.percent, tt.total)
+			}
+			if !line.Gross.Sub(line.Discount).Equal(line.Total) || !q.Subtotal.Sub(q.Discount).Equal(q.Total) {
+				t.Errorf("totals do not add up: %+v", q)
+			}
+		})
+	}
+}
+
+func TestNewQuoteRoundsToCurrency(t *testing.T) {
+	pl := PriceList{ID: "yen", Currency: "JPY", EffectiveFrom: jan1, Prices: map[int]decimal.Decimal{3: d("33.4")}}
+	result := &calculator.CalculationResult{OrderAmount: 7, Packs: map[int]int{3: 3}, TotalItems: 9, TotalPacks: 3}
+
+	q, err := NewQuote(pl, result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !q.Total.Equal(d("100")) || !q.SurplusPrice.Equal(d("22")) {
+		t.Errorf("expected whole yen, got total %s and surplus %s", q.Total, q.SurplusPrice)
+	}
+}
+
+func TestNewQuoteMissingPrice(t *testing.T) {
+	result := &calculator.CalculationResult{OrderAmount: 2000, Packs: map[int]int{2000: 1}, TotalItems: 2000, TotalPacks: 1}
+
+	if _, err := NewQuote(testList(), result); !errors.Is(err, ErrMissingPrice) {
+		t.Errorf("expected ErrMissingPrice, got %v", err)
+	}
+}
+
+func TestPriceListValidate(t *testing.T) {
+	before := jan1.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		modify func(*PriceList)
+	}{
+		{"lowercase currency", func(pl *PriceList) { pl.Currency = "eur" }},
+		{"no effective date", func(pl *PriceList) { pl.EffectiveFrom = time.Time{} }},
+		{"ends before it starts", func(pl *PriceList) { pl.EffectiveTo = &before }},
+		{"no prices", func(pl *PriceList) { pl.Prices = nil }},
+		{"negative price", func(pl *PriceList) { pl.Prices[250] = d("-1") }},
+		{"zero pack size", func(pl *PriceList) { pl.Prices[0] = d("1") }},
+		{"discount over 100", func(pl *PriceList) { pl.Discounts[0].Percent = d("100.01") }},
+		{"discount without minimum", func(pl *PriceList) { pl.Discounts[0].MinPacks = 0 }},
+	}
+
+	if err := testList().Validate(); err != nil {
+		t.Fatalf("expected a valid list, got %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := testList()
+			tt.modify(&pl)
+			if err := pl.Validate(); !errors.Is(err, ErrInvalidPriceList) {
+				t.Errorf("expected ErrInvalidPriceList, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	feb1 := jan1.AddDate(0, 1, 0)
+	mar1 := jan1.AddDate(0, 2, 0)
+	lists := []PriceList{
+		{ID: "standard", Currency: "EUR", EffectiveFrom: jan1},
+		{ID: "february", Currency: "EUR", EffectiveFrom: feb1, EffectiveTo: &mar1},
+		{ID: "dollars", Currency: "USD", EffectiveFrom: jan1},
+	}
+
+	tests := []struct {
+		currency string
+		at       time.Time
+		want     string
+	}{
+		{"EUR", jan1, "standard"},
+		{"EUR", feb1.AddDate(0, 0, 3), "february"},
+		{"EUR", mar1, "standard"},
+		{"USD", feb1, "dollars"},
+		{"", feb1, "february"},
+	}
+
+	for _, tt := range tests {
+		got, err := Select(lists, tt.currency, tt.at)
+		if err != nil || got.ID != tt.want {
+			t.Errorf("%s at %s: got %q %v, want %q", tt.currency, tt.at.Format(time.DateOnly), got.ID, err, tt.want)
+		}
+	}
+
+	if _, err := Select(lists, "GBP", jan1); !errors.Is(err, ErrNoPriceList) {
+		t.Errorf("expected ErrNoPriceList, got %v", err)
+	}
+	if _, err := Select(lists, "EUR", jan1.Add(-time.Second)); !errors.Is(err, ErrNoPriceList) {
+		t.Errorf("expected ErrNoPriceList before any list, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package ratelimit implements token bucket rate limiting with pluggable bucket storage.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit describes a token bucket: Rate tokens are added per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result is the outcome of taking tokens from a bucket.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // zero when allowed
+}
+
+// Store keeps bucket state. Implementations must be safe for concurrent use.
+type Store interface {
+	Take(key string, limit Limit, cost int, now time.Time) Result
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// sweepEvery controls how often idle buckets are evicted from MemoryStore
+const sweepEvery = 1024
+
+// MemoryStore is the default in-process Store
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take removes cost tokens if available. A cost above the burst can never be
+// paid and is rejected, with RetryAfter the time until the bucket is full.
+func (s *MemoryStore) Take(key string, limit Limit, cost int, now time.Time) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+
+	result := Result{Limit: limit.Burst}
+	switch {
+	case cost > limit.Burst:
+		result.RetryAfter = durationFor(float64(limit.Burst)-b.tokens, limit.Rate)
+	case b.tokens >= float64(cost):
+		b.tokens -= float64(cost)
+		result.Allowed = true
+	default:
+		result.RetryAfter = durationFor(float64(cost)-b.tokens, limit.Rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = durationFor(float64(limit.Burst)-b.tokens, limit.Rate)
+	return result
+}
+
+// sweep drops buckets that would be full by now, they carry no state
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		missing := float64(b.limit.Burst) - b.tokens
+		if now.Sub(b.last) >= durationFor(missing, b.limit.Rate) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func durationFor(tokens, rate float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	if rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(math.Ceil(tokens / rate * float64(time.Second)))
+}
+
+// Config selects the limit for each route. Routes are keyed as "METHOD /path"
+// using the registered path pattern, e.g. "POST /api/calculate".
+type Config struct {
+	Default Limit
+	Routes  map[string]Limit
+
+	// AmountPerToken charges calculations one extra token per this many items
+	// ordered. Zero disables amount based cost.
+	AmountPerToken int
+}
+
+// ParseRoutes reads route limits written as "METHOD /path=rate:burst",
+// separated by semicolons, e.g. "POST /api/calculate=2:10;GET /api/v2/calculate=2:10".
+func ParseRoutes(spec string) (map[string]Limit, error) {
+	routes := make(map[string]Limit)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		rate, burst, hasBurst := strings.Cut(value, ":")
+		if !ok || !hasPath || !hasBurst || method == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("route limit %q must look like \"METHOD /path=rate:burst\"", entry)
+		}
+
+		var limit Limit
+		var err error
+		if limit.Rate, err = strconv.ParseFloat(rate, 64); err != nil || limit.Rate <= 0 {
+			return nil, fmt.Errorf("route limit %q: rate must be a positive number", entry)
+		}
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+			return nil, fmt.Errorf("route limit %q: burst must be a positive integer", entry)
+		}
+		routes[strings.ToUpper(method)+" "+path] = limit
+	}
+	return routes, nil
+}
+
+func (c Config) LimitFor(route string) Limit {
+	if limit, ok := c.Routes[route]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+// Limiter applies a Config against a Store, keeping one bucket per client and route.
+type Limiter struct {
+	cfg   Config
+	store Store
+	now   func() time.Time
+}
+
+// New creates a Limiter. A nil store uses a MemoryStore.
+func New(cfg Config, store Store) *Limiter {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Limiter{cfg: cfg, store: store, now: time.Now}
+}
+
+func (l *Limiter) Allow(client, route string, cost int) Result {
+	return l.store.Take(client+"|"+route, l.cfg.LimitFor(route), cost, l.now())
+}
+
+// CalculationCost is the extra cost of calculating the given amount.
+func (l *Limiter) CalculationCost(amount int) int {
+	if l.cfg.AmountPerToken <= 0 {
+		return 0
+	}
+	return amount / l.cfg.AmountPerToken
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 2; i++ {
+		if r := s.Take("a", limit, 1, now); !r.Allowed {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+
+	r := s.Take("a", limit, 1, now)
+	if r.Allowed {
+		t.Fatal("third request should be rejected")
+	}
+	if r.RetryAfter != time.Second {
+		t.Errorf("expected retry after 1s, got %v", r.RetryAfter)
+	}
+
+	if r := s.Take("b", limit, 1, now); !r.Allowed {
+		t.Error("other keys should have their own bucket")
+	}
+
+	if r := s.Take("a", limit, 1, now.Add(time.Second)); !r.Allowed {
+		t.Error("bucket should refill over time")
+	}
+}
+
+func TestMemoryStoreCostAboveBurstRejected(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 5}
+	now := time.Unix(0, 0)
+
+	r := s.Take("a", limit, 100, now)
+	if r.Allowed || r.Remaining != 5 {
+		t.Errorf("expected a cost above the burst to be rejected without draining, got %+v", r)
+	}
+
+	s.Take("a", limit, 5, now)
+	if r := s.Take("a", limit, 6, now); r.Allowed || r.RetryAfter != 5*time.Second {
+		t.Errorf("expected retry once the bucket is full, got %+v", r)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("POST /api/calculate=2:10; get /api/v2/calculate=0.5:4;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes["POST /api/calculate"] != (Limit{Rate: 2, Burst: 10}) || routes["GET /api/v2/calculate"] != (Limit{Rate: 0.5, Burst: 4}) || len(routes) != 2 {
+		t.Errorf("unexpected routes %v", routes)
+	}
+
+	for _, spec := range []string{"POST /api/calculate", "/api/calculate=1:1", "POST /api/calculate=0:1", "POST /api/calculate=1:x"} {
+		if _, err := ParseRoutes(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestLimiterRoutes(t *testing.T) {
+	l := New(Config{
+		Default:        Limit{Rate: 1, Burst: 1},
+		Routes:         map[string]Limit{"POST /api/calculate": {Rate: 1, Burst: 3}},
+		AmountPerToken: 1000,
+	}, nil)
+	l.now = func() time.Time { return time.Unix(0, 0) }
+
+	if r := l.Allow("c", "POST /api/calculate", 1); r.Limit != 3 {
+		t.Errorf("expected route limit 3, got %d", r.Limit)
+	}
+	if r := l.Allow("c", "GET /api/pack-sizes", 1); r.Limit != 1 {
+		t.Errorf("expected default limit 1, got %d", r.Limit)
+	}
+	if cost := l.CalculationCost(5500); cost != 5 {
+		t.Errorf("expected cost 5, got %d", cost)
+	}
+}
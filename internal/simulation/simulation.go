@@ -0,0 +1,240 @@
+// Package simulation replays order amounts against several pack size
+// catalogues to compare how much each over-ships and how many packs it needs.
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+)
+
+var ErrInvalidSimulation = errors.New("invalid simulation")
+
+// MaxAmounts caps the orders of one simulation, replayed or sampled
+const MaxAmounts = 100000
+
+// Distribution kinds
+const (
+	// Uniform draws whole amounts from Min to Max alike.
+	Uniform = "uniform"
+	// Normal draws around Mean with StdDev.
+	Normal = "normal"
+	// LogNormal draws a right-skewed demand with the given Mean and StdDev,
+	// typical of order sizes: many small orders and a few large ones.
+	LogNormal = "lognormal"
+)
+
+// Distribution samples Count order amounts. The same Seed always gives the
+// same amounts. Min and Max bound normal and lognormal draws when set.
+type Distribution struct {
+	Kind   string  `json:"kind"`
+	Count  int     `json:"count"`
+	Seed   uint64  `json:"seed"`
+	Min    int     `json:"min,omitempty"`
+	Max    int     `json:"max,omitempty"`
+	Mean   float64 `json:"mean,omitempty"`
+	StdDev float64 `json:"stddev,omitempty"`
+}
+
+func (d Distribution) Validate() error {
+	if d.Count <= 0 || d.Count > MaxAmounts {
+		return fmt.Errorf("%w: count must be from 1 to %d", ErrInvalidSimulation, MaxAmounts)
+	}
+	if d.Min < 0 || d.Max < 0 || (d.Max > 0 && d.Max < d.Min) {
+		return fmt.Errorf("%w: min and max must not be negative and max must not be below min", ErrInvalidSimulation)
+	}
+
+	switch d.Kind {
+	case Uniform:
+		if d.Min <= 0 || d.Max <= 0 {
+			return fmt.Errorf("%w: %s needs min and max", ErrInvalidSimulation, Uniform)
+		}
+	case Normal, LogNormal:
+		if d.Mean <= 0 || d.StdDev < 0 {
+			return fmt.Errorf("%w: %s needs a positive mean and a stddev that is not negative", ErrInvalidSimulation, d.Kind)
+		}
+	default:
+		return fmt.Errorf("%w: unknown distribution %q", ErrInvalidSimulation, d.Kind)
+	}
+	return nil
+}
+
+// Sample draws the amounts, rounding each to a whole amount of at least one.
+func (d Distribution) Sample() ([]int, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(d.Seed, 0))
+	draw := func() float64 {
+		switch d.Kind {
+		case Uniform:
+			return float64(d.Min + rng.IntN(d.Max-d.Min+1))
+		case Normal:
+			return d.Mean + d.StdDev*rng.NormFloat64()
+		}
+		// the parameters of the underlying normal giving this mean and stddev
+		sigma2 := math.Log1p(d.StdDev * d.StdDev / (d.Mean * d.Mean))
+		mu := math.Log(d.Mean) - sigma2/2
+		return math.Exp(mu + math.Sqrt(sigma2)*rng.NormFloat64())
+	}
+
+	lo, hi := max(1, d.Min), math.MaxInt32
+	if d.Max > 0 {
+		hi = d.Max
+	}
+	amounts := make([]int, d.Count)
+	for i := range amounts {
+		amounts[i] = int(min(max(math.Round(draw()), float64(lo)), float64(hi)))
+	}
+	return amounts, nil
+}
+
+// Baseline names the configured pack sizes when the API and the command
+// compare catalogues with them
+const Baseline = "current"
+
+// Catalogue is a named set of pack sizes to simulate.
+type Catalogue struct {
+	Name      string `json:"name"`
+	PackSizes []int  `json:"pack_sizes"`
+}
+
+// Summary totals one catalogue over every order. The deltas compare it with
+// the first catalogue, the baseline.
+type Summary struct {
+	Name           string  `json:"name"`
+	PackSizes      []int   `json:"pack_sizes"`
+	TotalItems     int     `json:"total_items"`
+	TotalPacks     int     `json:"total_packs"`
+	TotalOvership  int     `json:"total_overship"`
+	OvershipRate   float64 `json:"overship_rate"`
+	OvershipDelta  int     `json:"overship_delta"`
+	PacksDelta     int     `json:"packs_delta"`
+	AmountsChanged int     `json:"amounts_changed"`
+}
+
+// Outcome is the result of one catalogue for one amount.
+type Outcome struct {
+	Catalogue  string      `json:"catalogue"`
+	Packs      map[int]int `json:"packs"`
+	TotalItems int         `json:"total_items"`
+	TotalPacks int         `json:"total_packs"`
+	Overship   int         `json:"overship"`
+}
+
+// Difference lists the outcomes of an amount some catalogue packs differently
+// from the baseline. Orders counts how often the amount occurs.
+type Difference struct {
+	Amount   int       `json:"amount"`
+	Orders   int       `json:"orders"`
+	Outcomes []Outcome `json:"outcomes"`
+}
+
+// Report compares the catalogues over the orders, with the differing amounts
+// in ascending order.
+type Report struct {
+	Orders       int          `json:"orders"`
+	OrderedItems int          `json:"ordered_items"`
+	Catalogues   []Summary    `json:"catalogues"`
+	Differences  []Difference `json:"differences"`
+}
+
+// Run calculates every amount with every catalogue. The first catalogue is
+// the baseline the others are compared with; each distinct amount is
+// calculated once per catalogue.
+func Run(amounts []int, catalogues []Catalogue) (*Report, error) {
+	if len(amounts) == 0 || len(amounts) > MaxAmounts {
+		return nil, fmt.Errorf("%w: between 1 and %d amounts are needed", ErrInvalidSimulation, MaxAmounts)
+	}
+	if len(catalogues) < 2 {
+		return nil, fmt.Errorf("%w: at least two catalogues are needed", ErrInvalidSimulation)
+	}
+	seen := make(map[string]bool, len(catalogues))
+	for _, cat := range catalogues {
+		if cat.Name == "" || seen[cat.Name] {
+			return nil, fmt.Errorf("%w: catalogue names must be set and unique", ErrInvalidSimulation)
+		}
+		seen[cat.Name] = true
+	}
+
+	orders := make(map[int]int)
+	report := &Report{Orders: len(amounts)}
+	for _, amount := range amounts {
+		if amount <= 0 {
+			return nil, calculator.ErrInvalidAmount
+		}
+		orders[amount]++
+		report.OrderedItems += amount
+	}
+	distinct := make([]int, 0, len(orders))
+	for amount := range orders {
+		distinct = append(distinct, amount)
+	}
+	slices.Sort(distinct)
+
+	results := make([][]*calculator.CalculationResult, len(catalogues))
+	for i, cat := range catalogues {
+		calc, err := calculator.New(cat.PackSizes)
+		if err != nil {
+			return nil, fmt.Errorf("catalogue %s: %w", cat.Name, err)
+		}
+		if results[i], err = calc.CalculateMany(distinct); err != nil {
+			return nil, err
+		}
+
+		s := Summary{Name: cat.Name, PackSizes: calc.GetPackSizes()}
+		slices.Sort(s.PackSizes)
+		for j, r := range results[i] {
+			n := orders[distinct[j]]
+			s.TotalItems += n * r.TotalItems
+			s.TotalPacks += n * r.TotalPacks
+			s.TotalOvership += n * r.Overship()
+			if i > 0 && differs(r, results[0][j]) {
+				s.AmountsChanged++
+			}
+		}
+		s.OvershipRate = float64(s.TotalOvership) / float64(report.OrderedItems)
+		report.Catalogues = append(report.Catalogues, s)
+	}
+
+	baseline := report.Catalogues[0]
+	for i := range report.Catalogues {
+		report.Catalogues[i].OvershipDelta = report.Catalogues[i].TotalOvership - baseline.TotalOvership
+		report.Catalogues[i].PacksDelta = report.Catalogues[i].TotalPacks - baseline.TotalPacks
+	}
+
+	report.Differences = []Difference{}
+	for j, amount := range distinct {
+		changed := false
+		for i := 1; i < len(catalogues); i++ {
+			changed = changed || differs(results[i][j], results[0][j])
+		}
+		if !changed {
+			continue
+		}
+
+		d := Difference{Amount: amount, Orders: orders[amount]}
+		for i, cat := range catalogues {
+			r := results[i][j]
+			d.Outcomes = append(d.Outcomes, Outcome{
+				Catalogue:  cat.Name,
+				Packs:      r.Packs,
+				TotalItems: r.TotalItems,
+				TotalPacks: r.TotalPacks,
+				Overship:   r.Overship(),
+			})
+		}
+		report.Differences = append(report.Differences, d)
+	}
+	return report, nil
+}
+
+// differs reports whether two results ship a different total or pack count
+func differs(a, b *calculator.CalculationResult) bool {
+	return a.TotalItems != b.TotalItems || a.TotalPacks != b.TotalPacks
+}
@@ -0,0 +1,144 @@
+package simulation
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+)
+
+var (
+	current  = Catalogue{Name: "current", PackSizes: []int{250, 500, 1000, 2000, 5000}}
+	proposed = Catalogue{Name: "proposed", PackSizes: []int{300, 600, 1200, 5000}}
+)
+
+func TestRun(t *testing.T) {
+	report, err := Run([]int{250, 251, 251, 12001}, []Catalogue{current, proposed})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Orders != 4 || report.OrderedItems != 12753 {
+		t.Errorf("unexpected totals %+v", report)
+	}
+
+	// current: 250, 500, 500, 12250; proposed: 300, 300, 300, 12100
+	base, other := report.Catalogues[0], report.Catalogues[1]
+	if base.TotalItems != 13500 || base.TotalOvership != 747 || base.TotalPacks != 7 {
+		t.Errorf("unexpected baseline %+v", base)
+	}
+	if other.TotalItems != 13000 || other.TotalOvership != 247 || other.TotalPacks != 8 {
+		t.Errorf("unexpected proposed %+v", other)
+	}
+	if other.OvershipDelta != -500 || other.PacksDelta != 1 || other.AmountsChanged != 3 || base.OvershipDelta != 0 {
+		t.Errorf("unexpected deltas %+v", other)
+	}
+
+	if len(report.Differences) != 3 {
+		t.Fatalf("expected 3 differing amounts, got %+v", report.Differences)
+	}
+	d := report.Differences[1]
+	if d.Amount != 251 || d.Orders != 2 || d.Outcomes[0].Overship != 249 || d.Outcomes[1].Overship != 49 {
+		t.Errorf("unexpected difference %+v", d)
+	}
+}
+
+func TestRunSameCatalogue(t *testing.T) {
+	twin := Catalogue{Name: "twin", PackSizes: []int{5000, 2000, 1000, 500, 250}}
+
+	report, err := Run([]int{1, 12001, 499999}, []Catalogue{current, twin})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Differences) != 0 || report.Catalogues[1].AmountsChanged != 0 || report.Catalogues[1].OvershipDelta != 0 {
+		t.Errorf("expected no differences, got %+v", report)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		amounts    []int
+		catalogues []Catalogue
+		want       error
+	}{
+		{"no amounts", nil, []Catalogue{current, proposed}, ErrInvalidSimulation},
+		{"one catalogue", []int{1}, []Catalogue{current}, ErrInvalidSimulation},
+		{"duplicate names", []int{1}, []Catalogue{current, current}, ErrInvalidSimulation},
+		{"unnamed", []int{1}, []Catalogue{current, {PackSizes: []int{1}}}, ErrInvalidSimulation},
+		{"zero amount", []int{0}, []Catalogue{current, proposed}, calculator.ErrInvalidAmount},
+		{"empty catalogue", []int{1}, []Catalogue{current, {Name: "empty"}}, calculator.ErrNoPackSizes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Run(tt.amounts, tt.catalogues); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSample(t *testing.T) {
+	dists := []Distribution{
+		{Kind: Uniform, Count: 500, Seed: 7, Min: 100, Max: 200},
+		{Kind: Normal, Count: 500, Seed: 7, Mean: 1000, StdDev: 400, Max: 1500},
+		{Kind: LogNormal, Count: 500, Seed: 7, Mean: 1000, StdDev: 2000},
+	}
+
+	for _, d := range dists {
+		t.Run(d.Kind, func(t *testing.T) {
+			amounts, err := d.Sample()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			again, _ := d.Sample()
+			if len(amounts) != d.Count || !slices.Equal(amounts, again) {
+				t.Fatalf("expected %d reproducible amounts", d.Count)
+			}
+
+			lo, hi := max(1, d.Min), d.Max
+			sum := 0
+			for _, a := range amounts {
+				if a < lo || (hi > 0 && a > hi) {
+					t.Fatalf("amount %d outside [%d, %d]", a, lo, hi)
+				}
+				sum += a
+			}
+			if d.Kind == LogNormal {
+				if mean := sum / len(amounts); mean < 700 || mean > 1300 {
+					t.Errorf("expected a mean near 1000, got %d", mean)
+				}
+				if median := slices.Sorted(slices.Values(amounts))[len(amounts)/2]; median >= 1000 {
+					t.Errorf("expected a median below the mean, got %d", median)
+				}
+			}
+		})
+	}
+
+	other := dists[0]
+	other.Seed = 8
+	a, _ := dists[0].Sample()
+	b, _ := other.Sample()
+	if slices.Equal(a, b) {
+		t.Error("expected another seed to give other amounts")
+	}
+}
+
+func TestDistributionValidate(t *testing.T) {
+	invalid := []Distribution{
+		{Kind: Uniform, Count: 0, Min: 1, Max: 2},
+		{Kind: Uniform, Count: MaxAmounts + 1, Min: 1, Max: 2},
+		{Kind: Uniform, Count: 1, Min: 0, Max: 2},
+		{Kind: Uniform, Count: 1, Min: 3, Max: 2},
+		{Kind: Normal, Count: 1, Mean: 0, StdDev: 1},
+		{Kind: LogNormal, Count: 1, Mean: 10, StdDev: -1},
+		{Kind: "poisson", Count: 1, Mean: 10},
+	}
+
+	for _, d := range invalid {
+		if err := d.Validate(); !errors.Is(err, ErrInvalidSimulation) {
+			t.Errorf("%+v: expected ErrInvalidSimulation, got %v", d, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// APIKey is a stored credential. The raw key is never kept, only its hash.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	KeyHash   string    `json:"-"`
+	Scopes    []string  `json:"scopes"`
+	Tenant    string    `json:"tenant,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKeyStore interface for API key persistence
+type APIKeyStore interface {
+	CreateAPIKey(key APIKey) bool
+	GetAPIKeyByHash(hash string) (APIKey, bool)
+	ListAPIKeys() []APIKey
+	DeleteAPIKey(id string) bool
+}
+
+// MemoryAPIKeyStore is a thread-safe in-memory implementation
+type MemoryAPIKeyStore struct {
+	mu     sync.RWMutex
+	byID   map[string]APIKey
+	byHash map[string]string // hash -> id
+}
+
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{
+		byID:   make(map[string]APIKey),
+		byHash: make(map[string]string),
+	}
+}
+
+// CreateAPIKey returns false if the id or hash is already in use
+func (s *MemoryAPIKeyStore) CreateAPIKey(key APIKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byID[key.ID]; ok {
+		return false
+	}
+	if _, ok := s.byHash[key.KeyHash]; ok {
+		return false
+	}
+
+	s.byID[key.ID] = copyAPIKey(key)
+	s.byHash[key.KeyHash] = key.ID
+	return true
+}
+
+func (s *MemoryAPIKeyStore) GetAPIKeyByHash(hash string) (APIKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.byHash[hash]
+	if !ok {
+		return APIKey{}, false
+	}
+	return copyAPIKey(s.byID[id]), true
+}
+
+// ListAPIKeys returns keys ordered by creation time
+func (s *MemoryAPIKeyStore) ListAPIKeys() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]APIKey, 0, len(s.byID))
+	for _, key := range s.byID {
+		result = append(result, copyAPIKey(key))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+func (s *MemoryAPIKeyStore) DeleteAPIKey(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.byID[id]
+	if !ok {
+		return false
+	}
+
+	delete(s.byID, id)
+	delete(s.byHash, key.KeyHash)
+	return true
+}
+
+func copyAPIKey(key APIKey) APIKey {
+	key.Scopes = append([]string(nil), key.Scopes...)
+	return key
+}
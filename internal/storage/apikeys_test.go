@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPIKeyStoreCreateAndLookup(t *testing.T) {
+	s := NewMemoryAPIKeyStore()
+
+	key := APIKey{ID: "a", Name: "ci", KeyHash: "hash-a", Scopes: []string{"calculate"}, CreatedAt: time.Now()}
+	if !s.CreateAPIKey(key) {
+		t.Fatal("expected CreateAPIKey to succeed")
+	}
+
+	got, ok := s.GetAPIKeyByHash("hash-a")
+	if !ok || got.ID != "a" {
+		t.Errorf("expected key a, got %+v (found=%v)", got, ok)
+	}
+
+	got.Scopes[0] = "admin"
+	again, _ := s.GetAPIKeyByHash("hash-a")
+	if again.Scopes[0] != "calculate" {
+		t.Error("GetAPIKeyByHash should return a copy")
+	}
+}
+
+func TestAPIKeyStoreDuplicate(t *testing.T) {
+	s := NewMemoryAPIKeyStore()
+	s.CreateAPIKey(APIKey{ID: "a", KeyHash: "hash-a"})
+
+	if s.CreateAPIKey(APIKey{ID: "a", KeyHash: "hash-b"}) {
+		t.Error("expected duplicate id to be rejected")
+	}
+	if s.CreateAPIKey(APIKey{ID: "b", KeyHash: "hash-a"}) {
+		t.Error("expected duplicate hash to be rejected")
+	}
+}
+
+func TestAPIKeyStoreDelete(t *testing.T) {
+	s := NewMemoryAPIKeyStore()
+	s.CreateAPIKey(APIKey{ID: "a", KeyHash: "hash-a"})
+
+	if !s.DeleteAPIKey("a") {
+		t.Fatal("expected DeleteAPIKey to succeed")
+	}
+	if _, ok := s.GetAPIKeyByHash("hash-a"); ok {
+		t.Error("expected deleted key to be gone")
+	}
+	if s.DeleteAPIKey("a") {
+		t.Error("expected second delete to fail")
+	}
+	if len(s.ListAPIKeys()) != 0 {
+		t.Error("expected empty list")
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAuditRetention is how long MemoryAuditLog keeps calculations
+	DefaultAuditRetention = 90 * 24 * time.Hour
+	// DefaultAuditMaxRecords bounds MemoryAuditLog regardless of age
+	DefaultAuditMaxRecords = 100000
+)
+
+// CalculationRecord is an audited calculation result.
+type CalculationRecord struct {
+	ID          int64       `json:"id"`
+	RequestID   string      `json:"request_id"`
+	Caller      string      `json:"caller"`
+	OrderAmount int         `json:"order_amount"`
+	PackSizes   []int       `json:"pack_sizes_used"`
+	TotalItems  int         `json:"total_items"`
+	TotalPacks  int         `json:"total_packs"`
+	Packs       map[int]int `json:"packs"`
+	Timestamp   time.Time   `json:"timestamp"`
+}
+
+// CalculationFilter selects audit records. Zero values do not filter.
+// Results are newest first; BeforeID continues from a previous page.
+type CalculationFilter struct {
+	From      time.Time // inclusive
+	To        time.Time // exclusive
+	MinAmount int
+	MaxAmount int
+	Caller    string
+	BeforeID  int64
+	Limit     int
+}
+
+func (f CalculationFilter) matches(r CalculationRecord) bool {
+	switch {
+	case !f.From.IsZero() && r.Timestamp.Before(f.From):
+		return false
+	case !f.To.IsZero() && !r.Timestamp.Before(f.To):
+		return false
+	case f.MinAmount > 0 && r.OrderAmount < f.MinAmount:
+		return false
+	case f.MaxAmount > 0 && r.OrderAmount > f.MaxAmount:
+		return false
+	case f.Caller != "" && r.Caller != f.Caller:
+		return false
+	case f.BeforeID > 0 && r.ID >= f.BeforeID:
+		return false
+	}
+	return true
+}
+
+// AuditStore interface for the calculation audit log
+type AuditStore interface {
+	RecordCalculation(record CalculationRecord) CalculationRecord
+	// ListCalculations returns up to filter.Limit matches and whether more remain.
+	ListCalculations(filter CalculationFilter) ([]CalculationRecord, bool)
+}
+
+// MemoryAuditLog is a thread-safe in-memory audit log that drops records
+// older than its retention or beyond its size limit.
+type MemoryAuditLog struct {
+	mu         sync.RWMutex
+	records    []CalculationRecord // oldest first
+	nextID     int64
+	retention  time.Duration
+	maxRecords int
+	now        func() time.Time
+}
+
+func NewMemoryAuditLog(retention time.Duration, maxRecords int) *MemoryAuditLog {
+	if retention <= 0 {
+		retention = DefaultAuditRetention
+	}
+	if maxRecords <= 0 {
+		maxRecords = DefaultAuditMaxRecords
+	}
+	return &MemoryAuditLog{nextID: 1, retention: retention, maxRecords: maxRecords, now: time.Now}
+}
+
+// RecordCalculation assigns an ID and timestamp and applies the retention policy
+func (l *MemoryAuditLog) RecordCalculation(record CalculationRecord) CalculationRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record.ID = l.nextID
+	l.nextID++
+	if record.Timestamp.IsZero() {
+		record.Timestamp = l.now().UTC()
+	}
+	record = copyCalculationRecord(record)
+
+	l.records = append(l.records, record)
+	l.prune()
+	return record
+}
+
+func (l *MemoryAuditLog) ListCalculations(filter CalculationFilter) ([]CalculationRecord, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	cutoff := l.now().Add(-l.retention)
+	result := []CalculationRecord{}
+	for i := len(l.records) - 1; i >= 0; i-- {
+		r := l.records[i]
+		if r.Timestamp.Before(cutoff) {
+			break
+		}
+		if !filter.matches(r) {
+			continue
+		}
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			return result, true
+		}
+		result = append(result, copyCalculationRecord(r))
+	}
+	return result, false
+}
+
+// prune drops expired records and the oldest beyond maxRecords
+func (l *MemoryAuditLog) prune() {
+	cutoff := l.now().Add(-l.retention)
+	drop := 0
+	for drop < len(l.records) && l.records[drop].Timestamp.Before(cutoff) {
+		drop++
+	}
+	if excess := len(l.records) - drop - l.maxRecords; excess > 0 {
+		drop += excess
+	}
+	l.records = l.records[drop:]
+}
+
+func copyCalculationRecord(r CalculationRecord) CalculationRecord {
+	r.PackSizes = append([]int(nil), r.PackSizes...)
+	packs := make(map[int]int, len(r.Packs))
+	for size, qty := range r.Packs {
+		packs[size] = qty
+	}
+	r.Packs = packs
+	return r
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditLogFilters(t *testing.T) {
+	l := NewMemoryAuditLog(time.Hour, 10)
+	base := time.Now().UTC()
+
+	l.RecordCalculation(CalculationRecord{Caller: "alice", OrderAmount: 100, Timestamp: base.Add(-30 * time.Minute)})
+	l.RecordCalculation(CalculationRecord{Caller: "bob", OrderAmount: 500, Timestamp: base.Add(-20 * time.Minute)})
+	l.RecordCalculation(CalculationRecord{Caller: "alice", OrderAmount: 1000, Timestamp: base.Add(-10 * time.Minute), Packs: map[int]int{1000: 1}})
+
+	tests := []struct {
+		name   string
+		filter CalculationFilter
+		want   []int64
+	}{
+		{"all newest first", CalculationFilter{}, []int64{3, 2, 1}},
+		{"caller", CalculationFilter{Caller: "alice"}, []int64{3, 1}},
+		{"amount range", CalculationFilter{MinAmount: 200, MaxAmount: 1000}, []int64{3, 2}},
+		{"time range", CalculationFilter{From: base.Add(-25 * time.Minute), To: base.Add(-10 * time.Minute)}, []int64{2}},
+		{"before id", CalculationFilter{BeforeID: 3}, []int64{2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, _ := l.ListCalculations(tt.filter)
+			var got []int64
+			for _, r := range records {
+				got = append(got, r.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	records, _ := l.ListCalculations(CalculationFilter{Limit: 1})
+	records[0].Packs[1000] = 5
+	again, _ := l.ListCalculations(CalculationFilter{Limit: 1})
+	if again[0].Packs[1000] != 1 {
+		t.Error("ListCalculations should return copies")
+	}
+}
+
+func TestAuditLogPagination(t *testing.T) {
+	l := NewMemoryAuditLog(time.Hour, 10)
+	for i := 0; i < 5; i++ {
+		l.RecordCalculation(CalculationRecord{OrderAmount: i + 1})
+	}
+
+	page, more := l.ListCalculations(CalculationFilter{Limit: 2})
+	if len(page) != 2 || !more || page[1].ID != 4 {
+		t.Fatalf("unexpected first page %+v (more=%v)", page, more)
+	}
+
+	page, more = l.ListCalculations(CalculationFilter{Limit: 2, BeforeID: 2})
+	if len(page) != 1 || more || page[0].ID != 1 {
+		t.Errorf("unexpected last page %+v (more=%v)", page, more)
+	}
+}
+
+func TestAuditLogRetention(t *testing.T) {
+	l := NewMemoryAuditLog(time.Hour, 3)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	l.RecordCalculation(CalculationRecord{Timestamp: now.Add(-2 * time.Hour)})
+	for i := 0; i < 4; i++ {
+		l.RecordCalculation(CalculationRecord{})
+	}
+
+	records, _ := l.ListCalculations(CalculationFilter{})
+	if len(records) != 3 || records[2].ID != 3 {
+		t.Errorf("expected the 3 newest records, got %+v", records)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if records, _ := l.ListCalculations(CalculationFilter{}); len(records) != 0 {
+		t.Errorf("expected expired records to be hidden, got %d", len(records))
+	}
+}
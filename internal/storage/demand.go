@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDemandBucket is the width of MemoryDemandStore buckets
+	DefaultDemandBucket = time.Hour
+	// DefaultDemandRetention is how long MemoryDemandStore keeps buckets
+	DefaultDemandRetention = 90 * 24 * time.Hour
+)
+
+// DemandSample is one ordered amount.
+type DemandSample struct {
+	At     time.Time
+	Amount int
+}
+
+// DemandBucket counts the orders per amount from Start for one bucket width.
+type DemandBucket struct {
+	Start   time.Time   `json:"start"`
+	Orders  int         `json:"orders"`
+	Amounts map[int]int `json:"amounts"`
+}
+
+// DemandStore is a time-bucketed histogram of ordered amounts
+type DemandStore interface {
+	AddDemand(samples []DemandSample)
+	// ListDemand returns the buckets starting in [from, to), oldest first.
+	// Zero times do not filter.
+	ListDemand(from, to time.Time) []DemandBucket
+	BucketWidth() time.Duration
+	ResetDemand()
+}
+
+// MemoryDemandStore is a thread-safe in-memory histogram that drops buckets
+// older than its retention. Each bucket keeps a count per distinct amount, so
+// repeated amounts cost nothing.
+type MemoryDemandStore struct {
+	mu        sync.RWMutex
+	buckets   map[int64]*DemandBucket // by start, in unix nanoseconds
+	width     time.Duration
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewMemoryDemandStore(width, retention time.Duration) *MemoryDemandStore {
+	if width <= 0 {
+		width = DefaultDemandBucket
+	}
+	if retention <= 0 {
+		retention = DefaultDemandRetention
+	}
+	return &MemoryDemandStore{
+		buckets:   make(map[int64]*DemandBucket),
+		width:     width,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryDemandStore) AddDemand(samples []DemandSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sample := range samples {
+		start := sample.At.UTC().Truncate(s.width)
+		b, ok := s.buckets[start.UnixNano()]
+		if !ok {
+			b = &DemandBucket{Start: start, Amounts: make(map[int]int)}
+			s.buckets[start.UnixNano()] = b
+		}
+		b.Orders++
+		b.Amounts[sample.Amount]++
+	}
+	s.prune()
+}
+
+func (s *MemoryDemandStore) ListDemand(from, to time.Time) []DemandBucket {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cutoff := s.now().Add(-s.retention)
+	result := []DemandBucket{}
+	for _, b := range s.buckets {
+		if b.Start.Add(s.width).Before(cutoff) ||
+			(!from.IsZero() && b.Start.Before(from)) ||
+			(!to.IsZero() && !b.Start.Before(to)) {
+			continue
+		}
+		result = append(result, DemandBucket{Start: b.Start, Orders: b.Orders, Amounts: maps.Clone(b.Amounts)})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result
+}
+
+func (s *MemoryDemandStore) BucketWidth() time.Duration {
+	return s.width
+}
+
+func (s *MemoryDemandStore) ResetDemand() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buckets = make(map[int64]*DemandBucket)
+}
+
+// prune drops buckets that ended before the retention
+func (s *MemoryDemandStore) prune() {
+	cutoff := s.now().Add(-s.retention)
+	for key, b := range s.buckets {
+		if b.Start.Add(s.width).Before(cutoff) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDemandStoreBuckets(t *testing.T) {
+	s := NewMemoryDemandStore(time.Hour, 24*time.Hour)
+	hour := time.Now().UTC().Truncate(time.Hour)
+
+	s.AddDemand([]DemandSample{
+		{At: hour.Add(-90 * time.Minute), Amount: 250},
+		{At: hour.Add(5 * time.Minute), Amount: 250},
+		{At: hour.Add(10 * time.Minute), Amount: 12001},
+		{At: hour.Add(50 * time.Minute), Amount: 250},
+	})
+
+	buckets := s.ListDemand(time.Time{}, time.Time{})
+	if len(buckets) != 2 || !buckets[0].Start.Equal(hour.Add(-2*time.Hour)) || !buckets[1].Start.Equal(hour) {
+		t.Fatalf("expected two hourly buckets, got %+v", buckets)
+	}
+	if b := buckets[1]; b.Orders != 3 || b.Amounts[250] != 2 || b.Amounts[12001] != 1 {
+		t.Errorf("unexpected bucket %+v", b)
+	}
+
+	buckets[1].Amounts[250] = 99
+	if again := s.ListDemand(hour, time.Time{}); len(again) != 1 || again[0].Amounts[250] != 2 {
+		t.Errorf("expected ListDemand to filter and return copies, got %+v", again)
+	}
+	if before := s.ListDemand(time.Time{}, hour); len(before) != 1 || before[0].Orders != 1 {
+		t.Errorf("expected the earlier bucket only, got %+v", before)
+	}
+
+	s.ResetDemand()
+	if buckets := s.ListDemand(time.Time{}, time.Time{}); len(buckets) != 0 {
+		t.Errorf("expected no buckets after reset, got %+v", buckets)
+	}
+}
+
+func TestDemandStoreRetention(t *testing.T) {
+	s := NewMemoryDemandStore(time.Hour, 24*time.Hour)
+	now := time.Now().UTC()
+
+	s.AddDemand([]DemandSample{{At: now.Add(-48 * time.Hour), Amount: 1}, {At: now, Amount: 2}})
+	buckets := s.ListDemand(time.Time{}, time.Time{})
+	if len(buckets) != 1 || buckets[0].Amounts[2] != 1 {
+		t.Errorf("expected the expired bucket to be dropped, got %+v", buckets)
+	}
+	if len(s.buckets) != 1 {
+		t.Errorf("expected the expired bucket to be pruned, got %d buckets", len(s.buckets))
+	}
+}
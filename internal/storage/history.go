@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	ChangeSet      = "set"
+	ChangeAdd      = "add"
+	ChangeRemove   = "remove"
+	ChangeActivate = "activate"
+)
+
+// DefaultHistoryLimit is how many changes MemoryHistory keeps
+const DefaultHistoryLimit = 1000
+
+// Change records a single pack size configuration change.
+type Change struct {
+	ID        int64     `json:"id"`
+	Action    string    `json:"action"`
+	Size      int       `json:"size,omitempty"`
+	PackSizes []int     `json:"pack_sizes"` // list after the change
+	Profile   string    `json:"profile,omitempty"`
+	Actor     string    `json:"actor"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// subscriberBuffer is how many changes a subscriber may fall behind before
+// it is dropped. Dropped subscribers resume from their last ID.
+const subscriberBuffer = 64
+
+// HistoryStore interface for configuration change history
+type HistoryStore interface {
+	RecordChange(change Change) Change
+	History() []Change
+}
+
+// ChangeFeed is implemented by history stores that push new changes to
+// subscribers as they are recorded.
+type ChangeFeed interface {
+	Subscribe(lastID int64) *Subscription
+}
+
+// Subscription delivers recorded changes. C is closed when the subscription
+// is closed or the subscriber falls too far behind.
+type Subscription struct {
+	Replay []Change // retained changes after lastID, oldest first
+	C      <-chan Change
+
+	close func()
+}
+
+func (s *Subscription) Close() {
+	s.close()
+}
+
+// MemoryHistory is a bounded, thread-safe in-memory change log
+type MemoryHistory struct {
+	mu          sync.RWMutex
+	changes     []Change
+	nextID      int64
+	limit       int
+	subscribers map[chan Change]struct{}
+}
+
+func NewMemoryHistory(limit int) *MemoryHistory {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	return &MemoryHistory{limit: limit, nextID: 1, subscribers: make(map[chan Change]struct{})}
+}
+
+// RecordChange assigns an ID and timestamp, dropping the oldest entry when full
+func (h *MemoryHistory) RecordChange(change Change) Change {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	change.ID = h.nextID
+	h.nextID++
+	if change.Timestamp.IsZero() {
+		change.Timestamp = time.Now().UTC()
+	}
+	change.PackSizes = append([]int(nil), change.PackSizes...)
+
+	h.changes = append(h.changes, change)
+	if len(h.changes) > h.limit {
+		h.changes = h.changes[len(h.changes)-h.limit:]
+	}
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- copyChange(change):
+		default:
+			// never block writers on a slow reader
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+	return change
+}
+
+// Subscribe replays retained changes after lastID and then delivers new ones.
+// A lastID of 0 skips the replay.
+func (h *MemoryHistory) Subscribe(lastID int64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []Change
+	if lastID > 0 {
+		for _, change := range h.changes {
+			if change.ID > lastID {
+				replay = append(replay, copyChange(change))
+			}
+		}
+	}
+
+	ch := make(chan Change, subscriberBuffer)
+	h.subscribers[ch] = struct{}{}
+
+	return &Subscription{
+		Replay: replay,
+		C:      ch,
+		close: func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := h.subscribers[ch]; ok {
+				delete(h.subscribers, ch)
+				close(ch)
+			}
+		},
+	}
+}
+
+// History returns changes oldest first
+func (h *MemoryHistory) History() []Change {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := make([]Change, len(h.changes))
+	for i, change := range h.changes {
+		result[i] = copyChange(change)
+	}
+	return result
+}
+
+func copyChange(change Change) Change {
+	change.PackSizes = append([]int(nil), change.PackSizes...)
+	return change
+}
@@ -0,0 +1,88 @@
+package storage
+
+import "testing"
+
+func TestMemoryHistoryRecord(t *testing.T) {
+	h := NewMemoryHistory(10)
+
+	first := h.RecordChange(Change{Action: ChangeAdd, Size: 10, PackSizes: []int{10}, Actor: "alice"})
+	second := h.RecordChange(Change{Action: ChangeRemove, Size: 10, Actor: "bob"})
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("expected sequential ids, got %d and %d", first.ID, second.ID)
+	}
+	if first.Timestamp.IsZero() {
+		t.Error("expected timestamp to be set")
+	}
+
+	changes := h.History()
+	if len(changes) != 2 || changes[0].Actor != "alice" {
+		t.Errorf("unexpected history %+v", changes)
+	}
+}
+
+func TestMemoryHistoryLimit(t *testing.T) {
+	h := NewMemoryHistory(2)
+
+	for i := 0; i < 5; i++ {
+		h.RecordChange(Change{Action: ChangeSet})
+	}
+
+	changes := h.History()
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+	if changes[0].ID != 4 || changes[1].ID != 5 {
+		t.Errorf("expected newest changes to be kept, got ids %d and %d", changes[0].ID, changes[1].ID)
+	}
+}
+
+func TestMemoryHistorySubscribe(t *testing.T) {
+	h := NewMemoryHistory(10)
+	h.RecordChange(Change{Action: ChangeAdd, Size: 10})
+	h.RecordChange(Change{Action: ChangeAdd, Size: 20})
+
+	sub := h.Subscribe(0)
+	defer sub.Close()
+	if len(sub.Replay) != 0 {
+		t.Errorf("expected no replay without a last id, got %+v", sub.Replay)
+	}
+
+	h.RecordChange(Change{Action: ChangeRemove, Size: 10})
+	change := <-sub.C
+	if change.ID != 3 || change.Action != ChangeRemove {
+		t.Errorf("unexpected change %+v", change)
+	}
+}
+
+func TestMemoryHistorySubscribeReplay(t *testing.T) {
+	h := NewMemoryHistory(10)
+	for i := 0; i < 3; i++ {
+		h.RecordChange(Change{Action: ChangeSet})
+	}
+
+	sub := h.Subscribe(1)
+	defer sub.Close()
+	if len(sub.Replay) != 2 || sub.Replay[0].ID != 2 {
+		t.Errorf("expected changes 2 and 3 replayed, got %+v", sub.Replay)
+	}
+}
+
+func TestMemoryHistoryDropsSlowSubscriber(t *testing.T) {
+	h := NewMemoryHistory(10)
+	sub := h.Subscribe(0)
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		h.RecordChange(Change{Action: ChangeSet})
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("expected %d buffered changes before close, got %d", subscriberBuffer, received)
+	}
+
+	sub.Close() // closing a dropped subscription is a no-op
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long stored responses are replayed
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencySweepEvery controls how often expired records are evicted
+const idempotencySweepEvery = 1024
+
+// StoredResponse is the first response sent for an idempotency key.
+type StoredResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyRecord ties a key to the request that first used it. Response
+// is nil while that request is still being processed.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Response    *StoredResponse
+	ExpiresAt   time.Time
+}
+
+// IdempotencyStore interface for idempotency key persistence
+type IdempotencyStore interface {
+	// Reserve claims key for a new request. When the key is already taken it
+	// returns the existing record and false.
+	Reserve(key string, record IdempotencyRecord, now time.Time) (IdempotencyRecord, bool)
+	Complete(key string, resp StoredResponse)
+	// Release forgets a reservation so the request can be retried.
+	Release(key string)
+}
+
+// MemoryIdempotencyStore is a thread-safe in-memory implementation
+type MemoryIdempotencyStore struct {
+	mu       sync.Mutex
+	records  map[string]IdempotencyRecord
+	reserves int
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]IdempotencyRecord)}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(key string, record IdempotencyRecord, now time.Time) (IdempotencyRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reserves++
+	if s.reserves%idempotencySweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	if existing, ok := s.records[key]; ok && now.Before(existing.ExpiresAt) {
+		return copyIdempotencyRecord(existing), false
+	}
+
+	s.records[key] = record
+	return record, true
+}
+
+func (s *MemoryIdempotencyStore) Complete(key string, resp StoredResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return
+	}
+	resp.Body = append([]byte(nil), resp.Body...)
+	record.Response = &resp
+	s.records[key] = record
+}
+
+func (s *MemoryIdempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+}
+
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func copyIdempotencyRecord(record IdempotencyRecord) IdempotencyRecord {
+	if record.Response != nil {
+		resp := *record.Response
+		resp.Body = append([]byte(nil), resp.Body...)
+		record.Response = &resp
+	}
+	return record
+}
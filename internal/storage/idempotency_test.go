@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotencyStoreReserve(t *testing.T) {
+	s := NewMemoryIdempotencyStore()
+	now := time.Now()
+	record := IdempotencyRecord{Fingerprint: "a", ExpiresAt: now.Add(time.Minute)}
+
+	if _, ok := s.Reserve("k", record, now); !ok {
+		t.Fatal("expected first reservation to succeed")
+	}
+
+	existing, ok := s.Reserve("k", IdempotencyRecord{Fingerprint: "b"}, now)
+	if ok || existing.Fingerprint != "a" || existing.Response != nil {
+		t.Errorf("expected in-flight record a, got %+v (reserved=%v)", existing, ok)
+	}
+
+	s.Complete("k", StoredResponse{Status: 201, Body: []byte("created")})
+	existing, _ = s.Reserve("k", record, now)
+	if existing.Response == nil || existing.Response.Status != 201 || string(existing.Response.Body) != "created" {
+		t.Errorf("expected stored response, got %+v", existing.Response)
+	}
+}
+
+func TestIdempotencyStoreExpiryAndRelease(t *testing.T) {
+	s := NewMemoryIdempotencyStore()
+	now := time.Now()
+	s.Reserve("k", IdempotencyRecord{Fingerprint: "a", ExpiresAt: now.Add(time.Minute)}, now)
+
+	if _, ok := s.Reserve("k", IdempotencyRecord{Fingerprint: "b"}, now.Add(time.Minute)); !ok {
+		t.Error("expected expired key to be reusable")
+	}
+
+	s.Release("k")
+	if _, ok := s.Reserve("k", IdempotencyRecord{Fingerprint: "c"}, now); !ok {
+		t.Error("expected released key to be reusable")
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"maps"
+	"sort"
+
+	"github.com/willianbsanches13/pack-calculator/internal/pricing"
+)
+
+// PriceStore is a Storage that keeps price lists alongside the pack sizes.
+// They are shared by every profile.
+type PriceStore interface {
+	Storage
+	ListPriceLists() []pricing.PriceList
+	GetPriceList(id string) (pricing.PriceList, bool)
+	PutPriceList(pl pricing.PriceList) bool
+	DeletePriceList(id string) bool
+}
+
+// ListPriceLists returns price lists ordered by currency, then effective date
+func (s *MemoryStorage) ListPriceLists() []pricing.PriceList {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]pricing.PriceList, 0, len(s.priceLists))
+	for _, pl := range s.priceLists {
+		result = append(result, copyPriceList(pl))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch {
+		case a.Currency != b.Currency:
+			return a.Currency < b.Currency
+		case !a.EffectiveFrom.Equal(b.EffectiveFrom):
+			return a.EffectiveFrom.Before(b.EffectiveFrom)
+		}
+		return a.ID < b.ID
+	})
+	return result
+}
+
+func (s *MemoryStorage) GetPriceList(id string) (pricing.PriceList, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pl, ok := s.priceLists[id]
+	return copyPriceList(pl), ok
+}
+
+// PutPriceList creates or replaces a price list and reports whether it is new
+func (s *MemoryStorage) PutPriceList(pl pricing.PriceList) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.priceLists[pl.ID]
+	s.priceLists[pl.ID] = copyPriceList(pl)
+	return !exists
+}
+
+func (s *MemoryStorage) DeletePriceList(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.priceLists[id]; !ok {
+		return false
+	}
+	delete(s.priceLists, id)
+	return true
+}
+
+func copyPriceList(pl pricing.PriceList) pricing.PriceList {
+	pl.Prices = maps.Clone(pl.Prices)
+	pl.Discounts = append([]pricing.VolumeDiscount(nil), pl.Discounts...)
+	if pl.EffectiveTo != nil {
+		to := *pl.EffectiveTo
+		pl.EffectiveTo = &to
+	}
+	return pl
+}
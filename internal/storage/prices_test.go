@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/willianbsanches13/pack-calculator/internal/pricing"
+)
+
+func TestPriceStoreCRUD(t *testing.T) {
+	s := NewMemoryStorage()
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+
+	standard := pricing.PriceList{
+		ID:            "standard",
+		Currency:      "EUR",
+		EffectiveFrom: jan,
+		Prices:        map[int]decimal.Decimal{250: decimal.NewFromInt(2)},
+	}
+	if !s.PutPriceList(standard) {
+		t.Error("expected a new price list to be created")
+	}
+	s.PutPriceList(pricing.PriceList{ID: "dollars", Currency: "USD", EffectiveFrom: jan})
+	s.PutPriceList(pricing.PriceList{ID: "february", Currency: "EUR", EffectiveFrom: feb})
+
+	got, ok := s.GetPriceList("standard")
+	if !ok || !got.Prices[250].Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected price list standard, got %+v (found=%v)", got, ok)
+	}
+	got.Prices[250] = decimal.NewFromInt(3)
+	if again, _ := s.GetPriceList("standard"); !again.Prices[250].Equal(decimal.NewFromInt(2)) {
+		t.Error("GetPriceList should return a copy")
+	}
+
+	if s.PutPriceList(got) {
+		t.Error("expected an existing price list to be replaced")
+	}
+
+	lists := s.ListPriceLists()
+	if len(lists) != 3 || lists[0].ID != "standard" || lists[1].ID != "february" || lists[2].ID != "dollars" {
+		t.Errorf("unexpected list %+v", lists)
+	}
+
+	if !s.DeletePriceList("standard") || s.DeletePriceList("standard") {
+		t.Error("expected delete to succeed once")
+	}
+	if _, ok := s.GetPriceList("standard"); ok {
+		t.Error("expected the price list to be gone")
+	}
+}
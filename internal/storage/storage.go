@@ -2,7 +2,12 @@
 package storage
 
 import (
+	"errors"
+	"sort"
 	"sync"
+
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
+	"github.com/willianbsanches13/pack-calculator/internal/pricing"
 )
 
 // Storage interface for pack size persistence
@@ -13,10 +18,57 @@ type Storage interface {
 	RemovePackSize(size int) bool
 }
 
+// PackStore is a Storage that keeps pack attributes such as weight. The
+// Storage methods keep the attributes of the sizes they leave in place.
+type PackStore interface {
+	Storage
+	GetPacks() []calculator.Pack
+	SetPacks(packs []calculator.Pack) error
+}
+
+// PackagingStore is a Storage that keeps how packs are grouped into cartons
+// and pallets. It is shared by every profile.
+type PackagingStore interface {
+	Storage
+	GetPackaging() calculator.Packaging
+	SetPackaging(p calculator.Packaging)
+}
+
+// DefaultProfile is the profile a new MemoryStorage starts with
+const DefaultProfile = "default"
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrProfileActive   = errors.New("profile is active")
+)
+
+// Profile is a named pack size list.
+type Profile struct {
+	Name      string            `json:"name"`
+	PackSizes []int             `json:"pack_sizes"`
+	Packs     []calculator.Pack `json:"packs"`
+	Active    bool              `json:"active"`
+}
+
+// ProfileStore is a Storage holding several named lists. The Storage methods
+// read and modify the active profile.
+type ProfileStore interface {
+	Storage
+	ListProfiles() []Profile
+	GetProfile(name string) (Profile, bool)
+	CreateProfile(name string, sizes []int) bool
+	UpdateProfile(name string, sizes []int) bool
+	DeleteProfile(name string) error
+	ActivateProfile(name string) (Profile, bool)
+}
+
 // MemoryStorage is a thread-safe in-memory implementation
 type MemoryStorage struct {
-	mu        sync.RWMutex
-	packSizes []int
+	mu         sync.RWMutex
+	profiles   map[string][]calculator.Pack
+	active     string
+	packaging  calculator.Packaging
+	priceLists map[string]pricing.PriceList
 }
 
 func DefaultPackSizes() []int {
@@ -24,17 +76,15 @@ func DefaultPackSizes() []int {
 }
 
 func NewMemoryStorage() *MemoryStorage {
-	return &MemoryStorage{
-		packSizes: DefaultPackSizes(),
-	}
+	return NewMemoryStorageWithSizes(DefaultPackSizes())
 }
 
 func NewMemoryStorageWithSizes(sizes []int) *MemoryStorage {
-	s := &MemoryStorage{
-		packSizes: make([]int, len(sizes)),
+	return &MemoryStorage{
+		profiles:   map[string][]calculator.Pack{DefaultProfile: calculator.PacksFromSizes(sizes)},
+		active:     DefaultProfile,
+		priceLists: make(map[string]pricing.PriceList),
 	}
-	copy(s.packSizes, sizes)
-	return s
 }
 
 // GetPackSizes returns a copy to prevent external modifications
@@ -42,31 +92,60 @@ func (s *MemoryStorage) GetPackSizes() []int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	result := make([]int, len(s.packSizes))
-	copy(result, s.packSizes)
-	return result
+	return calculator.Sizes(s.profiles[s.active])
 }
 
 func (s *MemoryStorage) SetPackSizes(sizes []int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.packSizes = make([]int, len(sizes))
-	copy(s.packSizes, sizes)
+	s.profiles[s.active] = withSizes(s.profiles[s.active], sizes)
 	return nil
 }
 
+// GetPacks returns the active pack definitions
+func (s *MemoryStorage) GetPacks() []calculator.Pack {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyPacks(s.profiles[s.active])
+}
+
+func (s *MemoryStorage) SetPacks(packs []calculator.Pack) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.profiles[s.active] = copyPacks(packs)
+	return nil
+}
+
+// GetPackaging returns a copy of the carton and pallet hierarchy
+func (s *MemoryStorage) GetPackaging() calculator.Packaging {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyPackaging(s.packaging)
+}
+
+func (s *MemoryStorage) SetPackaging(p calculator.Packaging) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.packaging = copyPackaging(p)
+}
+
 func (s *MemoryStorage) AddPackSize(size int) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, existing := range s.packSizes {
-		if existing == size {
+	packs := s.profiles[s.active]
+	for _, existing := range packs {
+		if existing.Size == size {
 			return false
 		}
 	}
 
-	s.packSizes = append(s.packSizes, size)
+	s.profiles[s.active] = append(packs, calculator.Pack{Size: size})
 	return true
 }
 
@@ -74,12 +153,128 @@ func (s *MemoryStorage) RemovePackSize(size int) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, existing := range s.packSizes {
-		if existing == size {
-			s.packSizes = append(s.packSizes[:i], s.packSizes[i+1:]...)
+	packs := s.profiles[s.active]
+	for i, existing := range packs {
+		if existing.Size == size {
+			s.profiles[s.active] = append(packs[:i], packs[i+1:]...)
 			return true
 		}
 	}
 
 	return false
 }
+
+// ListProfiles returns profiles ordered by name
+func (s *MemoryStorage) ListProfiles() []Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Profile, 0, len(s.profiles))
+	for name := range s.profiles {
+		result = append(result, s.profile(name))
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (s *MemoryStorage) GetProfile(name string) (Profile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.profiles[name]; !ok {
+		return Profile{}, false
+	}
+	return s.profile(name), true
+}
+
+// CreateProfile returns false if the name is already in use
+func (s *MemoryStorage) CreateProfile(name string, sizes []int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[name]; ok {
+		return false
+	}
+	s.profiles[name] = calculator.PacksFromSizes(sizes)
+	return true
+}
+
+// UpdateProfile replaces a profile's list and returns false if it is unknown
+func (s *MemoryStorage) UpdateProfile(name string, sizes []int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	packs, ok := s.profiles[name]
+	if !ok {
+		return false
+	}
+	s.profiles[name] = withSizes(packs, sizes)
+	return true
+}
+
+// DeleteProfile refuses to delete the active profile
+func (s *MemoryStorage) DeleteProfile(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[name]; !ok {
+		return ErrProfileNotFound
+	}
+	if name == s.active {
+		return ErrProfileActive
+	}
+	delete(s.profiles, name)
+	return nil
+}
+
+// ActivateProfile switches the active profile in one step
+func (s *MemoryStorage) ActivateProfile(name string) (Profile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[name]; !ok {
+		return Profile{}, false
+	}
+	s.active = name
+	return s.profile(name), true
+}
+
+// profile must be called with the lock held
+func (s *MemoryStorage) profile(name string) Profile {
+	packs := s.profiles[name]
+	return Profile{Name: name, PackSizes: calculator.Sizes(packs), Packs: copyPacks(packs), Active: name == s.active}
+}
+
+// withSizes builds packs for sizes, keeping the attributes of existing ones
+func withSizes(existing []calculator.Pack, sizes []int) []calculator.Pack {
+	bySize := make(map[int]calculator.Pack, len(existing))
+	for _, p := range existing {
+		bySize[p.Size] = p
+	}
+
+	packs := calculator.PacksFromSizes(sizes)
+	for i, p := range packs {
+		if old, ok := bySize[p.Size]; ok {
+			packs[i] = old
+		}
+	}
+	return packs
+}
+
+func copyPacks(in []calculator.Pack) []calculator.Pack {
+	out := make([]calculator.Pack, len(in))
+	for i, p := range in {
+		if p.Dimensions != nil {
+			d := *p.Dimensions
+			p.Dimensions = &d
+		}
+		out[i] = p
+	}
+	return out
+}
+
+func copyPackaging(p calculator.Packaging) calculator.Packaging {
+	p.Cartons = append([]calculator.CartonType{}, p.Cartons...)
+	return p
+}
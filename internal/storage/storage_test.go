@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/willianbsanches13/pack-calculator/internal/calculator"
 )
 
 func TestNewMemoryStorage(t *testing.T) {
@@ -149,3 +151,104 @@ func TestDefaultPackSizes(t *testing.T) {
 		t.Errorf("expected %v, got %v", expected, defaults)
 	}
 }
+
+func TestProfiles(t *testing.T) {
+	s := NewMemoryStorage()
+
+	if !s.CreateProfile("holiday", []int{100, 200}) {
+		t.Fatal("expected CreateProfile to succeed")
+	}
+	if s.CreateProfile("holiday", []int{1}) {
+		t.Error("expected duplicate profile to be rejected")
+	}
+
+	profiles := s.ListProfiles()
+	if len(profiles) != 2 || profiles[0].Name != DefaultProfile || !profiles[0].Active || profiles[1].Active {
+		t.Errorf("unexpected profiles %+v", profiles)
+	}
+
+	s.AddPackSize(300) // applies to the active default profile
+	if holiday, _ := s.GetProfile("holiday"); len(holiday.PackSizes) != 2 {
+		t.Errorf("inactive profile must not change, got %v", holiday.PackSizes)
+	}
+
+	active, ok := s.ActivateProfile("holiday")
+	if !ok || !active.Active {
+		t.Fatalf("expected holiday to be activated, got %+v", active)
+	}
+	if sizes := s.GetPackSizes(); len(sizes) != 2 || sizes[0] != 100 {
+		t.Errorf("expected holiday sizes, got %v", sizes)
+	}
+	if _, ok := s.ActivateProfile("missing"); ok {
+		t.Error("expected unknown profile to be rejected")
+	}
+
+	if !s.UpdateProfile(DefaultProfile, []int{42}) || s.UpdateProfile("missing", []int{1}) {
+		t.Error("expected update to succeed only for known profiles")
+	}
+	if p, _ := s.GetProfile(DefaultProfile); len(p.PackSizes) != 1 || p.PackSizes[0] != 42 {
+		t.Errorf("expected updated default profile, got %+v", p)
+	}
+}
+
+func TestDeleteProfile(t *testing.T) {
+	s := NewMemoryStorage()
+	s.CreateProfile("bulk", []int{10000})
+
+	if err := s.DeleteProfile(DefaultProfile); err != ErrProfileActive {
+		t.Errorf("expected ErrProfileActive, got %v", err)
+	}
+	if err := s.DeleteProfile("bulk"); err != nil {
+		t.Errorf("expected delete to succeed, got %v", err)
+	}
+	if err := s.DeleteProfile("bulk"); err != ErrProfileNotFound {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestPacksKeepAttributes(t *testing.T) {
+	s := NewMemoryStorageWithSizes([]int{250})
+	s.SetPacks([]calculator.Pack{
+		{Size: 250, Weight: 0.3, Label: "small", Dimensions: &calculator.Dimensions{Length: 10, Width: 10, Height: 5}},
+		{Size: 500, Weight: 0.55},
+	})
+
+	packs := s.GetPacks()
+	packs[0].Dimensions.Length = 99
+	if s.GetPacks()[0].Dimensions.Length != 10 {
+		t.Error("expected GetPacks to return a copy")
+	}
+
+	s.SetPackSizes([]int{250, 1000})
+	s.AddPackSize(2000)
+	packs = s.GetPacks()
+	if len(packs) != 3 || packs[0].Label != "small" || packs[1] != (calculator.Pack{Size: 1000}) {
+		t.Errorf("expected 250 to keep its attributes, got %+v", packs)
+	}
+	if sizes := s.GetPackSizes(); len(sizes) != 3 || sizes[2] != 2000 {
+		t.Errorf("unexpected sizes %v", sizes)
+	}
+
+	profile, _ := s.GetProfile(DefaultProfile)
+	if len(profile.Packs) != 3 || profile.Packs[0].Weight != 0.3 {
+		t.Errorf("expected the profile to carry packs, got %+v", profile)
+	}
+}
+
+func TestPackaging(t *testing.T) {
+	s := NewMemoryStorage()
+	if p := s.GetPackaging(); len(p.Cartons) != 0 || p.PalletCapacity != 0 {
+		t.Errorf("expected no packaging, got %+v", p)
+	}
+
+	s.SetPackaging(calculator.Packaging{
+		Cartons:        []calculator.CartonType{{Name: "case", PackSize: 250, Capacity: 24}},
+		PalletCapacity: 40,
+	})
+
+	p := s.GetPackaging()
+	p.Cartons[0].Capacity = 1
+	if got := s.GetPackaging(); got.Cartons[0].Capacity != 24 || got.PalletCapacity != 40 {
+		t.Errorf("expected GetPackaging to return a copy, got %+v", got)
+	}
+}
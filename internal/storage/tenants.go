@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// DefaultTenant owns requests that name no tenant
+const DefaultTenant = "default"
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidTenantID reports whether id is 1-64 lowercase letters, digits, '-' or
+// '_', starting with a letter or digit.
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+// TenantStores are the stores that belong to a single tenant. Audit may be nil.
+type TenantStores struct {
+	Storage Storage
+	History HistoryStore
+	Audit   AuditStore
+}
+
+// TenantRegistry interface for per-tenant stores. Only registered tenants
+// are served; the default tenant is always registered.
+type TenantRegistry interface {
+	// Tenant returns the stores of a registered tenant.
+	Tenant(id string) (TenantStores, bool)
+	// CreateTenant registers a tenant with new stores. It returns false when
+	// the tenant already exists.
+	CreateTenant(id string) (TenantStores, bool)
+	Tenants() []string
+}
+
+// MemoryTenantRegistry is a thread-safe registry that creates the stores of
+// new tenants with a factory.
+type MemoryTenantRegistry struct {
+	mu      sync.Mutex
+	tenants map[string]TenantStores
+	factory func(id string) TenantStores
+}
+
+func NewMemoryTenantRegistry(factory func(id string) TenantStores) *MemoryTenantRegistry {
+	return &MemoryTenantRegistry{tenants: make(map[string]TenantStores), factory: factory}
+}
+
+// Set registers existing stores for a tenant, e.g. to share the default
+// tenant's stores with the gRPC server.
+func (r *MemoryTenantRegistry) Set(id string, stores TenantStores) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tenants[id] = stores
+}
+
+// Tenant returns the stores of a registered tenant. The default tenant is
+// created on first use unless Set registered it.
+func (r *MemoryTenantRegistry) Tenant(id string) (TenantStores, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stores, ok := r.tenants[id]
+	if !ok && id == DefaultTenant {
+		stores, ok = r.factory(id), true
+		r.tenants[id] = stores
+	}
+	return stores, ok
+}
+
+func (r *MemoryTenantRegistry) CreateTenant(id string) (TenantStores, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stores, ok := r.tenants[id]; ok {
+		return stores, false
+	}
+	stores := r.factory(id)
+	r.tenants[id] = stores
+	return stores, true
+}
+
+// Tenants returns the registered tenant IDs in order
+func (r *MemoryTenantRegistry) Tenants() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
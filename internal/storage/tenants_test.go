@@ -0,0 +1,59 @@
+package storage
+
+import "testing"
+
+func TestTenantRegistry(t *testing.T) {
+	var created []string
+	r := NewMemoryTenantRegistry(func(id string) TenantStores {
+		created = append(created, id)
+		return TenantStores{Storage: NewMemoryStorage(), History: NewMemoryHistory(10)}
+	})
+
+	shared := NewMemoryStorageWithSizes([]int{1})
+	r.Set(DefaultTenant, TenantStores{Storage: shared, History: NewMemoryHistory(10)})
+
+	if stores, ok := r.Tenant(DefaultTenant); !ok || stores.Storage != shared {
+		t.Error("expected the registered default stores")
+	}
+
+	if _, ok := r.Tenant("a"); ok {
+		t.Error("expected unregistered tenants to be unknown")
+	}
+	a, isNew := r.CreateTenant("a")
+	if !isNew {
+		t.Fatal("expected tenant a to be created")
+	}
+	a.Storage.AddPackSize(750)
+	if stores, ok := r.Tenant("a"); !ok || stores.Storage != a.Storage {
+		t.Error("expected the same stores on every lookup")
+	}
+	if again, isNew := r.CreateTenant("a"); isNew || again.Storage != a.Storage {
+		t.Error("expected creating an existing tenant to keep its stores")
+	}
+	b, _ := r.CreateTenant("b")
+	if len(b.Storage.GetPackSizes()) != 5 {
+		t.Error("expected tenant b to start from the defaults")
+	}
+
+	ids := r.Tenants()
+	if len(ids) != 3 || ids[0] != "a" || ids[2] != DefaultTenant {
+		t.Errorf("unexpected tenants %v", ids)
+	}
+	if len(created) != 2 || created[0] != "a" || created[1] != "b" {
+		t.Errorf("expected the factory to be called once per new tenant, got %v", created)
+	}
+}
+
+func TestTenantRegistryCreatesDefault(t *testing.T) {
+	r := NewMemoryTenantRegistry(func(id string) TenantStores {
+		return TenantStores{Storage: NewMemoryStorage(), History: NewMemoryHistory(10)}
+	})
+
+	first, ok := r.Tenant(DefaultTenant)
+	if !ok {
+		t.Fatal("expected the default tenant to always be registered")
+	}
+	if again, _ := r.Tenant(DefaultTenant); again.Storage != first.Storage {
+		t.Error("expected the default stores to be kept")
+	}
+}
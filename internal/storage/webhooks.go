@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Webhook is a registered receiver. The secret signs every delivery and is
+// only shown to the client when the webhook is created.
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookStore interface for webhook registrations
+type WebhookStore interface {
+	CreateWebhook(hook Webhook) bool
+	GetWebhook(id string) (Webhook, bool)
+	ListWebhooks() []Webhook
+	UpdateWebhook(hook Webhook) bool
+	DeleteWebhook(id string) bool
+}
+
+// MemoryWebhookStore is a thread-safe in-memory implementation
+type MemoryWebhookStore struct {
+	mu    sync.RWMutex
+	hooks map[string]Webhook
+}
+
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{hooks: make(map[string]Webhook)}
+}
+
+// CreateWebhook returns false if the id is already in use
+func (s *MemoryWebhookStore) CreateWebhook(hook Webhook) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hooks[hook.ID]; ok {
+		return false
+	}
+	s.hooks[hook.ID] = copyWebhook(hook)
+	return true
+}
+
+func (s *MemoryWebhookStore) GetWebhook(id string) (Webhook, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hook, ok := s.hooks[id]
+	return copyWebhook(hook), ok
+}
+
+// ListWebhooks returns webhooks ordered by creation time
+func (s *MemoryWebhookStore) ListWebhooks() []Webhook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Webhook, 0, len(s.hooks))
+	for _, hook := range s.hooks {
+		result = append(result, copyWebhook(hook))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// UpdateWebhook replaces an existing webhook and returns false if it is unknown
+func (s *MemoryWebhookStore) UpdateWebhook(hook Webhook) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hooks[hook.ID]; !ok {
+		return false
+	}
+	s.hooks[hook.ID] = copyWebhook(hook)
+	return true
+}
+
+func (s *MemoryWebhookStore) DeleteWebhook(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hooks[id]; !ok {
+		return false
+	}
+	delete(s.hooks, id)
+	return true
+}
+
+func copyWebhook(hook Webhook) Webhook {
+	hook.Events = append([]string(nil), hook.Events...)
+	return hook
+}
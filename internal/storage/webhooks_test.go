@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhookStoreCRUD(t *testing.T) {
+	s := NewMemoryWebhookStore()
+	now := time.Now()
+
+	if !s.CreateWebhook(Webhook{ID: "a", URL: "http://a", Events: []string{"x"}, CreatedAt: now}) {
+		t.Fatal("expected CreateWebhook to succeed")
+	}
+	if s.CreateWebhook(Webhook{ID: "a"}) {
+		t.Error("expected duplicate id to be rejected")
+	}
+	s.CreateWebhook(Webhook{ID: "b", URL: "http://b", CreatedAt: now.Add(time.Second)})
+
+	got, ok := s.GetWebhook("a")
+	if !ok || got.URL != "http://a" {
+		t.Fatalf("expected webhook a, got %+v (found=%v)", got, ok)
+	}
+	got.Events[0] = "y"
+	if again, _ := s.GetWebhook("a"); again.Events[0] != "x" {
+		t.Error("GetWebhook should return a copy")
+	}
+
+	got.URL = "http://a2"
+	if !s.UpdateWebhook(got) {
+		t.Error("expected UpdateWebhook to succeed")
+	}
+	if s.UpdateWebhook(Webhook{ID: "missing"}) {
+		t.Error("expected update of unknown webhook to fail")
+	}
+
+	hooks := s.ListWebhooks()
+	if len(hooks) != 2 || hooks[0].URL != "http://a2" || hooks[1].ID != "b" {
+		t.Errorf("unexpected list %+v", hooks)
+	}
+
+	if !s.DeleteWebhook("a") || s.DeleteWebhook("a") {
+		t.Error("expected delete to succeed once")
+	}
+}
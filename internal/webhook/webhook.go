@@ -0,0 +1,497 @@
+// Package webhook delivers signed event notifications to registered
+// receivers, retrying failures with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+const (
+	EventPackSizesChanged     = "pack_sizes.changed"
+	EventCalculationCompleted = "calculation.completed"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// secretPrefix makes leaked secrets easy to spot, like API keys
+const secretPrefix = "whsec_"
+
+var (
+	ErrUnknownEvent     = errors.New("unknown event")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+func AllEvents() []string {
+	return []string{EventPackSizesChanged, EventCalculationCompleted}
+}
+
+func ParseEvent(s string) (string, error) {
+	for _, event := range AllEvents() {
+		if event == s {
+			return event, nil
+		}
+	}
+	return "", ErrUnknownEvent
+}
+
+// NewSecret generates a signing secret for a new webhook.
+func NewSecret() (string, error) {
+	s, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	return secretPrefix + s, nil
+}
+
+// NewID generates an identifier for webhooks, events and deliveries.
+func NewID() (string, error) {
+	return randomHex(8)
+}
+
+// Sign returns the signature header value for body sent at ts:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+func Sign(secret string, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + mac(secret, t, body)
+}
+
+// Verify checks a signature header produced by Sign and rejects signatures
+// older than tolerance, which limits replays. A zero tolerance skips the check.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, t, body))) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(unix, 0)) > tolerance {
+		return fmt.Errorf("%w: timestamp too old", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func mac(secret, t string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(t))
+	m.Write([]byte("."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Event is the JSON body POSTed to receivers.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Delivery records a single attempt to deliver an event to a webhook.
+type Delivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// DeadLetter is an event that could not be delivered after every retry.
+type DeadLetter struct {
+	ID        string    `json:"id"`
+	WebhookID string    `json:"webhook_id"`
+	Event     Event     `json:"event"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Config struct {
+	MaxAttempts    int           // including the first; default 5
+	InitialBackoff time.Duration // doubled after every failure; default 1s
+	MaxBackoff     time.Duration // default 1m
+	Timeout        time.Duration // per attempt; default 10s
+	Concurrency    int           // requests in flight; default 8
+	LogLimit       int           // deliveries and dead letters kept; default 1000
+	Client         *http.Client
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Minute
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.LogLimit <= 0 {
+		c.LogLimit = 1000
+	}
+	if c.Client == nil {
+		c.Client = &http.Client{}
+	}
+	return c
+}
+
+// Dispatcher fans events out to the webhooks subscribed to them. Deliveries
+// run in the background; Close waits for them to stop.
+type Dispatcher struct {
+	store storage.WebhookStore
+	cfg   Config
+	slots chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu          sync.RWMutex
+	closed      bool
+	deliveries  []Delivery
+	deadLetters []DeadLetter
+}
+
+func NewDispatcher(store storage.WebhookStore, cfg Config) *Dispatcher {
+	cfg = cfg.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		store:  store,
+		cfg:    cfg,
+		slots:  make(chan struct{}, cfg.Concurrency),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (d *Dispatcher) Store() storage.WebhookStore {
+	return d.store
+}
+
+// Publish queues eventType for every webhook subscribed to it.
+func (d *Dispatcher) Publish(eventType string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	id, err := NewID()
+	if err != nil {
+		return err
+	}
+
+	event := Event{ID: id, Type: eventType, CreatedAt: time.Now().UTC(), Data: raw}
+	for _, hook := range d.store.ListWebhooks() {
+		if subscribed(hook, eventType) {
+			d.send(hook.ID, event)
+		}
+	}
+	return nil
+}
+
+// TenantChange is a pack size change of a tenant other than the default.
+type TenantChange struct {
+	storage.Change
+	Tenant string `json:"tenant"`
+}
+
+// Watch publishes every change recorded in feed until the dispatcher closes.
+func (d *Dispatcher) Watch(feed storage.ChangeFeed) {
+	d.watch(feed, func(change storage.Change) interface{} { return change })
+}
+
+// WatchTenant is Watch for a tenant's history; events carry the tenant.
+func (d *Dispatcher) WatchTenant(tenant string, feed storage.ChangeFeed) {
+	d.watch(feed, func(change storage.Change) interface{} {
+		return TenantChange{Change: change, Tenant: tenant}
+	})
+}
+
+func (d *Dispatcher) watch(feed storage.ChangeFeed, data func(storage.Change) interface{}) {
+	d.goTracked(func() {
+		var lastID int64
+		for {
+			sub := feed.Subscribe(lastID)
+			for _, change := range sub.Replay {
+				d.Publish(EventPackSizesChanged, data(change))
+				lastID = change.ID
+			}
+
+			if !d.forward(sub, &lastID, data) {
+				return
+			}
+		}
+	})
+}
+
+// forward publishes changes until the subscription is dropped (true) or
+// the dispatcher closes (false).
+func (d *Dispatcher) forward(sub *storage.Subscription, lastID *int64, data func(storage.Change) interface{}) bool {
+	defer sub.Close()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return false
+		case change, ok := <-sub.C:
+			if !ok {
+				return true
+			}
+			d.Publish(EventPackSizesChanged, data(change))
+			*lastID = change.ID
+		}
+	}
+}
+
+// Deliveries returns the logged attempts for a webhook, oldest first.
+func (d *Dispatcher) Deliveries(webhookID string) []Delivery {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result := []Delivery{}
+	for _, delivery := range d.deliveries {
+		if delivery.WebhookID == webhookID {
+			result = append(result, delivery)
+		}
+	}
+	return result
+}
+
+// DeadLetters returns events that exhausted their retries, oldest first.
+func (d *Dispatcher) DeadLetters() []DeadLetter {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return append([]DeadLetter{}, d.deadLetters...)
+}
+
+// Redeliver takes a dead letter off the list and retries it from scratch.
+// It returns false if the dead letter or its webhook no longer exists.
+func (d *Dispatcher) Redeliver(id string) bool {
+	d.mu.Lock()
+	var letter DeadLetter
+	found := false
+	for i, dl := range d.deadLetters {
+		if dl.ID == id {
+			letter, found = dl, true
+			d.deadLetters = append(d.deadLetters[:i], d.deadLetters[i+1:]...)
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	if !found {
+		return false
+	}
+	if _, ok := d.store.GetWebhook(letter.WebhookID); !ok {
+		return false
+	}
+
+	d.send(letter.WebhookID, letter.Event)
+	return true
+}
+
+// Close stops retries and waits for deliveries in flight.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) send(webhookID string, event Event) {
+	d.goTracked(func() {
+		d.deliver(webhookID, event)
+	})
+}
+
+// goTracked runs fn in the background unless the dispatcher is closed
+func (d *Dispatcher) goTracked(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		fn()
+	}()
+}
+
+func (d *Dispatcher) deliver(webhookID string, event Event) {
+	body, _ := json.Marshal(event)
+
+	var lastErr string
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-time.After(d.backoff(attempt - 1)):
+			}
+		}
+
+		// re-read so updates and deletions apply to pending retries
+		hook, ok := d.store.GetWebhook(webhookID)
+		if !ok {
+			return
+		}
+
+		status, err := d.post(hook, event, body)
+		delivery := Delivery{
+			WebhookID:  hook.ID,
+			EventID:    event.ID,
+			EventType:  event.Type,
+			Attempt:    attempt,
+			StatusCode: status,
+			Success:    err == nil,
+			Timestamp:  time.Now().UTC(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+			lastErr = err.Error()
+		}
+		d.logDelivery(delivery)
+
+		if err == nil {
+			return
+		}
+	}
+
+	d.deadLetter(DeadLetter{
+		WebhookID: webhookID,
+		Event:     event,
+		Attempts:  d.cfg.MaxAttempts,
+		LastError: lastErr,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+func (d *Dispatcher) post(hook storage.Webhook, event Event, body []byte) (int, error) {
+	select {
+	case d.slots <- struct{}{}:
+		defer func() { <-d.slots }()
+	case <-d.ctx.Done():
+		return 0, d.ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, time.Now(), body))
+
+	resp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff is InitialBackoff doubled for every earlier failure, capped at MaxBackoff
+func (d *Dispatcher) backoff(failures int) time.Duration {
+	wait := d.cfg.InitialBackoff
+	for i := 1; i < failures && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.cfg.MaxBackoff {
+		wait = d.cfg.MaxBackoff
+	}
+	return wait
+}
+
+func (d *Dispatcher) logDelivery(delivery Delivery) {
+	delivery.ID, _ = NewID()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deliveries = append(d.deliveries, delivery)
+	if len(d.deliveries) > d.cfg.LogLimit {
+		d.deliveries = d.deliveries[len(d.deliveries)-d.cfg.LogLimit:]
+	}
+}
+
+func (d *Dispatcher) deadLetter(letter DeadLetter) {
+	letter.ID, _ = NewID()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deadLetters = append(d.deadLetters, letter)
+	if len(d.deadLetters) > d.cfg.LogLimit {
+		d.deadLetters = d.deadLetters[len(d.deadLetters)-d.cfg.LogLimit:]
+	}
+}
+
+func subscribed(hook storage.Webhook, eventType string) bool {
+	for _, e := range hook.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/willianbsanches13/pack-calculator/internal/storage"
+)
+
+// receiver is a local endpoint that fails the first failures requests
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	headers  []http.Header
+	got      chan struct{}
+}
+
+func newReceiver(t *testing.T, failures int) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	rc := &receiver{failures: failures, got: make(chan struct{}, 100)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		rc.mu.Lock()
+		rc.bodies = append(rc.bodies, body)
+		rc.headers = append(rc.headers, r.Header.Clone())
+		fail := len(rc.bodies) <= rc.failures
+		rc.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		rc.got <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return rc, srv
+}
+
+func (rc *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rc.got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for request %d", i+1)
+		}
+	}
+}
+
+func setup(t *testing.T, cfg Config, events ...string) (*Dispatcher, storage.Webhook) {
+	t.Helper()
+
+	store := storage.NewMemoryWebhookStore()
+	cfg.InitialBackoff = time.Millisecond
+	d := NewDispatcher(store, cfg)
+	t.Cleanup(d.Close)
+
+	hook := storage.Webhook{ID: "hook", Events: events, Secret: "whsec_test", CreatedAt: time.Now()}
+	return d, hook
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+	header := Sign("secret", now, body)
+
+	if err := Verify("secret", header, body, now, time.Minute); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+	if err := Verify("other", header, body, now, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected wrong secret to fail, got %v", err)
+	}
+	if err := Verify("secret", header, []byte(`{}`), now, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected tampered body to fail, got %v", err)
+	}
+	if err := Verify("secret", header, body, now.Add(time.Hour), time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected stale signature to fail, got %v", err)
+	}
+	if err := Verify("secret", "garbage", body, now, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected malformed header to fail, got %v", err)
+	}
+}
+
+func TestPublishSignsPayload(t *testing.T) {
+	rc, srv := newReceiver(t, 0)
+	d, hook := setup(t, Config{}, EventCalculationCompleted)
+	hook.URL = srv.URL
+	d.Store().CreateWebhook(hook)
+
+	d.Publish(EventPackSizesChanged, "ignored") // not subscribed
+	d.Publish(EventCalculationCompleted, map[string]int{"total_items": 500})
+	rc.wait(t, 1)
+
+	rc.mu.Lock()
+	body, header := rc.bodies[0], rc.headers[0]
+	rc.mu.Unlock()
+
+	if err := Verify(hook.Secret, header.Get(SignatureHeader), body, time.Now(), time.Minute); err != nil {
+		t.Errorf("signature did not verify: %v", err)
+	}
+	if header.Get(EventHeader) != EventCalculationCompleted {
+		t.Errorf("unexpected event header %q", header.Get(EventHeader))
+	}
+
+	var event Event
+	json.Unmarshal(body, &event)
+	if event.Type != EventCalculationCompleted || string(event.Data) != `{"total_items":500}` {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if header.Get(DeliveryHeader) != event.ID {
+		t.Errorf("expected delivery header %q, got %q", event.ID, header.Get(DeliveryHeader))
+	}
+
+	waitFor(t, func() bool { return len(d.Deliveries("hook")) == 1 })
+	if delivery := d.Deliveries("hook")[0]; !delivery.Success || delivery.StatusCode != http.StatusOK {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+}
+
+func TestRetriesWithBackoff(t *testing.T) {
+	rc, srv := newReceiver(t, 2)
+	d, hook := setup(t, Config{MaxAttempts: 5}, EventPackSizesChanged)
+	hook.URL = srv.URL
+	d.Store().CreateWebhook(hook)
+
+	d.Publish(EventPackSizesChanged, storage.Change{ID: 1})
+	rc.wait(t, 3)
+
+	waitFor(t, func() bool { return len(d.Deliveries("hook")) == 3 })
+	deliveries := d.Deliveries("hook")
+	if deliveries[0].Success || deliveries[0].StatusCode != http.StatusInternalServerError || deliveries[0].Error == "" {
+		t.Errorf("expected first attempt to fail, got %+v", deliveries[0])
+	}
+	if !deliveries[2].Success || deliveries[2].Attempt != 3 {
+		t.Errorf("expected third attempt to succeed, got %+v", deliveries[2])
+	}
+	if len(d.DeadLetters()) != 0 {
+		t.Error("expected no dead letters")
+	}
+}
+
+func TestDeadLetterAndRedeliver(t *testing.T) {
+	rc, srv := newReceiver(t, 2)
+	d, hook := setup(t, Config{MaxAttempts: 2}, EventPackSizesChanged)
+	hook.URL = srv.URL
+	d.Store().CreateWebhook(hook)
+
+	d.Publish(EventPackSizesChanged, storage.Change{ID: 1})
+	rc.wait(t, 2)
+
+	waitFor(t, func() bool { return len(d.DeadLetters()) == 1 })
+	letter := d.DeadLetters()[0]
+	if letter.WebhookID != "hook" || letter.Attempts != 2 || letter.LastError == "" {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+
+	if !d.Redeliver(letter.ID) {
+		t.Fatal("expected redelivery to start")
+	}
+	rc.wait(t, 1)
+	waitFor(t, func() bool { return len(d.Deliveries("hook")) == 3 })
+
+	if len(d.DeadLetters()) != 0 {
+		t.Error("expected dead letter to be removed")
+	}
+	if d.Redeliver(letter.ID) {
+		t.Error("expected unknown dead letter to be rejected")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(storage.NewMemoryWebhookStore(), Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	defer d.Close()
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := d.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestWatchPublishesChanges(t *testing.T) {
+	rc, srv := newReceiver(t, 0)
+	d, hook := setup(t, Config{}, EventPackSizesChanged)
+	hook.URL = srv.URL
+	d.Store().CreateWebhook(hook)
+
+	history := storage.NewMemoryHistory(10)
+	d.Watch(history)
+
+	// Watch subscribes in the background; record until the first change lands
+	waitFor(t, func() bool {
+		history.RecordChange(storage.Change{Action: storage.ChangeAdd, Size: 750})
+		return len(rc.got) > 0
+	})
+
+	var event Event
+	rc.mu.Lock()
+	json.Unmarshal(rc.bodies[0], &event)
+	rc.mu.Unlock()
+
+	var change storage.Change
+	json.Unmarshal(event.Data, &change)
+	if event.Type != EventPackSizesChanged || change.Size != 750 {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestWatchTenantPublishesTenant(t *testing.T) {
+	rc, srv := newReceiver(t, 0)
+	d, hook := setup(t, Config{}, EventPackSizesChanged)
+	hook.URL = srv.URL
+	d.Store().CreateWebhook(hook)
+
+	history := storage.NewMemoryHistory(10)
+	d.WatchTenant("acme", history)
+
+	waitFor(t, func() bool {
+		history.RecordChange(storage.Change{Action: storage.ChangeAdd, Size: 750})
+		return len(rc.got) > 0
+	})
+
+	var event Event
+	rc.mu.Lock()
+	json.Unmarshal(rc.bodies[0], &event)
+	rc.mu.Unlock()
+
+	var change TenantChange
+	json.Unmarshal(event.Data, &change)
+	if change.Tenant != "acme" || change.Size != 750 {
+		t.Errorf("unexpected event data %s", event.Data)
+	}
+}
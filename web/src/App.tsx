@@ -1,6 +1,6 @@
-import { useState } from 'react'
+import { useEffect, useState } from 'react'
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
-import { fetchPackSizes, addPackSize, removePackSize, calculatePacks } from './api'
+import { fetchPackSizes, addPackSize, removePackSize, calculatePacks, subscribePackSizes } from './api'
 import type { CalculateResponse } from './types'
 
 interface PackDisplay {
@@ -20,6 +20,12 @@ function App() {
     queryFn: fetchPackSizes,
   })
 
+  useEffect(() => {
+    return subscribePackSizes((change) => {
+      queryClient.setQueryData(['packSizes'], change.pack_sizes)
+    })
+  }, [queryClient])
+
   const addMutation = useMutation({
     mutationFn: addPackSize,
     onSuccess: (data) => {
@@ -1,33 +1,29 @@
-import type { CalculateResponse } from './types'
+import type { CalculateResponse, Envelope, PackSizeChange, PackSizesResponse } from './types'
 
-const API_BASE = '/api'
+const API_BASE = '/api/v2'
+
+async function unwrap<T>(res: Response): Promise<T> {
+  const data = await res.json()
+  if (!res.ok) throw new Error(data.detail || data.title)
+  return (data as Envelope<T>).data
+}
 
 export async function fetchPackSizes(): Promise<number[]> {
   const res = await fetch(`${API_BASE}/pack-sizes`)
   if (!res.ok) throw new Error('Failed to fetch pack sizes')
-  const data = await res.json()
+  const data = await unwrap<PackSizesResponse>(res)
   return data.pack_sizes || []
 }
 
 export async function addPackSize(size: number): Promise<number[]> {
-  const res = await fetch(`${API_BASE}/pack-sizes/add`, {
-    method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
-    body: JSON.stringify({ size })
-  })
-  const data = await res.json()
-  if (!res.ok) throw new Error(data.message || data.error)
+  const res = await fetch(`${API_BASE}/pack-sizes/${size}`, { method: 'PUT' })
+  const data = await unwrap<PackSizesResponse>(res)
   return data.pack_sizes
 }
 
 export async function removePackSize(size: number): Promise<number[]> {
-  const res = await fetch(`${API_BASE}/pack-sizes/remove`, {
-    method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
-    body: JSON.stringify({ size })
-  })
-  const data = await res.json()
-  if (!res.ok) throw new Error(data.message || data.error)
+  const res = await fetch(`${API_BASE}/pack-sizes/${size}`, { method: 'DELETE' })
+  const data = await unwrap<PackSizesResponse>(res)
   return data.pack_sizes
 }
 
@@ -37,7 +33,15 @@ export async function calculatePacks(amount: number): Promise<CalculateResponse>
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify({ amount })
   })
-  const data = await res.json()
-  if (!res.ok) throw new Error(data.message || data.error)
-  return data
+  return unwrap<CalculateResponse>(res)
+}
+
+// subscribePackSizes calls onChange for every pack size change made by any
+// client. EventSource reconnects on its own and resumes via Last-Event-ID.
+export function subscribePackSizes(onChange: (change: PackSizeChange) => void): () => void {
+  const source = new EventSource(`${API_BASE}/pack-sizes/events`)
+  source.addEventListener('pack-sizes', (e) => {
+    onChange(JSON.parse((e as MessageEvent).data))
+  })
+  return () => source.close()
 }
@@ -1,8 +1,22 @@
+export interface Envelope<T> {
+  data: T
+  meta: { api_version: string }
+}
+
 export interface PackSizesResponse {
   pack_sizes: number[]
   message?: string
 }
 
+export interface PackSizeChange {
+  id: number
+  action: 'set' | 'add' | 'remove'
+  size?: number
+  pack_sizes: number[]
+  actor: string
+  timestamp: string
+}
+
 export interface CalculateRequest {
   amount: number
   pack_sizes?: number[]
@@ -16,7 +30,18 @@ export interface CalculateResponse {
   pack_sizes_used: number[]
 }
 
+export interface FieldError {
+  field: string
+  reason: string
+  param?: string
+}
+
 export interface ErrorResponse {
-  error: string
-  message?: string
+  type: string
+  title: string
+  status: number
+  detail?: string
+  instance?: string
+  code: string
+  errors?: FieldError[]
 }